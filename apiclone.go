@@ -0,0 +1,283 @@
+/*
+   Copyright (c) 2020 gingfrederik
+   Copyright (c) 2021 Gonzalo Fernandez-Victorio
+   Copyright (c) 2021 Basement Crowd Ltd (https://www.basementcrowd.com)
+   Copyright (c) 2023 Fumiama Minamoto (源文雨)
+
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU Affero General Public License as published
+   by the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU Affero General Public License for more details.
+
+   You should have received a copy of the GNU Affero General Public License
+   along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package docx
+
+import (
+	"bytes"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"unsafe"
+)
+
+// Clone returns a deep copy of the whole document
+func (f *Docx) Clone() (*Docx, error) {
+	buf := bytes.NewBuffer(make([]byte, 0, 1024*1024))
+	_, err := f.WriteTo(buf)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := ParseWithOptions(bytes.NewReader(buf.Bytes()), int64(buf.Len()), ParseOptions{
+		Preserve: true,
+		Logger:   f.logger,
+	})
+	if err != nil {
+		return nil, err
+	}
+	doc.complevel, doc.compset = f.complevel, f.compset
+	return doc, nil
+}
+
+// Clone returns a deep copy of p that belongs to to,
+// or to the document of p if to is nil.
+//
+// Images and hyperlinks are added to to if it is another document,
+// where header and footer references are dropped. Other references
+// like notes, comments and numbering are kept as they are.
+//
+// If a relationship cannot be moved into to, like the one of a chart,
+// its reference is left empty and a *RelationshipError is returned
+// with the copy.
+func (p *Paragraph) Clone(to *Docx) (*Paragraph, error) {
+	cl := newCloner(p.file, to)
+	return cl.clone(p).(*Paragraph), cl.err
+}
+
+// Clone returns a deep copy of r that belongs to to,
+// or to the document of r if to is nil.
+func (r *Run) Clone(to *Docx) (*Run, error) {
+	cl := newCloner(r.file, to)
+	return cl.clone(r).(*Run), cl.err
+}
+
+// Clone returns a deep copy of t that belongs to to,
+// or to the document of t if to is nil.
+func (t *Table) Clone(to *Docx) (*Table, error) {
+	cl := newCloner(t.file, to)
+	return cl.clone(t).(*Table), cl.err
+}
+
+// Clone returns a deep copy of w that belongs to to,
+// or to the document of w if to is nil.
+func (w *WTableRow) Clone(to *Docx) (*WTableRow, error) {
+	cl := newCloner(w.file, to)
+	return cl.clone(w).(*WTableRow), cl.err
+}
+
+// Clone returns a deep copy of c that belongs to to,
+// or to the document of c if to is nil.
+func (c *WTableCell) Clone(to *Docx) (*WTableCell, error) {
+	cl := newCloner(c.file, to)
+	return cl.clone(c).(*WTableCell), cl.err
+}
+
+// Clone returns a deep copy of r that belongs to to,
+// or to the document of r if to is nil.
+func (r *Drawing) Clone(to *Docx) (*Drawing, error) {
+	cl := newCloner(r.file, to)
+	return cl.clone(r).(*Drawing), cl.err
+}
+
+var (
+	docxType      = reflect.TypeOf((*Docx)(nil))
+	preservedType = reflect.TypeOf((*preserved)(nil))
+)
+
+// cloner deep-copies a node of from into to
+type cloner struct {
+	from, to *Docx
+	// rels maps the rIds of from to those of to
+	rels map[string]string
+	// err is the first relationship that cannot be moved
+	err error
+}
+
+// rawRelRe matches an r:* attribute written in RawXML.Inner
+var rawRelRe = regexp.MustCompile(`(\sr:\w+=")([^"]*)"`)
+
+func newCloner(from, to *Docx) *cloner {
+	if to == nil {
+		to = from
+	}
+	return &cloner{from: from, to: to, rels: make(map[string]string, 8)}
+}
+
+func (c *cloner) clone(v interface{}) interface{} {
+	return c.value(reflect.ValueOf(v)).Interface()
+}
+
+// value returns the deep copy of v, the unexported fields included
+func (c *cloner) value(v reflect.Value) reflect.Value {
+	if !v.CanInterface() && v.CanAddr() {
+		v = reflect.NewAt(v.Type(), unsafe.Pointer(v.UnsafeAddr())).Elem()
+	}
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.Type() == docxType {
+			return reflect.ValueOf(c.to)
+		}
+		if v.IsNil() {
+			return v
+		}
+		if v.Type() == preservedType {
+			// the source is never changed, and it is written
+			// back only if the copy still matches its snapshot
+			return v
+		}
+		n := reflect.New(v.Type().Elem())
+		n.Elem().Set(c.value(v.Elem()))
+		return n
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		e := reflect.New(v.Elem().Type()).Elem()
+		e.Set(v.Elem())
+		n := reflect.New(v.Type()).Elem()
+		n.Set(c.value(e))
+		return n
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		n := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			n.Index(i).Set(c.value(v.Index(i)))
+		}
+		return n
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		n := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			n.SetMapIndex(iter.Key(), c.value(iter.Value()))
+		}
+		return n
+	case reflect.Struct:
+		if !v.CanAddr() {
+			e := reflect.New(v.Type()).Elem()
+			e.Set(v)
+			v = e
+		}
+		n := reflect.New(v.Type()).Elem()
+		n.Set(v)
+		for i := 0; i < n.NumField(); i++ {
+			fv := n.Field(i)
+			if !fv.CanSet() {
+				fv = reflect.NewAt(fv.Type(), unsafe.Pointer(fv.UnsafeAddr())).Elem()
+			}
+			fv.Set(c.value(v.Field(i)))
+		}
+		c.rehome(n.Addr().Interface())
+		return n
+	default:
+		return v
+	}
+}
+
+// rehome fixes the ids of a copied node that must be unique in to
+func (c *cloner) rehome(x interface{}) {
+	switch o := x.(type) {
+	case *Paragraph:
+		o.ParaId, o.TextId = "", ""
+	case *WPDocPr:
+		if c.to != nil {
+			o.ID = int(atomic.AddUintptr(&c.to.docID, 1))
+		}
+	case *ABlip:
+		o.Embed = c.rel(o.Embed)
+	case *Hyperlink:
+		o.ID = c.rel(o.ID)
+	case *RawXML:
+		if c.from == c.to {
+			return
+		}
+		for i, a := range o.Attrs {
+			if strings.HasPrefix(a.Name.Local, "r:") {
+				o.Attrs[i].Value = c.rel(a.Value)
+			}
+		}
+		o.Inner = rawRelRe.ReplaceAllFunc(o.Inner, func(b []byte) []byte {
+			m := rawRelRe.FindSubmatch(b)
+			return []byte(string(m[1]) + c.rel(string(m[2])) + `"`)
+		})
+	case *SectPr:
+		if o.HeaderReference != nil {
+			refs := make([]HeaderReference, 0, len(*o.HeaderReference))
+			for _, ref := range *o.HeaderReference {
+				if ref.Id = c.rel(ref.Id); ref.Id != "" {
+					refs = append(refs, ref)
+				}
+			}
+			*o.HeaderReference = refs
+		}
+		if o.FooterReference != nil {
+			refs := make([]FooterReference, 0, len(*o.FooterReference))
+			for _, ref := range *o.FooterReference {
+				if ref.Id = c.rel(ref.Id); ref.Id != "" {
+					refs = append(refs, ref)
+				}
+			}
+			*o.FooterReference = refs
+		}
+	}
+}
+
+// rel returns the rId in to of the relationship id in from,
+// or an empty string if it cannot be moved into to
+//
+//	this func is not thread-safe
+func (c *cloner) rel(id string) string {
+	if id == "" || c.from == nil || c.from == c.to {
+		return id
+	}
+	if nid, ok := c.rels[id]; ok {
+		return nid
+	}
+	nid, typ := "", ""
+	for _, r := range c.from.docRelation.Relationship {
+		if r.ID != id {
+			continue
+		}
+		typ = r.Type
+		switch r.Type {
+		case REL_IMAGE:
+			if m := c.from.Media(strings.TrimPrefix(r.Target, "media/")); m != nil {
+				nid = c.to.addImage(r.Target[strings.LastIndex(r.Target, ".")+1:], m.Data)
+			}
+		case REL_HYPERLINK:
+			nid = c.to.addLinkRelation(r.Target)
+		case REL_HEADER, REL_FOOTER:
+			// the parts are not moved, so their references are dropped
+			c.rels[id] = ""
+			return ""
+		}
+		break
+	}
+	if nid == "" && c.err == nil {
+		c.err = &RelationshipError{ID: id, Type: typ}
+	}
+	c.rels[id] = nid
+	return nid
+}
@@ -0,0 +1,153 @@
+/*
+   Copyright (c) 2020 gingfrederik
+   Copyright (c) 2021 Gonzalo Fernandez-Victorio
+   Copyright (c) 2021 Basement Crowd Ltd (https://www.basementcrowd.com)
+   Copyright (c) 2023 Fumiama Minamoto (源文雨)
+
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU Affero General Public License as published
+   by the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU Affero General Public License for more details.
+
+   You should have received a copy of the GNU Affero General Public License
+   along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package docx
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestClone(t *testing.T) {
+	logo, err := os.ReadFile("testdata/fumiamayoko.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := NewA4()
+	p := doc.AddParagraph()
+	p.AddText("bold").Bold()
+	p.AddLink("link", "https://github.com/fumiama/go-docx")
+	_, err = p.AddInlineDrawing(logo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.ParaId = "1A2B3C4D"
+	tbl := doc.AddTable(2, 2)
+	tbl.TableRows[0].TableCells[0].AddParagraph().AddText("cell")
+
+	np, err := p.Clone(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	np.Children[0].(*Run).Color("FF0000")
+	if p.Children[0].(*Run).RunProperties.Color != nil {
+		t.Fatal("run properties are shared")
+	}
+	if np.ParaId != "" || np.String() != p.String() {
+		t.Fatal("unexpected clone", np.ParaId, np.String())
+	}
+	d, nd := p.Children[2].(*Run).Children[0].(*Drawing), np.Children[2].(*Run).Children[0].(*Drawing)
+	if d == nd || d.Inline.DocPr == nd.Inline.DocPr || d.Inline.DocPr.ID == nd.Inline.DocPr.ID {
+		t.Fatal("drawing is shared")
+	}
+	if d.Inline.Graphic.GraphicData.Pic.BlipFill.Blip.Embed != nd.Inline.Graphic.GraphicData.Pic.BlipFill.Blip.Embed {
+		t.Fatal("image is copied in the same document")
+	}
+
+	other := NewA4()
+	op, err := p.Clone(other)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other.Document.Body.Items = append(other.Document.Body.Items, op)
+	if op.file != other || op.Children[0].(*Run).file != other || op.String() != p.String() {
+		t.Fatal("paragraph is not moved", op.String())
+	}
+	tgt, err := other.ReferTarget(op.Children[1].(*Hyperlink).ID)
+	if err != nil || tgt != "https://github.com/fumiama/go-docx" {
+		t.Fatal("link is not moved", tgt, err)
+	}
+	ot, err := tbl.Clone(other)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ot.TableRows[0].TableCells[0].Items[0].(*Paragraph).Children[0].(*Run).Children[0].(*Text).Text = "changed"
+	if tbl.TableRows[0].TableCells[0].Items[0].(*Paragraph).String() != "cell" || ot.TableRows[1].file != other {
+		t.Fatal("table is shared")
+	}
+
+	buf := bytes.NewBuffer(make([]byte, 0, 1024*1024))
+	_, err = other.WriteTo(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Parse(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := got.Document.Body.Items[0].(*Paragraph).String(); s != p.String() {
+		t.Fatal("unexpected paragraph after reparse", s)
+	}
+
+	nf, err := doc.Clone()
+	if err != nil {
+		t.Fatal(err)
+	}
+	nf.Document.Body.Items[0].(*Paragraph).AddText("more")
+	if len(p.Children) != 3 || len(nf.Document.Body.Items) != len(doc.Document.Body.Items) {
+		t.Fatal("document is shared")
+	}
+}
+
+func TestCloneRelationships(t *testing.T) {
+	const relChart = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/chart"
+	doc := NewA4()
+	doc.addLinkRelation("https://example.com/unused")
+	link := doc.addLinkRelation("https://github.com/fumiama/go-docx")
+	chart := doc.addPartRelation(relChart, "word/charts/chart1.xml")
+	p := doc.AddParagraph()
+	p.Children = append(p.Children, &RawXML{
+		XMLName: xml.Name{Local: "w:object"},
+		Attrs:   []xml.Attr{{Name: xml.Name{Local: "r:id"}, Value: link}},
+		Inner:   []byte(`<c:chart xmlns:c="http://schemas.openxmlformats.org/drawingml/2006/chart" r:id="` + chart + `"/>`),
+	})
+
+	np, err := p.Clone(nil)
+	if err != nil || np.Children[0].(*RawXML).Attrs[0].Value != link {
+		t.Fatal("unexpected clone in the same document", err)
+	}
+
+	other := NewA4()
+	op, err := p.Clone(other)
+	var rerr *RelationshipError
+	if !errors.As(err, &rerr) || rerr.ID != chart || rerr.Type != relChart {
+		t.Fatal("unexpected error", err)
+	}
+	raw := op.Children[0].(*RawXML)
+	tgt, err := other.ReferTarget(raw.Attrs[0].Value)
+	if err != nil || tgt != "https://github.com/fumiama/go-docx" {
+		t.Fatal("link in raw node is not moved", raw.Attrs[0].Value, tgt, err)
+	}
+	if !bytes.Contains(raw.Inner, []byte(` r:id=""`)) {
+		t.Fatal("chart reference is kept", string(raw.Inner))
+	}
+	if p.Children[0].(*RawXML).Attrs[0].Value != link {
+		t.Fatal("source is changed")
+	}
+
+	p.Children[0].(*RawXML).Attrs[0].Value = "rId999"
+	_, err = p.Clone(NewA4())
+	if !errors.As(err, &rerr) || rerr.ID != "rId999" || rerr.Type != "" {
+		t.Fatal("missing relationship is not reported", err)
+	}
+}
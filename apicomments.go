@@ -0,0 +1,240 @@
+/*
+   Copyright (c) 2020 gingfrederik
+   Copyright (c) 2021 Gonzalo Fernandez-Victorio
+   Copyright (c) 2021 Basement Crowd Ltd (https://www.basementcrowd.com)
+   Copyright (c) 2023 Fumiama Minamoto (源文雨)
+
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU Affero General Public License as published
+   by the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU Affero General Public License for more details.
+
+   You should have received a copy of the GNU Affero General Public License
+   along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package docx
+
+import "strings"
+
+// AddComment adds a comment of text by author anchored to the runs
+// from from to to, which must be children of p. A nil from or to
+// extends the anchor to the beginning or the end of p.
+// It returns nil if the runs are not found in order.
+//
+//	Date is left empty, set it to something like 2006-01-02T15:04:05Z if needed
+func (p *Paragraph) AddComment(from, to *Run, author, text string) *Comment {
+	start, end := 0, len(p.Children)
+	if from != nil {
+		start = p.IndexOf(from)
+		if start < 0 {
+			return nil
+		}
+	}
+	if to != nil {
+		end = p.IndexOf(to) + 1
+		if end <= start {
+			return nil
+		}
+	}
+	c := p.file.commentsPart().newComment(author, text)
+	children := make([]interface{}, 0, len(p.Children)+3)
+	children = append(children, p.Children[:start]...)
+	children = append(children, &CommentRangeStart{ID: c.ID})
+	children = append(children, p.Children[start:end]...)
+	children = append(children, &CommentRangeEnd{ID: c.ID}, c.referenceRun())
+	children = append(children, p.Children[end:]...)
+	p.Children = children
+	return c
+}
+
+// Reply adds a comment of text by author replying to c,
+// anchored to the same text
+func (c *Comment) Reply(author, text string) *Comment {
+	r := c.file.commentsPart().newComment(author, text)
+	r.Parent = c
+	for _, items := range c.file.stories() {
+		*items = r.anchorAfter(c.ID, *items)
+	}
+	return r
+}
+
+// anchorAfter puts the marks of c after the ones of comment id in items
+func (c *Comment) anchorAfter(id int, items []interface{}) []interface{} {
+	nitems := make([]interface{}, 0, len(items)+3)
+	for _, it := range items {
+		nitems = append(nitems, it)
+		switch o := it.(type) {
+		case *CommentRangeStart:
+			if o.ID == id {
+				nitems = append(nitems, &CommentRangeStart{ID: c.ID})
+			}
+		case *CommentRangeEnd:
+			if o.ID == id {
+				nitems = append(nitems, &CommentRangeEnd{ID: c.ID})
+			}
+		case *Run:
+			for _, x := range o.Children {
+				if ref, ok := x.(*CommentReference); ok && ref.ID == id {
+					nitems = append(nitems, c.referenceRun())
+					break
+				}
+			}
+		case *Paragraph:
+			o.Children = c.anchorAfter(id, o.Children)
+		case *Table:
+			for _, row := range o.TableRows {
+				for _, cell := range row.TableCells {
+					cell.Items = c.anchorAfter(id, cell.Items)
+				}
+			}
+		}
+	}
+	return nitems
+}
+
+// referenceRun returns a run holding the reference mark of c
+func (c *Comment) referenceRun() *Run {
+	return &Run{
+		Children: []interface{}{&CommentReference{ID: c.ID}},
+		file:     c.file,
+	}
+}
+
+// commentsPart returns the comments part, adding it if not exist
+//
+//	this func is not thread-safe
+func (f *Docx) commentsPart() *Comments {
+	if f.comments != nil {
+		return f.comments
+	}
+	c := newComments(f)
+	c.name = "word/comments.xml"
+	if f.hasPart(c.name) {
+		c.name = f.newPartName("comments")
+	}
+	f.addPartRelation(REL_COMMENTS, c.name)
+	f.comments = c
+	return c
+}
+
+// newComment appends a comment of text by author with a new id
+func (c *Comments) newComment(author, text string) *Comment {
+	id := 0
+	for _, x := range c.Comments {
+		if x.ID >= id {
+			id = x.ID + 1
+		}
+	}
+	cm := &Comment{
+		ID:       id,
+		Author:   author,
+		Initials: initialsOf(author),
+		Items:    make([]interface{}, 0, 4),
+		file:     c.file,
+	}
+	for i, line := range strings.Split(text, "\n") {
+		p := cm.AddParagraph()
+		if i == 0 {
+			p.Children = append(p.Children, &Run{
+				Children: []interface{}{&AnnotationRef{}},
+				file:     c.file,
+			})
+		}
+		if line != "" {
+			p.AddText(line)
+		}
+	}
+	c.Comments = append(c.Comments, cm)
+	return cm
+}
+
+// initialsOf returns JD for John Doe
+func initialsOf(author string) string {
+	sb := strings.Builder{}
+	for _, w := range strings.Fields(author) {
+		for _, r := range w {
+			sb.WriteRune(r)
+			break
+		}
+	}
+	return sb.String()
+}
+
+// Comments returns all comments in the file
+func (f *Docx) Comments() []*Comment {
+	if f.comments == nil {
+		return nil
+	}
+	return f.comments.Comments
+}
+
+// Comment returns the comment of id, or nil
+func (f *Docx) Comment(id int) *Comment {
+	if f.comments == nil {
+		return nil
+	}
+	for _, c := range f.comments.Comments {
+		if c.ID == id {
+			return c
+		}
+	}
+	return nil
+}
+
+// Replies returns the comments replying to c
+func (c *Comment) Replies() []*Comment {
+	var replies []*Comment
+	for _, x := range c.file.Comments() {
+		if x.Parent == c {
+			replies = append(replies, x)
+		}
+	}
+	return replies
+}
+
+// AddParagraph adds a new paragraph to comment
+func (c *Comment) AddParagraph() *Paragraph {
+	p := &Paragraph{
+		Children: make([]interface{}, 0, 64),
+		file:     c.file,
+	}
+	c.Items = append(c.Items, p)
+	return p
+}
+
+// String returns the plain text of comment
+func (c *Comment) String() string {
+	return strings.TrimSpace(itemsString(c.Items))
+}
+
+// Anchor returns the plain text in document the comment is anchored to
+func (c *Comment) Anchor() string {
+	sb := strings.Builder{}
+	in := false
+	Walk(c.file, func(n Node, _ WalkPath) WalkAction {
+		switch o := n.(type) {
+		case *CommentRangeStart:
+			in = in || o.ID == c.ID
+		case *CommentRangeEnd:
+			in = in && o.ID != c.ID
+		case *Paragraph:
+			if in && sb.Len() > 0 {
+				sb.WriteByte('\n')
+			}
+		case *Deletion, *MoveFrom:
+			return WalkSkip
+		default:
+			if in {
+				writeText(&sb, o)
+			}
+		}
+		return WalkContinue
+	})
+	return strings.TrimSuffix(sb.String(), "\n")
+}
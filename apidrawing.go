@@ -49,6 +49,8 @@ func (p *Paragraph) AddInlineDrawing(pic []byte) (*Run, error) {
 	}
 	d := &Drawing{
 		Inline: &WPInline{
+			file: p.file,
+
 			// AnchorID: fmt.Sprintf("%08X", rand.Uint32()),
 			// EditID:   fmt.Sprintf("%08X", rand.Uint32()),
 
@@ -105,6 +107,7 @@ func (p *Paragraph) AddInlineDrawing(pic []byte) (*Run, error) {
 	run := &Run{
 		RunProperties: &RunProperties{},
 		Children:      c,
+		file:          p.file,
 	}
 	p.Children = append(p.Children, run)
 	return run, nil
@@ -151,6 +154,8 @@ func (p *Paragraph) AddAnchorDrawing(pic []byte) (*Run, error) {
 	}
 	d := &Drawing{
 		Anchor: &WPAnchor{
+			file: p.file,
+
 			LayoutInCell: 1,
 			AllowOverlap: 1,
 
@@ -216,6 +221,7 @@ func (p *Paragraph) AddAnchorDrawing(pic []byte) (*Run, error) {
 	run := &Run{
 		RunProperties: &RunProperties{},
 		Children:      c,
+		file:          p.file,
 	}
 	p.Children = append(p.Children, run)
 	return run, nil
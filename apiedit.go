@@ -0,0 +1,332 @@
+/*
+   Copyright (c) 2020 gingfrederik
+   Copyright (c) 2021 Gonzalo Fernandez-Victorio
+   Copyright (c) 2021 Basement Crowd Ltd (https://www.basementcrowd.com)
+   Copyright (c) 2023 Fumiama Minamoto (源文雨)
+
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU Affero General Public License as published
+   by the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU Affero General Public License for more details.
+
+   You should have received a copy of the GNU Affero General Public License
+   along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package docx
+
+// IndexOf returns the index of item in body, or -1 if it is not there
+func (b *Body) IndexOf(item interface{}) int {
+	return itemIndex(b.Items, item)
+}
+
+// Insert puts item at index i of body, before the trailing section properties.
+//
+// An item of another document is cloned into this one,
+// so the item actually inserted is returned, with the
+// *RelationshipError of the clone if any.
+func (b *Body) Insert(i int, item interface{}) (interface{}, error) {
+	item, err := adopt(b.file, item)
+	limit := len(b.Items)
+	if b.lastSectPr() != nil {
+		limit--
+	}
+	b.Items = insertItem(b.Items, clamp(i, limit), item)
+	return item, err
+}
+
+// Remove removes item from body and reports whether it was there
+func (b *Body) Remove(item interface{}) bool {
+	i := b.IndexOf(item)
+	if i < 0 {
+		return false
+	}
+	b.Items = removeItem(b.Items, i)
+	return true
+}
+
+// Move moves item to index i of body and reports whether it was there
+func (b *Body) Move(item interface{}, i int) bool {
+	if !b.Remove(item) {
+		return false
+	}
+	b.Insert(i, item)
+	return true
+}
+
+// InsertParagraphAt inserts a new paragraph at index i of body
+func (f *Docx) InsertParagraphAt(i int) *Paragraph {
+	p := f.AddParagraph()
+	f.Document.Body.Move(p, i)
+	return p
+}
+
+// InsertParagraphBefore inserts a new paragraph before item,
+// or returns nil if item is not in body
+func (f *Docx) InsertParagraphBefore(item interface{}) *Paragraph {
+	i := f.Document.Body.IndexOf(item)
+	if i < 0 {
+		return nil
+	}
+	return f.InsertParagraphAt(i)
+}
+
+// InsertParagraphAfter inserts a new paragraph after item,
+// or returns nil if item is not in body
+func (f *Docx) InsertParagraphAfter(item interface{}) *Paragraph {
+	i := f.Document.Body.IndexOf(item)
+	if i < 0 {
+		return nil
+	}
+	return f.InsertParagraphAt(i + 1)
+}
+
+// InsertTableAt inserts a new table by col*row at index i of body
+func (f *Docx) InsertTableAt(i, row, col int) *Table {
+	tbl := f.AddTable(row, col)
+	f.Document.Body.Move(tbl, i)
+	return tbl
+}
+
+// InsertTableBefore inserts a new table by col*row before item,
+// or returns nil if item is not in body
+func (f *Docx) InsertTableBefore(item interface{}, row, col int) *Table {
+	i := f.Document.Body.IndexOf(item)
+	if i < 0 {
+		return nil
+	}
+	return f.InsertTableAt(i, row, col)
+}
+
+// InsertTableAfter inserts a new table by col*row after item,
+// or returns nil if item is not in body
+func (f *Docx) InsertTableAfter(item interface{}, row, col int) *Table {
+	i := f.Document.Body.IndexOf(item)
+	if i < 0 {
+		return nil
+	}
+	return f.InsertTableAt(i+1, row, col)
+}
+
+// IndexOf returns the index of item in paragraph, or -1 if it is not there
+func (p *Paragraph) IndexOf(item interface{}) int {
+	return itemIndex(p.Children, item)
+}
+
+// Insert puts item like a run or a hyperlink at index i of paragraph.
+//
+// An item of another document is cloned into this one,
+// so the item actually inserted is returned, with the
+// *RelationshipError of the clone if any.
+func (p *Paragraph) Insert(i int, item interface{}) (interface{}, error) {
+	item, err := adopt(p.file, item)
+	p.Children = insertItem(p.Children, clamp(i, len(p.Children)), item)
+	return item, err
+}
+
+// Remove removes item from paragraph and reports whether it was there
+func (p *Paragraph) Remove(item interface{}) bool {
+	i := p.IndexOf(item)
+	if i < 0 {
+		return false
+	}
+	p.Children = removeItem(p.Children, i)
+	return true
+}
+
+// Move moves item to index i of paragraph and reports whether it was there
+func (p *Paragraph) Move(item interface{}, i int) bool {
+	if !p.Remove(item) {
+		return false
+	}
+	p.Insert(i, item)
+	return true
+}
+
+// InsertTextAt inserts a new run of text at index i of paragraph
+func (p *Paragraph) InsertTextAt(i int, text string) *Run {
+	r := p.AddText(text)
+	p.Move(r, i)
+	return r
+}
+
+// InsertTextBefore inserts a new run of text before item,
+// or returns nil if item is not in paragraph
+func (p *Paragraph) InsertTextBefore(item interface{}, text string) *Run {
+	i := p.IndexOf(item)
+	if i < 0 {
+		return nil
+	}
+	return p.InsertTextAt(i, text)
+}
+
+// InsertTextAfter inserts a new run of text after item,
+// or returns nil if item is not in paragraph
+func (p *Paragraph) InsertTextAfter(item interface{}, text string) *Run {
+	i := p.IndexOf(item)
+	if i < 0 {
+		return nil
+	}
+	return p.InsertTextAt(i+1, text)
+}
+
+// IndexOf returns the index of item in cell, or -1 if it is not there
+func (c *WTableCell) IndexOf(item interface{}) int {
+	return itemIndex(c.Items, item)
+}
+
+// Insert puts item like a paragraph or a table at index i of cell.
+//
+// An item of another document is cloned into this one,
+// so the item actually inserted is returned, with the
+// *RelationshipError of the clone if any.
+func (c *WTableCell) Insert(i int, item interface{}) (interface{}, error) {
+	item, err := adopt(c.file, item)
+	c.Items = insertItem(c.Items, clamp(i, len(c.Items)), item)
+	return item, err
+}
+
+// Remove removes item from cell and reports whether it was there
+func (c *WTableCell) Remove(item interface{}) bool {
+	i := c.IndexOf(item)
+	if i < 0 {
+		return false
+	}
+	c.Items = removeItem(c.Items, i)
+	return true
+}
+
+// Move moves item to index i of cell and reports whether it was there
+func (c *WTableCell) Move(item interface{}, i int) bool {
+	if !c.Remove(item) {
+		return false
+	}
+	c.Insert(i, item)
+	return true
+}
+
+// InsertParagraphAt inserts a new paragraph at index i of cell
+func (c *WTableCell) InsertParagraphAt(i int) *Paragraph {
+	p := c.AddParagraph()
+	c.Move(p, i)
+	return p
+}
+
+// InsertParagraphBefore inserts a new paragraph before item,
+// or returns nil if item is not in cell
+func (c *WTableCell) InsertParagraphBefore(item interface{}) *Paragraph {
+	i := c.IndexOf(item)
+	if i < 0 {
+		return nil
+	}
+	return c.InsertParagraphAt(i)
+}
+
+// InsertParagraphAfter inserts a new paragraph after item,
+// or returns nil if item is not in cell
+func (c *WTableCell) InsertParagraphAfter(item interface{}) *Paragraph {
+	i := c.IndexOf(item)
+	if i < 0 {
+		return nil
+	}
+	return c.InsertParagraphAt(i + 1)
+}
+
+// InsertTableAt inserts a new table by col*row at index i of cell
+func (c *WTableCell) InsertTableAt(i, row, col int) *Table {
+	tbl := c.AddTable(row, col)
+	c.Move(tbl, i)
+	return tbl
+}
+
+// adopt returns item ready to be put into f. An item of another document
+// is cloned into f, and the nodes in item without a document are given f.
+func adopt(f *Docx, item interface{}) (interface{}, error) {
+	if from := fileOf(item); from != nil && from != f {
+		c := newCloner(from, f)
+		return c.clone(item), c.err
+	}
+	Walk(item, func(n Node, _ WalkPath) WalkAction {
+		switch o := n.(type) {
+		case *Paragraph:
+			if o.file == nil {
+				o.file = f
+			}
+		case *Run:
+			if o.file == nil {
+				o.file = f
+			}
+		case *Table:
+			if o.file == nil {
+				o.file = f
+			}
+		case *WTableRow:
+			if o.file == nil {
+				o.file = f
+			}
+		case *WTableCell:
+			if o.file == nil {
+				o.file = f
+			}
+		}
+		return WalkContinue
+	})
+	return item, nil
+}
+
+// fileOf returns the document of item, or nil if it is unknown
+func fileOf(item interface{}) *Docx {
+	switch o := item.(type) {
+	case *Paragraph:
+		return o.file
+	case *Run:
+		return o.file
+	case *Table:
+		return o.file
+	case *WTableRow:
+		return o.file
+	case *WTableCell:
+		return o.file
+	case *Drawing:
+		return o.file
+	}
+	return nil
+}
+
+func clamp(i, limit int) int {
+	if i < 0 {
+		return 0
+	}
+	if i > limit {
+		return limit
+	}
+	return i
+}
+
+// itemIndex returns the index of item in items, or -1 if it is not there
+func itemIndex(items []interface{}, item interface{}) int {
+	for i, x := range items {
+		if x == item {
+			return i
+		}
+	}
+	return -1
+}
+
+func insertItem(items []interface{}, i int, item interface{}) []interface{} {
+	items = append(items, nil)
+	copy(items[i+1:], items[i:])
+	items[i] = item
+	return items
+}
+
+func removeItem(items []interface{}, i int) []interface{} {
+	copy(items[i:], items[i+1:])
+	items[len(items)-1] = nil
+	return items[:len(items)-1]
+}
@@ -0,0 +1,104 @@
+/*
+   Copyright (c) 2020 gingfrederik
+   Copyright (c) 2021 Gonzalo Fernandez-Victorio
+   Copyright (c) 2021 Basement Crowd Ltd (https://www.basementcrowd.com)
+   Copyright (c) 2023 Fumiama Minamoto (源文雨)
+
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU Affero General Public License as published
+   by the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU Affero General Public License for more details.
+
+   You should have received a copy of the GNU Affero General Public License
+   along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package docx
+
+import (
+	"os"
+	"testing"
+)
+
+func TestStructuralEditing(t *testing.T) {
+	doc := NewA4()
+	first := doc.AddParagraph()
+	first.AddText("first")
+	last := doc.AddParagraph()
+	last.AddText("last")
+	doc.Document.Body.sectPr()
+
+	mid := doc.InsertParagraphAfter(first)
+	mid.AddText("middle")
+	tbl := doc.InsertTableBefore(last, 1, 1)
+	tbl.TableRows[0].TableCells[0].AddParagraph().AddText("cell")
+	doc.InsertParagraphAt(100).AddText("end")
+	if doc.InsertParagraphBefore(&Paragraph{}) != nil {
+		t.Fatal("paragraph inserted before a foreign item")
+	}
+	if s := PlainText(&doc.Document.Body); s != "first\nmiddle\ncell\nlast\nend" {
+		t.Fatal("unexpected body", s)
+	}
+	if doc.Document.Body.lastSectPr() == nil {
+		t.Fatal("section properties are moved")
+	}
+
+	if !doc.Document.Body.Move(first, 3) || doc.Document.Body.IndexOf(first) != 3 {
+		t.Fatal("paragraph not moved")
+	}
+	if !doc.Document.Body.Remove(tbl) || doc.Document.Body.Remove(tbl) {
+		t.Fatal("table not removed")
+	}
+	if s := PlainText(&doc.Document.Body); s != "middle\nlast\nfirst\nend" {
+		t.Fatal("unexpected body", s)
+	}
+
+	r := last.Children[0].(*Run)
+	last.InsertTextBefore(r, "the ")
+	last.InsertTextAfter(r, " one").Bold()
+	last.Move(r, 0)
+	if s := last.String(); s != "lastthe  one" {
+		t.Fatal("unexpected paragraph", s)
+	}
+	if !last.Remove(r) || last.String() != "the  one" || last.Children[1].(*Run).file != doc {
+		t.Fatal("run not removed", last.String())
+	}
+
+	cell := doc.InsertTableAt(0, 1, 1).TableRows[0].TableCells[0]
+	a := cell.AddParagraph()
+	a.AddText("a")
+	cell.InsertParagraphBefore(a).AddText("b")
+	cell.InsertParagraphAfter(a).AddText("c")
+	cell.Move(a, 0)
+	if s := PlainText(cell); s != "a\nb\nc" {
+		t.Fatal("unexpected cell", s)
+	}
+
+	logo, err := os.ReadFile("testdata/fumiamayoko.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	other := NewA4()
+	op := other.AddParagraph()
+	_, err = op.AddInlineDrawing(logo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	it, err := doc.Document.Body.Insert(0, op)
+	if err != nil {
+		t.Fatal(err)
+	}
+	np := it.(*Paragraph)
+	if np == op || np.file != doc || np.String() != op.String() {
+		t.Fatal("paragraph of another document not cloned", np.String())
+	}
+	own := &Paragraph{Children: []interface{}{&Run{}}}
+	if it, err = cell.Insert(1, own); it != own || err != nil || own.file != doc || own.Children[0].(*Run).file != doc {
+		t.Fatal("paragraph without document not adopted")
+	}
+}
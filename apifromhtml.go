@@ -0,0 +1,808 @@
+/*
+   Copyright (c) 2020 gingfrederik
+   Copyright (c) 2021 Gonzalo Fernandez-Victorio
+   Copyright (c) 2021 Basement Crowd Ltd (https://www.basementcrowd.com)
+   Copyright (c) 2023 Fumiama Minamoto (源文雨)
+
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU Affero General Public License as published
+   by the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU Affero General Public License for more details.
+
+   You should have received a copy of the GNU Affero General Public License
+   along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package docx
+
+import (
+	"encoding/xml"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// htmlNode is an element of a parsed HTML document, or a text if tag is empty
+type htmlNode struct {
+	tag      string
+	attrs    map[string]string
+	text     string
+	children []*htmlNode
+}
+
+// FromHTMLOptions controls how FromHTML reads a document
+type FromHTMLOptions struct {
+	// ImageDir is the directory relative image paths are read from.
+	// No local files are read if both ImageDir and LoadImage are empty,
+	// and the paths out of ImageDir fail with ErrImageOutOfDir.
+	ImageDir string
+	// LoadImage reads the image of <img src="link">. It takes
+	// precedence over ImageDir, but not over data URIs.
+	LoadImage func(link string) ([]byte, error)
+}
+
+// cssColors are the named CSS colors FromHTML understands
+var cssColors = map[string]string{
+	"black": "000000", "silver": "C0C0C0", "gray": "808080", "grey": "808080",
+	"white": "FFFFFF", "maroon": "800000", "red": "FF0000", "purple": "800080",
+	"fuchsia": "FF00FF", "magenta": "FF00FF", "green": "008000", "lime": "00FF00",
+	"olive": "808000", "yellow": "FFFF00", "navy": "000080", "blue": "0000FF",
+	"teal": "008080", "aqua": "00FFFF", "cyan": "00FFFF", "orange": "FFA500",
+}
+
+// FromHTML builds a new A4 document from the subset of HTML that rich text
+// editors produce: paragraphs, headings, block quotes, pre, lists, tables with
+// merged cells, links, images and inline formatting set by tags or styles.
+// Unknown elements are read as their content. Tables are cut at 63 columns.
+func FromHTML(r io.Reader, opts FromHTMLOptions) (*Docx, error) {
+	root, err := parseHTML(r)
+	if err != nil {
+		return nil, err
+	}
+	m := &htmlReader{importer: newImporter(opts.ImageDir, opts.LoadImage)}
+	m.nodes(root.children, htmlBlock{}, htmlFormat{})
+	m.end()
+	if m.err != nil {
+		return nil, m.err
+	}
+	return m.f, nil
+}
+
+// htmlImplied maps a start tag to the open elements it ends,
+// and to the ones that stop looking for them
+var htmlImplied = map[string][2]string{
+	"li":    {"li", "ul ol table"},
+	"dt":    {"dt dd", "dl table"},
+	"dd":    {"dt dd", "dl table"},
+	"tr":    {"tr", "thead tbody tfoot table"},
+	"td":    {"td th", "tr table"},
+	"th":    {"td th", "tr table"},
+	"thead": {"thead tbody tfoot", "table"},
+	"tbody": {"thead tbody tfoot", "table"},
+	"tfoot": {"thead tbody tfoot", "table"},
+}
+
+// limits of table cells read by FromHTML
+const (
+	// htmlMaxColspan and htmlMaxRowspan are the limits of the HTML spec
+	htmlMaxColspan = 1000
+	htmlMaxRowspan = 65534
+	// htmlMaxColumns is the most columns Word shows in a table,
+	// the cells beyond are dropped
+	htmlMaxColumns = 63
+)
+
+// htmlParagraphEnders are the start tags that end an open <p>
+const htmlParagraphEnders = "address article aside blockquote dd div dl dt fieldset figure footer form " +
+	"h1 h2 h3 h4 h5 h6 header hr li main nav ol p pre section table ul"
+
+// parseHTML reads r into a tree of lowercase elements,
+// closing the elements whose end tags HTML allows to omit
+func parseHTML(r io.Reader) (*htmlNode, error) {
+	d := xml.NewDecoder(r)
+	d.Strict = false
+	d.Entity = xml.HTMLEntity
+	void := make(map[string]bool, len(xml.HTMLAutoClose))
+	for _, tag := range xml.HTMLAutoClose {
+		void[tag] = true
+	}
+	root := &htmlNode{}
+	stack := []*htmlNode{root}
+	// end pops the nearest open element in tags unless one in stops is nearer
+	end := func(tags, stops []string) {
+		for i := len(stack) - 1; i > 0; i-- {
+			if hasTag(stops, stack[i].tag) {
+				return
+			}
+			if hasTag(tags, stack[i].tag) {
+				stack = stack[:i]
+				return
+			}
+		}
+	}
+	for {
+		t, err := d.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch tt := t.(type) {
+		case xml.StartElement:
+			n := &htmlNode{tag: strings.ToLower(tt.Name.Local), attrs: make(map[string]string, len(tt.Attr))}
+			if tt.Name.Space != "" {
+				// foreign elements like <o:p> are read as their content
+				n.tag = "?"
+			}
+			for _, a := range tt.Attr {
+				n.attrs[strings.ToLower(a.Name.Local)] = a.Value
+			}
+			if hasTag(strings.Fields(htmlParagraphEnders), n.tag) {
+				end([]string{"p"}, []string{"li", "dd", "dt", "td", "th", "div", "blockquote", "table"})
+			}
+			if rule, ok := htmlImplied[n.tag]; ok {
+				end(strings.Fields(rule[0]), strings.Fields(rule[1]))
+			}
+			top := stack[len(stack)-1]
+			top.children = append(top.children, n)
+			if !void[n.tag] {
+				stack = append(stack, n)
+			}
+		case xml.EndElement:
+			tag := strings.ToLower(tt.Name.Local)
+			if tt.Name.Space != "" {
+				tag = "?"
+			}
+			end([]string{tag}, nil)
+		case xml.CharData:
+			top := stack[len(stack)-1]
+			top.children = append(top.children, &htmlNode{text: string(tt)})
+		}
+	}
+	return root, nil
+}
+
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// htmlBlock is the paragraph that inline content goes into
+type htmlBlock struct {
+	ctx   importContext
+	style string
+	align string
+	pre   bool
+}
+
+// htmlFormat is the run properties of inline content
+type htmlFormat struct {
+	bold, italic, underline, strike, code bool
+	vertAlign                             string
+	color, size, font                     string
+	highlight, fill                       string
+}
+
+// htmlReader holds the state of one FromHTML call
+type htmlReader struct {
+	*importer
+	// p is the paragraph being written, or nil to start a new one
+	p *Paragraph
+	// space is true if p is empty or ends with a space
+	space bool
+}
+
+// paragraph returns the paragraph being written, starting one of b if needed
+func (m *htmlReader) paragraph(b htmlBlock) *Paragraph {
+	if m.p == nil {
+		m.p = m.importer.paragraph(b.ctx, b.style)
+		if b.align != "" {
+			m.p.Justification(b.align)
+		}
+		m.space = true
+	}
+	return m.p
+}
+
+// end finishes the paragraph being written, dropping the trailing
+// spaces and line break which HTML does not show
+func (m *htmlReader) end() {
+	p := m.p
+	m.p = nil
+	if p == nil || len(p.Children) == 0 {
+		return
+	}
+	r, ok := p.Children[len(p.Children)-1].(*Run)
+	if !ok || len(r.Children) == 0 {
+		return
+	}
+	switch x := r.Children[len(r.Children)-1].(type) {
+	case *BarterRabbet:
+		if x.Type == "" && len(r.Children) == 1 {
+			p.Children = p.Children[:len(p.Children)-1]
+		}
+	case *Text:
+		x.Text = strings.TrimRight(x.Text, " ")
+	}
+}
+
+func (m *htmlReader) nodes(ns []*htmlNode, b htmlBlock, f htmlFormat) {
+	for _, n := range ns {
+		if m.err != nil {
+			return
+		}
+		m.node(n, b, f)
+	}
+}
+
+func (m *htmlReader) node(n *htmlNode, b htmlBlock, f htmlFormat) {
+	if n.tag == "" {
+		m.text(n.text, b, f)
+		return
+	}
+	css := parseCSS(n.attrs["style"])
+	if css["display"] == "none" {
+		return
+	}
+	if n.tag == "font" {
+		if c := n.attrs["color"]; c != "" && css["color"] == "" {
+			css["color"] = strings.ToLower(c)
+		}
+		if face := n.attrs["face"]; face != "" && css["font-family"] == "" {
+			css["font-family"] = face
+		}
+	}
+	f = f.with(n.tag, css, b.pre)
+	if a := n.attrs["align"]; a != "" {
+		css["text-align"] = strings.ToLower(a)
+	}
+	switch n.tag {
+	case "head", "title", "script", "style", "template":
+	case "br":
+		m.paragraph(b).addBreak()
+		m.space = true
+	case "img":
+		m.img(n, b)
+	case "a":
+		href := strings.TrimSpace(n.attrs["href"])
+		if href == "" || strings.HasPrefix(href, "#") {
+			m.nodes(n.children, b, f)
+			return
+		}
+		text := strings.Join(strings.Fields(n.plainText()), " ")
+		if text == "" {
+			text = href
+		}
+		if m.space || m.p == nil {
+			text = strings.TrimLeft(text, " ")
+		}
+		m.paragraph(b).AddLink(text, href)
+		m.space = false
+	case "ul", "ol":
+		m.end()
+		m.list(n, b, f)
+	case "table":
+		m.end()
+		m.table(n, b, f)
+	case "hr":
+		m.end()
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		b.style = m.style("Heading" + n.tag[1:])
+		m.block(n, b, f, css)
+	case "pre":
+		b.style, b.pre = m.style(SOURCE_CODE_STYLE), true
+		m.block(n, b, f, css)
+	case "blockquote":
+		b.ctx.quote, b.style = true, ""
+		m.block(n, b, f, css)
+	case "p", "div", "li", "dd", "dt", "dl", "center", "caption", "figure", "figcaption",
+		"section", "article", "header", "footer", "main", "nav", "aside", "address":
+		if n.tag == "center" || n.tag == "caption" {
+			b.align = "center"
+		}
+		m.block(n, b, f, css)
+	default:
+		m.nodes(n.children, b, f)
+	}
+}
+
+// block reads n into paragraphs of its own
+func (m *htmlReader) block(n *htmlNode, b htmlBlock, f htmlFormat, css map[string]string) {
+	if a := cssAlign(css["text-align"]); a != "" {
+		b.align = a
+	}
+	m.end()
+	m.nodes(n.children, b, f)
+	m.end()
+}
+
+// text adds the text with its white spaces collapsed outside <pre>
+func (m *htmlReader) text(s string, b htmlBlock, f htmlFormat) {
+	if b.pre {
+		if m.p == nil {
+			// a newline right after <pre> is not shown
+			s = strings.TrimPrefix(strings.TrimPrefix(s, "\r"), "\n")
+		}
+		s = strings.ReplaceAll(strings.ReplaceAll(s, "\r\n", "\n"), "\t", "    ")
+		for i, line := range strings.Split(s, "\n") {
+			if i > 0 {
+				m.paragraph(b).addBreak()
+			}
+			if line != "" {
+				f.run(m.paragraph(b).AddText(line), m.importer)
+			}
+		}
+		return
+	}
+	var sb strings.Builder
+	space := m.space || m.p == nil
+	for _, c := range s {
+		switch c {
+		case ' ', '\t', '\n', '\r', '\f':
+			if !space {
+				sb.WriteByte(' ')
+			}
+			space = true
+		default:
+			sb.WriteRune(c)
+			space = false
+		}
+	}
+	if sb.Len() == 0 {
+		return
+	}
+	f.run(m.paragraph(b).AddText(sb.String()), m.importer)
+	m.space = space
+}
+
+// img adds the image of n, sized by its width and height in pixels
+func (m *htmlReader) img(n *htmlNode, b htmlBlock) {
+	src := strings.TrimSpace(n.attrs["src"])
+	if src == "" {
+		return
+	}
+	d := m.image(m.paragraph(b), n.attrs["alt"], src)
+	m.space = false
+	if d == nil || d.Inline == nil || d.Inline.Extent == nil {
+		return
+	}
+	w, h := d.Inline.Extent.CX, d.Inline.Extent.CY
+	pw, errw := strconv.Atoi(strings.TrimSuffix(n.attrs["width"], "px"))
+	ph, errh := strconv.Atoi(strings.TrimSuffix(n.attrs["height"], "px"))
+	switch {
+	case errw == nil && errh == nil && pw > 0 && ph > 0:
+		w, h = int64(pw)*9525, int64(ph)*9525
+	case errw == nil && pw > 0 && w > 0:
+		w, h = int64(pw)*9525, h*int64(pw)*9525/w
+	case errh == nil && ph > 0 && h > 0:
+		w, h = w*int64(ph)*9525/h, int64(ph)*9525
+	default:
+		return
+	}
+	d.Inline.Size(w, h)
+}
+
+// list reads <ul> or <ol> into a numbering, nested in the list of b if any
+func (m *htmlReader) list(n *htmlNode, b htmlBlock, f htmlFormat) {
+	if b.ctx.item != nil && *b.ctx.item {
+		// the item starts with this list, so it gets an empty paragraph first
+		m.importer.paragraph(b.ctx, "")
+	}
+	lb := b
+	lb.style, lb.pre, lb.ctx.item = "", false, nil
+	if b.ctx.list == nil {
+		lb.ctx.list = m.newList()
+		lb.ctx.level = 0
+	} else if b.ctx.level < 8 {
+		lb.ctx.level = b.ctx.level + 1
+	}
+	if l := lb.ctx.list; !l.set[lb.ctx.level] {
+		l.set[lb.ctx.level], l.ordered[lb.ctx.level], l.start[lb.ctx.level] = true, n.tag == "ol", 1
+		if s, err := strconv.Atoi(n.attrs["start"]); err == nil && s >= 0 {
+			l.start[lb.ctx.level] = s
+		}
+	}
+	for _, c := range n.children {
+		if m.err != nil {
+			break
+		}
+		if c.tag != "li" {
+			m.node(c, lb, f)
+			continue
+		}
+		m.end()
+		pending := true
+		ib := lb
+		ib.ctx.item = &pending
+		m.node(c, ib, f)
+		m.end()
+		if pending {
+			m.importer.paragraph(ib.ctx, "")
+		}
+	}
+	m.end()
+	if b.ctx.list == nil {
+		m.finishList(lb.ctx.list)
+	}
+}
+
+// htmlCell is a <td> or <th> placed on the grid of its table
+type htmlCell struct {
+	n                          *htmlNode
+	row, col, colspan, rowspan int
+}
+
+// table reads n into a table with merged cells
+func (m *htmlReader) table(n *htmlNode, b htmlBlock, f htmlFormat) {
+	var rows []*htmlNode
+	head := 0 // rows of the leading thead
+	for _, c := range n.children {
+		switch c.tag {
+		case "tr":
+			rows = append(rows, c)
+		case "thead", "tbody", "tfoot":
+			for _, r := range c.children {
+				if r.tag != "tr" {
+					continue
+				}
+				if c.tag == "thead" && len(rows) == head {
+					head++
+				}
+				rows = append(rows, r)
+			}
+		case "caption":
+			m.node(c, b, f)
+		}
+	}
+	b.style, b.pre = "", false
+	if b.ctx.item != nil {
+		*b.ctx.item = false
+	}
+	grid := make([][]*htmlCell, len(rows))
+	cols := 0
+	for i, r := range rows {
+		col := 0
+		for _, c := range r.children {
+			if c.tag != "td" && c.tag != "th" {
+				continue
+			}
+			for col < len(grid[i]) && grid[i][col] != nil {
+				col++
+			}
+			if col >= htmlMaxColumns {
+				break
+			}
+			hc := &htmlCell{n: c, row: i, col: col, colspan: 1, rowspan: 1}
+			if s, err := strconv.Atoi(c.attrs["colspan"]); err == nil && s > 1 {
+				hc.colspan = s
+				if s > htmlMaxColspan {
+					hc.colspan = htmlMaxColspan
+				}
+				if col+hc.colspan > htmlMaxColumns {
+					hc.colspan = htmlMaxColumns - col
+				}
+			}
+			if s, err := strconv.Atoi(c.attrs["rowspan"]); err == nil && s != 1 {
+				hc.rowspan = s
+				if s > htmlMaxRowspan {
+					hc.rowspan = htmlMaxRowspan
+				}
+				if s <= 0 || hc.rowspan > len(rows)-i {
+					hc.rowspan = len(rows) - i
+				}
+			}
+			for j := i; j < i+hc.rowspan; j++ {
+				for len(grid[j]) < col+hc.colspan {
+					grid[j] = append(grid[j], nil)
+				}
+				for k := col; k < col+hc.colspan; k++ {
+					grid[j][k] = hc
+				}
+			}
+			col += hc.colspan
+		}
+		if len(grid[i]) > cols {
+			cols = len(grid[i])
+		}
+	}
+	if cols == 0 {
+		return
+	}
+	width := m.width
+	if b.ctx.cell != nil {
+		if w, ok := cellsWidth([]*WTableCell{b.ctx.cell}); ok && w > 2*cellMargin {
+			width = w - 2*cellMargin
+		}
+	}
+	widths := make([]int64, cols)
+	for i := range widths {
+		widths[i] = width / int64(cols)
+	}
+	var tbl *Table
+	if b.ctx.cell != nil {
+		tbl = b.ctx.cell.AddTableTwips(make([]int64, len(rows)), widths)
+	} else {
+		tbl = m.f.AddTableTwips(make([]int64, len(rows)), widths)
+	}
+	tbl.HeaderRows(head)
+	for i, tr := range tbl.TableRows {
+		cells := make([]*WTableCell, 0, cols)
+		for j := 0; j < cols; {
+			c := tr.TableCells[j]
+			var hc *htmlCell
+			if j < len(grid[i]) {
+				hc = grid[i][j]
+			}
+			span := 1
+			if hc != nil {
+				span = hc.colspan
+				if span > 1 {
+					c.TableCellProperties.GridSpan = &WGridSpan{Val: span}
+					c.TableCellProperties.TableCellWidth.W = widths[j] * int64(span)
+				}
+				if hc.rowspan > 1 {
+					c.TableCellProperties.VMerge = &WvMerge{}
+					if hc.row == i {
+						c.TableCellProperties.VMerge.Val = "restart"
+					}
+				}
+				if hc.row == i {
+					m.cell(c, hc.n, b, f)
+				}
+			}
+			if len(c.Items) == 0 {
+				c.AddParagraph()
+			}
+			cells = append(cells, c)
+			j += span
+		}
+		tr.TableCells = cells
+	}
+}
+
+// cell reads the content of <td> or <th> into c
+func (m *htmlReader) cell(c *WTableCell, n *htmlNode, b htmlBlock, f htmlFormat) {
+	css := parseCSS(n.attrs["style"])
+	if a := n.attrs["align"]; a != "" {
+		css["text-align"] = strings.ToLower(a)
+	}
+	if fill := cssColor(css["background-color"]); fill != "" {
+		c.Shade("clear", "auto", fill)
+	}
+	b.ctx = importContext{cell: c}
+	m.block(n, b, f.with(n.tag, css, false), css)
+}
+
+// plainText returns the texts in n
+func (n *htmlNode) plainText() string {
+	if n.tag == "" {
+		return n.text
+	}
+	var sb strings.Builder
+	for _, c := range n.children {
+		sb.WriteString(c.plainText())
+	}
+	return sb.String()
+}
+
+// with returns f changed by the tag and the style of an element
+func (f htmlFormat) with(tag string, css map[string]string, pre bool) htmlFormat {
+	switch tag {
+	case "b", "strong", "th":
+		f.bold = true
+	case "i", "em", "cite", "dfn", "var":
+		f.italic = true
+	case "u", "ins":
+		f.underline = true
+	case "s", "strike", "del":
+		f.strike = true
+	case "sub":
+		f.vertAlign = "subscript"
+	case "sup":
+		f.vertAlign = "superscript"
+	case "mark":
+		f.highlight = "yellow"
+	case "code", "kbd", "samp", "tt":
+		f.code = !pre
+	}
+	if len(css) == 0 {
+		return f
+	}
+	if c := cssColor(css["color"]); c != "" {
+		f.color = c
+	}
+	if c := cssColor(css["background-color"]); c != "" && tag != "td" && tag != "th" {
+		f.highlight, f.fill = "", c
+		for name, hex := range highlightColors {
+			if strings.EqualFold(hex[1:], c) {
+				f.highlight, f.fill = name, ""
+				break
+			}
+		}
+	}
+	if s := cssSize(css["font-size"]); s != "" {
+		f.size = s
+	}
+	switch w := css["font-weight"]; w {
+	case "":
+	case "bold", "bolder":
+		f.bold = true
+	case "normal", "lighter":
+		f.bold = false
+	default:
+		if n, err := strconv.Atoi(w); err == nil {
+			f.bold = n >= 600
+		}
+	}
+	switch css["font-style"] {
+	case "italic", "oblique":
+		f.italic = true
+	case "normal":
+		f.italic = false
+	}
+	for _, p := range []string{"text-decoration", "text-decoration-line"} {
+		d := css[p]
+		if d == "none" {
+			f.underline, f.strike = false, false
+		}
+		if strings.Contains(d, "underline") {
+			f.underline = true
+		}
+		if strings.Contains(d, "line-through") {
+			f.strike = true
+		}
+	}
+	switch css["vertical-align"] {
+	case "super":
+		f.vertAlign = "superscript"
+	case "sub":
+		f.vertAlign = "subscript"
+	case "baseline":
+		f.vertAlign = ""
+	}
+	if ff := css["font-family"]; ff != "" {
+		ff = strings.Trim(strings.TrimSpace(strings.Split(ff, ",")[0]), `"'`)
+		if ff != "" {
+			f.font = ff
+		}
+	}
+	return f
+}
+
+// run sets the properties of r by f
+func (f htmlFormat) run(r *Run, m *importer) {
+	preserveSpace(r)
+	if f.bold {
+		r.Bold()
+	}
+	if f.italic {
+		r.Italic()
+	}
+	if f.underline {
+		r.Underline("single")
+	}
+	if f.strike {
+		r.RunProperties.Strike = &Strike{Val: "true"}
+	}
+	if f.vertAlign != "" {
+		r.RunProperties.VertAlign = &VertAlign{Val: f.vertAlign}
+	}
+	if f.color != "" {
+		r.Color(f.color)
+	}
+	if f.size != "" {
+		r.Size(f.size)
+	}
+	if f.highlight != "" {
+		r.Highlight(f.highlight)
+	} else if f.fill != "" {
+		r.Shade("clear", "auto", f.fill)
+	}
+	if f.font != "" {
+		r.Font(f.font, f.font, "default")
+	}
+	if f.code {
+		r.Style(m.style(VERBATIM_STYLE))
+	}
+}
+
+// parseCSS reads an inline style, lowering the case of all but font names
+func parseCSS(style string) map[string]string {
+	css := make(map[string]string, 4)
+	for _, decl := range strings.Split(style, ";") {
+		k, v, ok := strings.Cut(decl, ":")
+		if !ok {
+			continue
+		}
+		k = strings.ToLower(strings.TrimSpace(k))
+		v = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(v), "!important"))
+		if k != "font-family" {
+			v = strings.ToLower(v)
+		}
+		css[k] = v
+	}
+	return css
+}
+
+// cssColor returns a CSS color as hex like FF0000, or "" if it is unknown
+func cssColor(s string) string {
+	s = strings.TrimSpace(s)
+	switch {
+	case strings.HasPrefix(s, "#"):
+		s = s[1:]
+		if len(s) == 3 {
+			s = string([]byte{s[0], s[0], s[1], s[1], s[2], s[2]})
+		}
+		if _, err := strconv.ParseUint(s, 16, 32); err != nil || len(s) != 6 {
+			return ""
+		}
+		return strings.ToUpper(s)
+	case strings.HasPrefix(s, "rgb(") || strings.HasPrefix(s, "rgba("):
+		args := strings.FieldsFunc(s[strings.IndexByte(s, '(')+1:], func(r rune) bool {
+			return r == ',' || r == ' ' || r == ')' || r == '/'
+		})
+		if len(args) < 3 || (len(args) > 3 && strings.TrimSpace(args[3]) == "0") {
+			return ""
+		}
+		var sb strings.Builder
+		for _, a := range args[:3] {
+			v, err := strconv.ParseFloat(strings.TrimSuffix(a, "%"), 64)
+			if err != nil {
+				return ""
+			}
+			if strings.HasSuffix(a, "%") {
+				v *= 2.55
+			}
+			x := strconv.FormatInt(int64(math.Max(0, math.Min(255, math.Round(v)))), 16)
+			if len(x) < 2 {
+				sb.WriteByte('0')
+			}
+			sb.WriteString(x)
+		}
+		return strings.ToUpper(sb.String())
+	}
+	return cssColors[s]
+}
+
+// cssSize returns a CSS font size in half points, or "" if it is not absolute
+func cssSize(s string) string {
+	unit := 1.0
+	switch {
+	case strings.HasSuffix(s, "pt"):
+	case strings.HasSuffix(s, "px"):
+		unit = 0.75
+	default:
+		return ""
+	}
+	v, err := strconv.ParseFloat(strings.TrimSpace(s[:len(s)-2]), 64)
+	if err != nil || v <= 0 {
+		return ""
+	}
+	return strconv.Itoa(int(math.Round(v * unit * 2)))
+}
+
+// cssAlign returns the justification of a text-align
+func cssAlign(s string) string {
+	switch s {
+	case "left", "start":
+		return "start"
+	case "center":
+		return "center"
+	case "right", "end":
+		return "end"
+	case "justify":
+		return "both"
+	}
+	return ""
+}
@@ -0,0 +1,178 @@
+/*
+   Copyright (c) 2020 gingfrederik
+   Copyright (c) 2021 Gonzalo Fernandez-Victorio
+   Copyright (c) 2021 Basement Crowd Ltd (https://www.basementcrowd.com)
+   Copyright (c) 2023 Fumiama Minamoto (源文雨)
+
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU Affero General Public License as published
+   by the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU Affero General Public License for more details.
+
+   You should have received a copy of the GNU Affero General Public License
+   along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package docx
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const editorHTML = `<!DOCTYPE html>
+<html><head><title>ignored</title><style>p { color: red }</style></head><body>
+<h1>Release <em>notes</em></h1>
+<p style="text-align:center">Hello <b>bold</b>, <span style="color:rgb(255, 0, 0);font-size:14pt">red</span>
+  &amp; <a href="https://example.com">the   site</a>.<br></p>
+<p><br></p>
+<ul><li>one<li>two<ul><li>two.a</li></ul></ul>
+<ol start=3><li><p>three</p><li>four</ol>
+<blockquote><p>quoted <code>x</code></blockquote>
+<table><thead><tr><th colspan="2">H</th><th>C</th></tr></thead>
+<tbody><tr><td rowspan="2">A<td>B<td>C<tr><td>D<td>E</tbody></table>
+<p>H<sub>2</sub>O <mark>m</mark> <s>old</s><o:p></o:p></p>
+<p><img alt="logo" width="40" src="data:image/png;base64,LOGO"></p>
+</body></html>`
+
+func TestFromHTML(t *testing.T) {
+	logo, err := os.ReadFile("testdata/fumiamayoko.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := strings.Replace(editorHTML, "LOGO", base64.StdEncoding.EncodeToString(logo), 1)
+	doc, err := FromHTML(strings.NewReader(src), FromHTMLOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var sb strings.Builder
+	err = doc.MarshalMarkdown(&sb, MarkdownOptions{SaveImage: func(name string, _ []byte) (string, error) {
+		return name, nil
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "# Release notes\n\n" +
+		"Hello **bold**, red & [the site](https://example.com).\n\n" +
+		"- one\n- two\n    - two.a\n" +
+		"3. three\n4. four\n\n" +
+		"*quoted x*\n\n" +
+		"| **H** |  | **C** |\n| --- | --- | --- |\n| A | B | C |\n|  | D | E |\n\n" +
+		"H2O m ~~old~~\n\n" +
+		"![logo](image1.png)\n"
+	if sb.String() != expected {
+		t.Fatalf("unexpected markdown:\n%s", sb.String())
+	}
+
+	var ps []*Paragraph
+	var tbl *Table
+	for _, it := range doc.Document.Body.Items {
+		switch o := it.(type) {
+		case *Paragraph:
+			ps = append(ps, o)
+		case *Table:
+			tbl = o
+		}
+	}
+	if len(ps) != 11 || tbl == nil {
+		t.Fatalf("expected 11 paragraphs and a table, got %d and %v", len(ps), tbl != nil)
+	}
+	if ps[0].Properties.Style.Val != "Heading1" || ps[1].Properties.Justification.Val != "center" {
+		t.Fatal("unexpected heading or alignment")
+	}
+	if len(ps[2].Children) != 0 {
+		t.Fatal("expected an empty paragraph for <p><br></p>")
+	}
+	red := ps[1].Children[3].(*Run)
+	if red.RunProperties.Color.Val != "FF0000" || red.RunProperties.Size.Val != "28" {
+		t.Fatal("unexpected style of red")
+	}
+	if ps[9].Children[1].(*Run).RunProperties.VertAlign.Val != "subscript" ||
+		ps[9].Children[3].(*Run).RunProperties.Highlight.Val != "yellow" {
+		t.Fatal("unexpected sub or mark")
+	}
+	if ps[8].Properties.Style.Val != QUOTE_STYLE {
+		t.Fatal("expected a quote")
+	}
+
+	nums := *doc.Numbering.Nums
+	if len(nums) != 2 {
+		t.Fatalf("expected 2 lists, got %d", len(nums))
+	}
+	abs := (*doc.Numbering.AbstractNums)[1]
+	if lvl := (*abs.Lvl)[0]; lvl.NumFmt.Val != "decimal" || lvl.Start.Val != "3" {
+		t.Fatal("unexpected format of the ordered list")
+	}
+	if ps[5].Properties.NumPr.Ilvl.Val != 1 {
+		t.Fatal("expected two.a at level 1")
+	}
+
+	if len(tbl.TableRows) != 3 || len(tbl.TableRows[0].TableCells) != 2 {
+		t.Fatal("unexpected table shape")
+	}
+	if gridSpanOf(tbl.TableRows[0].TableCells[0]) != 2 || tbl.Cell(0, 0).Node.(*WTableCell).Items[0].(*Paragraph).Children[0].(*Run).RunProperties.Bold == nil {
+		t.Fatal("expected a bold header spanning 2 columns")
+	}
+	if vMergeOf(tbl.TableRows[1].TableCells[0]) != "restart" || vMergeOf(tbl.TableRows[2].TableCells[0]) != "continue" {
+		t.Fatal("expected A spanning 2 rows")
+	}
+	if len(tbl.TableRows[2].TableCells[0].Items) != 1 {
+		t.Fatal("expected a paragraph in the merged cell")
+	}
+
+	img := ps[10].Children[0].(*Run).Children[0].(*Drawing)
+	if img.Inline.Extent.CX != 40*9525 || img.Inline.DocPr.Name != "logo" {
+		t.Fatal("unexpected image")
+	}
+
+	doc, err = FromHTML(strings.NewReader(`<table><tr><td>a<table><tr><td>b<td>c</table></table>`), FromHTMLOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	outer := doc.Document.Body.Items[0].(*Table).TableRows[0].TableCells[0]
+	if len(outer.Items) != 2 || PlainText(outer.Items[1].(*Table)) != "b\nc" {
+		t.Fatal("expected a nested table")
+	}
+
+	_, err = FromHTML(strings.NewReader(`<img src="missing.png">`), FromHTMLOptions{ImageDir: "testdata"})
+	if err == nil {
+		t.Fatal("expected an error of the missing image")
+	}
+
+	doc, err = FromHTML(strings.NewReader(`<table><tr><td colspan="3000000" rowspan="3000000">a</td><td>b</td></tr>`+
+		`<tr><td>c</td>`+strings.Repeat("<td>d</td>", 100)+`</tr></table>`), FromHTMLOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tbl = doc.Document.Body.Items[0].(*Table)
+	if len(tbl.TableRows) != 2 || gridSpanOf(tbl.TableRows[0].TableCells[0]) != htmlMaxColumns || len(tbl.TableRows[0].TableCells) != 1 || len(tbl.TableRows[1].TableCells) != 1 {
+		t.Fatal("spans not capped", len(tbl.TableRows), gridSpanOf(tbl.TableRows[0].TableCells[0]))
+	}
+
+	logoPath, err := filepath.Abs("testdata/fumiamayoko.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err = FromHTML(strings.NewReader(`<p><img src="`+logoPath+`" alt="logo"></p>`), FromHTMLOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.media) != 0 || PlainText(doc.Document.Body.Items[0]) != "logo" {
+		t.Fatal("local file read without ImageDir")
+	}
+	for _, src := range []string{logoPath, "../testdata/fumiamayoko.png", "x/%2e%2e/../fumiamayoko.png"} {
+		_, err = FromHTML(strings.NewReader(`<img src="`+src+`">`), FromHTMLOptions{ImageDir: "testdata"})
+		if err != ErrImageOutOfDir {
+			t.Fatal("expected ErrImageOutOfDir for", src, "got", err)
+		}
+	}
+}
@@ -0,0 +1,737 @@
+/*
+   Copyright (c) 2020 gingfrederik
+   Copyright (c) 2021 Gonzalo Fernandez-Victorio
+   Copyright (c) 2021 Basement Crowd Ltd (https://www.basementcrowd.com)
+   Copyright (c) 2023 Fumiama Minamoto (源文雨)
+
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU Affero General Public License as published
+   by the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU Affero General Public License for more details.
+
+   You should have received a copy of the GNU Affero General Public License
+   along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package docx
+
+import (
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	mdFenceRegex      = regexp.MustCompile("^( {0,3})(`{3,}|~{3,})[^`]*$")
+	mdATXRegex        = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?[ \t]*$`)
+	mdATXCloseRegex   = regexp.MustCompile(`(?:^|[ \t]+)#+$`)
+	mdRuleRegex       = regexp.MustCompile(`^ {0,3}(?:(?:\*[ \t]*){3,}|(?:-[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+	mdSetextRegex     = regexp.MustCompile(`^ {0,3}(=+|-+)[ \t]*$`)
+	mdQuoteRegex      = regexp.MustCompile(`^ {0,3}> ?`)
+	mdItemRegex       = regexp.MustCompile(`^( {0,3})([-+*]|(\d{1,9})[.)])([ \t]+|$)`)
+	mdTableDelimRegex = regexp.MustCompile(`^ {0,3}\|?[ \t]*:?-+:?[ \t]*(?:\|[ \t]*:?-+:?[ \t]*)*\|?[ \t]*$`)
+	mdAutolinkRegex   = regexp.MustCompile(`^<([a-zA-Z][a-zA-Z0-9+.-]{1,31}:[^\s<>]*)>`)
+	mdEmailRegex      = regexp.MustCompile(`^<([a-zA-Z0-9.!#$%&'*+/=?^_{|}~-]+@[a-zA-Z0-9-]+(?:\.[a-zA-Z0-9-]+)*)>`)
+	mdBareLinkRegex   = regexp.MustCompile(`^(?:https?://|www\.)[^\s<]*[^\s<?!.,:*_~)'"]`)
+	mdBrRegex         = regexp.MustCompile(`(?i)^<br\s*/?>`)
+	mdCellBrRegex     = regexp.MustCompile(`(?i)<br\s*/?>`)
+)
+
+// FromMarkdown builds a new A4 document from CommonMark
+// with the tables and strikethrough of GitHub flavored Markdown.
+//
+// Headings get the styles Heading1 to Heading6, code blocks SourceCode,
+// block quotes Quote and code spans VerbatimChar, which are added if the
+// template lacks them. Each list gets a numbering of its own, nested by
+// the indentation of its items. Images are read by opts.LoadImage, or
+// from the files under opts.ImageDir, except that images linked to a URL
+// become links if opts.LoadImage is nil. No local file is read if both
+// are empty, and paths out of opts.ImageDir fail with ErrImageOutOfDir.
+func FromMarkdown(r io.Reader, opts MarkdownOptions) (*Docx, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	text := strings.TrimPrefix(string(data), "\ufeff")
+	text = strings.ReplaceAll(strings.ReplaceAll(text, "\r\n", "\n"), "\r", "\n")
+	lines := strings.Split(text, "\n")
+	for i, l := range lines {
+		lines[i] = expandLeadingTabs(l)
+	}
+	m := &markdownReader{newImporter(opts.ImageDir, opts.LoadImage)}
+	m.blocks(lines, importContext{})
+	if m.err != nil {
+		return nil, m.err
+	}
+	return m.f, nil
+}
+
+// markdownReader holds the state of one FromMarkdown call
+type markdownReader struct {
+	*importer
+}
+
+// blocks reads the lines of a container, like the whole document,
+// a block quote or a list item
+func (m *markdownReader) blocks(lines []string, ctx importContext) {
+	var para []string
+	flush := func() {
+		if len(para) > 0 {
+			p := m.paragraph(ctx, "")
+			m.inline(p, strings.TrimRight(strings.Join(para, "\n"), " \t"), false)
+			para = nil
+		}
+	}
+	for i := 0; i < len(lines) && m.err == nil; i++ {
+		line := lines[i]
+		if strings.TrimSpace(line) == "" {
+			flush()
+			continue
+		}
+		if len(para) > 0 {
+			if sm := mdSetextRegex.FindStringSubmatch(line); sm != nil {
+				level := 1
+				if sm[1][0] == '-' {
+					level = 2
+				}
+				m.heading(level, strings.TrimSpace(strings.Join(para, "\n")), ctx)
+				para = nil
+				continue
+			}
+		} else if leadingSpaces(line) >= 4 {
+			var code []string
+			j := i
+			for ; j < len(lines) && (leadingSpaces(lines[j]) >= 4 || strings.TrimSpace(lines[j]) == ""); j++ {
+				code = append(code, trimIndent(lines[j], 4))
+			}
+			for len(code) > 0 && strings.TrimSpace(code[len(code)-1]) == "" {
+				code = code[:len(code)-1]
+			}
+			m.code(code, ctx)
+			i = j - 1
+			continue
+		}
+		if sm := mdFenceRegex.FindStringSubmatch(line); sm != nil {
+			flush()
+			fence := sm[2]
+			var code []string
+			j := i + 1
+			for ; j < len(lines); j++ {
+				t := strings.TrimSpace(lines[j])
+				if leadingSpaces(lines[j]) < 4 && len(t) >= len(fence) && strings.Trim(t, fence[:1]) == "" {
+					break
+				}
+				code = append(code, trimIndent(lines[j], len(sm[1])))
+			}
+			m.code(code, ctx)
+			i = j
+			continue
+		}
+		if sm := mdATXRegex.FindStringSubmatch(line); sm != nil {
+			flush()
+			m.heading(len(sm[1]), mdATXCloseRegex.ReplaceAllString(sm[2], ""), ctx)
+			continue
+		}
+		if mdRuleRegex.MatchString(line) {
+			flush()
+			continue
+		}
+		if mdQuoteRegex.MatchString(line) {
+			flush()
+			var quoted []string
+			j := i
+			for ; j < len(lines) && mdQuoteRegex.MatchString(lines[j]); j++ {
+				quoted = append(quoted, mdQuoteRegex.ReplaceAllString(lines[j], ""))
+			}
+			qctx := ctx
+			qctx.quote = true
+			m.blocks(quoted, qctx)
+			i = j - 1
+			continue
+		}
+		if sm := mdItemRegex.FindStringSubmatch(line); sm != nil &&
+			(len(para) == 0 || (sm[4] != "" && (sm[3] == "" || sm[3] == "1"))) {
+			flush()
+			i = m.list(lines, i, ctx) - 1
+			continue
+		}
+		if len(para) == 0 && i+1 < len(lines) && strings.Contains(line, "|") && mdTableDelimRegex.MatchString(lines[i+1]) {
+			header, delims := splitTableRow(line), splitTableRow(lines[i+1])
+			if len(header) == len(delims) {
+				rows := [][]string{header}
+				j := i + 2
+				for ; j < len(lines) && strings.TrimSpace(lines[j]) != "" && !startsMarkdownBlock(lines[j]); j++ {
+					rows = append(rows, splitTableRow(lines[j]))
+				}
+				m.table(rows, delims, ctx)
+				i = j - 1
+				continue
+			}
+		}
+		para = append(para, strings.TrimLeft(line, " "))
+	}
+	flush()
+}
+
+// list reads the list beginning at lines[start] and returns the index of
+// the first line after it
+func (m *markdownReader) list(lines []string, start int, ctx importContext) int {
+	first := mdItemRegex.FindStringSubmatch(lines[start])
+	ordered := first[3] != ""
+	marker := first[2][len(first[2])-1]
+	lctx := ctx
+	if ctx.list == nil {
+		lctx.list = m.newList()
+		lctx.level = 0
+	} else if ctx.level < 8 {
+		lctx.level = ctx.level + 1
+	}
+	if l := lctx.list; !l.set[lctx.level] {
+		l.set[lctx.level], l.ordered[lctx.level], l.start[lctx.level] = true, ordered, 1
+		if ordered {
+			l.start[lctx.level], _ = strconv.Atoi(first[3])
+		}
+	}
+	i := start
+	for i < len(lines) && m.err == nil {
+		sm := mdItemRegex.FindStringSubmatch(lines[i])
+		if sm == nil || (sm[3] != "") != ordered || sm[2][len(sm[2])-1] != marker {
+			break
+		}
+		width := len(sm[0])
+		if sm[4] == "" || len(sm[4]) > 4 {
+			width = len(sm[1]) + len(sm[2]) + 1
+		}
+		item := []string{""}
+		if width < len(lines[i]) {
+			item[0] = lines[i][width:]
+		}
+		blank := false
+		j := i + 1
+	item:
+		for ; j < len(lines); j++ {
+			l := lines[j]
+			switch {
+			case strings.TrimSpace(l) == "":
+				item = append(item, "")
+				blank = true
+				continue
+			case leadingSpaces(l) >= width:
+				item = append(item, trimIndent(l, width))
+			case !blank && !startsMarkdownBlock(l):
+				item = append(item, strings.TrimLeft(l, " "))
+			default:
+				break item
+			}
+			blank = false
+		}
+		pending := true
+		ictx := lctx
+		ictx.item = &pending
+		m.blocks(item, ictx)
+		if pending {
+			m.paragraph(ictx, "")
+		}
+		i = j
+	}
+	if ctx.list == nil {
+		m.finishList(lctx.list)
+	}
+	return i
+}
+
+func (m *markdownReader) heading(level int, text string, ctx importContext) {
+	p := m.paragraph(ctx, m.style("Heading"+strconv.Itoa(level)))
+	m.inline(p, text, false)
+}
+
+// code writes lines as is into one paragraph
+func (m *markdownReader) code(lines []string, ctx importContext) {
+	p := m.paragraph(ctx, m.style(SOURCE_CODE_STYLE))
+	for i, l := range lines {
+		if i > 0 {
+			p.addBreak()
+		}
+		if l != "" {
+			preserveSpace(p.AddText(l))
+		}
+	}
+}
+
+func (m *markdownReader) table(rows [][]string, delims []string, ctx importContext) {
+	if ctx.item != nil {
+		*ctx.item = false
+	}
+	cols := len(delims)
+	widths := make([]int64, cols)
+	for i := range widths {
+		widths[i] = m.width / int64(cols)
+	}
+	aligns := make([]string, cols)
+	for i, d := range delims {
+		switch {
+		case strings.HasPrefix(d, ":") && strings.HasSuffix(d, ":"):
+			aligns[i] = "center"
+		case strings.HasSuffix(d, ":"):
+			aligns[i] = "end"
+		case strings.HasPrefix(d, ":"):
+			aligns[i] = "start"
+		}
+	}
+	tbl := m.f.AddTableTwips(make([]int64, len(rows)), widths)
+	for i, row := range rows {
+		for j, c := range tbl.TableRows[i].TableCells {
+			text := ""
+			if j < len(row) {
+				text = row[j]
+			}
+			for _, part := range mdCellBrRegex.Split(text, -1) {
+				p := c.AddParagraph()
+				if aligns[j] != "" {
+					p.Justification(aligns[j])
+				}
+				m.inline(p, strings.TrimSpace(part), i == 0)
+			}
+		}
+	}
+}
+
+// markdownToken is a piece of inline content
+type markdownToken struct {
+	text string
+	// delim is the char of an emphasis delimiter run,
+	// whose n chars are left unmatched
+	delim       byte
+	n           int
+	open, close bool
+
+	bold, italic, strike, code, br bool
+	// link is the destination of a link, or of an image if image is set
+	link  string
+	image bool
+}
+
+// literal is the text written for t
+func (t *markdownToken) literal() string {
+	if t.delim != 0 {
+		return strings.Repeat(string(t.delim), t.n)
+	}
+	return t.text
+}
+
+// sameRun tells whether u can be written into the run of t
+func (t *markdownToken) sameRun(u *markdownToken) bool {
+	return !u.image && !u.br && u.link == "" &&
+		t.bold == u.bold && t.italic == u.italic && t.strike == u.strike && t.code == u.code
+}
+
+// inline writes the inline content text into p
+func (m *markdownReader) inline(p *Paragraph, text string, bold bool) {
+	toks := parseMarkdownInline(text)
+	for i := 0; i < len(toks) && m.err == nil; {
+		t := &toks[i]
+		switch {
+		case t.image:
+			m.image(p, t.text, t.link)
+			i++
+		case t.link != "":
+			var sb strings.Builder
+			for ; i < len(toks) && toks[i].link == t.link && !toks[i].image; i++ {
+				sb.WriteString(toks[i].literal())
+			}
+			if sb.Len() == 0 {
+				sb.WriteString(t.link)
+			}
+			p.AddLink(sb.String(), t.link)
+		case t.br:
+			p.addBreak()
+			i++
+		default:
+			var sb strings.Builder
+			for ; i < len(toks) && t.sameRun(&toks[i]); i++ {
+				sb.WriteString(toks[i].literal())
+			}
+			if sb.Len() == 0 {
+				continue
+			}
+			r := preserveSpace(p.AddText(sb.String()))
+			if t.bold || bold {
+				r.Bold()
+			}
+			if t.italic {
+				r.Italic()
+			}
+			if t.strike {
+				r.RunProperties.Strike = &Strike{Val: "true"}
+			}
+			if t.code {
+				r.Style(m.style(VERBATIM_STYLE))
+			}
+		}
+	}
+}
+
+// parseMarkdownInline splits text into tokens with emphasis resolved
+func parseMarkdownInline(s string) []markdownToken {
+	var toks []markdownToken
+	var sb strings.Builder
+	flush := func() {
+		if sb.Len() > 0 {
+			toks = append(toks, markdownToken{text: sb.String()})
+			sb.Reset()
+		}
+	}
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s) && s[i+1] == '\n':
+			flush()
+			toks = append(toks, markdownToken{br: true})
+			i += 2
+			continue
+		case c == '\\' && i+1 < len(s) && isASCIIPunct(s[i+1]):
+			sb.WriteByte(s[i+1])
+			i += 2
+			continue
+		case c == '`':
+			n := countByte(s[i:], '`')
+			if end := codeSpanEnd(s, i+n, n); end >= 0 {
+				flush()
+				code := strings.ReplaceAll(s[i+n:end], "\n", " ")
+				if len(code) > 2 && code[0] == ' ' && code[len(code)-1] == ' ' && strings.TrimSpace(code) != "" {
+					code = code[1 : len(code)-1]
+				}
+				toks = append(toks, markdownToken{text: code, code: true})
+				i = end + n
+				continue
+			}
+			sb.WriteString(s[i : i+n])
+			i += n
+			continue
+		case c == '*' || c == '_' || c == '~':
+			n := countByte(s[i:], c)
+			if c == '~' && n > 2 {
+				sb.WriteString(s[i : i+n])
+				i += n
+				continue
+			}
+			before, _ := utf8.DecodeLastRuneInString(s[:i])
+			after, _ := utf8.DecodeRuneInString(s[i+n:])
+			left := !isMarkdownSpace(after) && (!isMarkdownPunct(after) || isMarkdownSpace(before) || isMarkdownPunct(before))
+			right := !isMarkdownSpace(before) && (!isMarkdownPunct(before) || isMarkdownSpace(after) || isMarkdownPunct(after))
+			open, close := left, right
+			if c == '_' {
+				open = left && (!right || isMarkdownPunct(before))
+				close = right && (!left || isMarkdownPunct(after))
+			}
+			flush()
+			toks = append(toks, markdownToken{delim: c, n: n, open: open, close: close})
+			i += n
+			continue
+		case c == '[' || c == '!' && i+1 < len(s) && s[i+1] == '[':
+			image := c == '!'
+			st := i
+			if image {
+				st++
+			}
+			if text, dest, end, ok := parseMarkdownLink(s, st); ok {
+				flush()
+				inner := parseMarkdownInline(text)
+				if image {
+					var alt strings.Builder
+					for _, t := range inner {
+						alt.WriteString(t.literal())
+					}
+					toks = append(toks, markdownToken{text: alt.String(), link: dest, image: true})
+				} else {
+					for _, t := range inner {
+						t.link, t.open, t.close = dest, false, false
+						toks = append(toks, t)
+					}
+				}
+				i = end
+				continue
+			}
+		case c == '<':
+			if br := mdBrRegex.FindString(s[i:]); br != "" {
+				flush()
+				toks = append(toks, markdownToken{br: true})
+				i += len(br)
+				continue
+			}
+			if sm := mdAutolinkRegex.FindStringSubmatch(s[i:]); sm != nil {
+				flush()
+				toks = append(toks, markdownToken{text: sm[1], link: sm[1]})
+				i += len(sm[0])
+				continue
+			}
+			if sm := mdEmailRegex.FindStringSubmatch(s[i:]); sm != nil {
+				flush()
+				toks = append(toks, markdownToken{text: sm[1], link: "mailto:" + sm[1]})
+				i += len(sm[0])
+				continue
+			}
+		case (c == 'h' || c == 'w') && (i == 0 || strings.IndexByte(" \t\n(*_~", s[i-1]) >= 0):
+			if link := mdBareLinkRegex.FindString(s[i:]); link != "" {
+				flush()
+				dest := link
+				if strings.HasPrefix(link, "www.") {
+					dest = "http://" + link
+				}
+				toks = append(toks, markdownToken{text: link, link: dest})
+				i += len(link)
+				continue
+			}
+		case c == '\n':
+			text := sb.String()
+			trimmed := strings.TrimRight(text, " ")
+			sb.Reset()
+			sb.WriteString(trimmed)
+			if len(text)-len(trimmed) >= 2 {
+				flush()
+				toks = append(toks, markdownToken{br: true})
+			} else {
+				sb.WriteByte(' ')
+			}
+			for i++; i < len(s) && s[i] == ' '; i++ {
+			}
+			continue
+		}
+		sb.WriteByte(c)
+		i++
+	}
+	flush()
+	resolveMarkdownEmphasis(toks)
+	return toks
+}
+
+// resolveMarkdownEmphasis matches the delimiter runs of toks
+// and sets the formatting of the tokens between them
+func resolveMarkdownEmphasis(toks []markdownToken) {
+	for c := 0; c < len(toks); c++ {
+		cl := &toks[c]
+		if cl.delim == 0 || !cl.close || cl.n == 0 {
+			continue
+		}
+		for o := c - 1; o >= 0; o-- {
+			op := &toks[o]
+			if op.delim != cl.delim || !op.open || op.n == 0 {
+				continue
+			}
+			if cl.delim == '~' {
+				if op.n != cl.n {
+					continue
+				}
+			} else if (op.close || cl.open) && (op.n+cl.n)%3 == 0 && (op.n%3 != 0 || cl.n%3 != 0) {
+				continue
+			}
+			use := 1
+			if op.n >= 2 && cl.n >= 2 {
+				use = 2
+			}
+			for k := o + 1; k < c; k++ {
+				t := &toks[k]
+				switch {
+				case cl.delim == '~':
+					t.strike = true
+				case use == 2:
+					t.bold = true
+				default:
+					t.italic = true
+				}
+				if t.delim != 0 {
+					t.open, t.close = false, false
+				}
+			}
+			op.n -= use
+			cl.n -= use
+			if cl.n > 0 {
+				c--
+			}
+			break
+		}
+	}
+}
+
+// parseMarkdownLink parses [text](dest "title") at s[i]
+// and returns the index after it
+func parseMarkdownLink(s string, i int) (text, dest string, end int, ok bool) {
+	depth := 0
+	j := i
+	for ; j < len(s); j++ {
+		switch s[j] {
+		case '\\':
+			j++
+			continue
+		case '[':
+			depth++
+		case ']':
+			depth--
+		}
+		if depth == 0 {
+			break
+		}
+	}
+	if j+1 >= len(s) || s[j+1] != '(' {
+		return "", "", 0, false
+	}
+	text = s[i+1 : j]
+	k := skipMarkdownSpace(s, j+2)
+	var sb strings.Builder
+	if k < len(s) && s[k] == '<' {
+		e := strings.IndexAny(s[k:], ">\n")
+		if e < 0 || s[k+e] != '>' {
+			return "", "", 0, false
+		}
+		sb.WriteString(s[k+1 : k+e])
+		k += e + 1
+	} else {
+		parens := 0
+	loop:
+		for ; k < len(s); k++ {
+			switch c := s[k]; {
+			case c == '\\' && k+1 < len(s) && isASCIIPunct(s[k+1]):
+				k++
+				sb.WriteByte(s[k])
+				continue
+			case c == '(':
+				parens++
+			case c == ')':
+				if parens == 0 {
+					break loop
+				}
+				parens--
+			case c == ' ' || c == '\t' || c == '\n':
+				break loop
+			}
+			sb.WriteByte(s[k])
+		}
+	}
+	k = skipMarkdownSpace(s, k)
+	if k < len(s) && (s[k] == '"' || s[k] == '\'' || s[k] == '(') {
+		closing := s[k]
+		if closing == '(' {
+			closing = ')'
+		}
+		e := strings.IndexByte(s[k+1:], closing)
+		if e < 0 {
+			return "", "", 0, false
+		}
+		k = skipMarkdownSpace(s, k+e+2)
+	}
+	if k >= len(s) || s[k] != ')' {
+		return "", "", 0, false
+	}
+	return text, sb.String(), k + 1, true
+}
+
+// splitTableRow splits a table row by the pipes not escaped
+func splitTableRow(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, `\|`) {
+		line = line[:len(line)-1]
+	}
+	var cells []string
+	last := 0
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case '|':
+			cells = append(cells, strings.TrimSpace(line[last:i]))
+			last = i + 1
+		}
+	}
+	return append(cells, strings.TrimSpace(line[last:]))
+}
+
+// startsMarkdownBlock tells whether line begins a block other than a paragraph
+func startsMarkdownBlock(line string) bool {
+	return mdItemRegex.MatchString(line) || mdATXRegex.MatchString(line) ||
+		mdFenceRegex.MatchString(line) || mdQuoteRegex.MatchString(line) || mdRuleRegex.MatchString(line)
+}
+
+// codeSpanEnd returns the index of the backtick string of length n
+// closing a code span, or -1
+func codeSpanEnd(s string, i, n int) int {
+	for i < len(s) {
+		j := strings.IndexByte(s[i:], '`')
+		if j < 0 {
+			return -1
+		}
+		i += j
+		k := countByte(s[i:], '`')
+		if k == n {
+			return i
+		}
+		i += k
+	}
+	return -1
+}
+
+func countByte(s string, c byte) int {
+	n := 0
+	for n < len(s) && s[n] == c {
+		n++
+	}
+	return n
+}
+
+func skipMarkdownSpace(s string, i int) int {
+	for i < len(s) && (s[i] == ' ' || s[i] == '\t' || s[i] == '\n') {
+		i++
+	}
+	return i
+}
+
+// isMarkdownSpace is true for whitespace and the ends of text
+func isMarkdownSpace(r rune) bool {
+	return r == utf8.RuneError || unicode.IsSpace(r)
+}
+
+func isASCIIPunct(c byte) bool {
+	return strings.IndexByte("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", c) >= 0
+}
+
+func isMarkdownPunct(r rune) bool {
+	return unicode.IsPunct(r) || unicode.IsSymbol(r)
+}
+
+func leadingSpaces(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+// trimIndent removes up to n leading spaces
+func trimIndent(line string, n int) string {
+	i := 0
+	for i < n && i < len(line) && line[i] == ' ' {
+		i++
+	}
+	return line[i:]
+}
+
+// expandLeadingTabs turns the tabs indenting line into spaces to the next tab stop of 4
+func expandLeadingTabs(line string) string {
+	var sb strings.Builder
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case ' ':
+			sb.WriteByte(' ')
+		case '\t':
+			sb.WriteString(strings.Repeat(" ", 4-sb.Len()%4))
+		default:
+			sb.WriteString(line[i:])
+			return sb.String()
+		}
+	}
+	return sb.String()
+}
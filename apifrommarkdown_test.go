@@ -0,0 +1,150 @@
+/*
+   Copyright (c) 2020 gingfrederik
+   Copyright (c) 2021 Gonzalo Fernandez-Victorio
+   Copyright (c) 2021 Basement Crowd Ltd (https://www.basementcrowd.com)
+   Copyright (c) 2023 Fumiama Minamoto (源文雨)
+
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU Affero General Public License as published
+   by the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU Affero General Public License for more details.
+
+   You should have received a copy of the GNU Affero General Public License
+   along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package docx
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+const releaseNotes = "# Release *1.2*\n" +
+	"\n" +
+	"We fixed **bold** bugs, `code`, 2 * 3 and ~~old~~ [docs](https://example.com/docs).  \n" +
+	"Second line\n" +
+	"\n" +
+	"- first\n" +
+	"  lazy\n" +
+	"- second\n" +
+	"  1. nested\n" +
+	"  2. again\n" +
+	"\n" +
+	"3. three\n" +
+	"\n" +
+	"> quoted *text*\n" +
+	"\n" +
+	"```go\n" +
+	"func main() {\n" +
+	"\treturn\n" +
+	"}\n" +
+	"```\n" +
+	"\n" +
+	"| Name | Price |\n" +
+	"| :--- | ---: |\n" +
+	"| a\\|b | 1<br>2 |\n" +
+	"\n" +
+	"![logo](fumiamayoko.png)\n"
+
+func TestFromMarkdown(t *testing.T) {
+	doc, err := FromMarkdown(strings.NewReader(releaseNotes), MarkdownOptions{ImageDir: "testdata"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sb strings.Builder
+	err = doc.MarshalMarkdown(&sb, MarkdownOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "# Release 1.2\n\n" +
+		"We fixed **bold** bugs, code, 2 \\* 3 and ~~old~~ [docs](https://example.com/docs).\\\nSecond line\n\n" +
+		"- first lazy\n- second\n    1. nested\n    2. again\n3. three\n\n" +
+		"*quoted text*\n\n" +
+		"func main() {\\\n    return\\\n}\n\n" +
+		"| **Name** | **Price** |\n| --- | --- |\n| a\\|b | 1<br>2 |\n\n" +
+		"![logo](media/image1.png)\n"
+	if sb.String() != expected {
+		t.Fatalf("unexpected markdown:\n%s", sb.String())
+	}
+
+	lvls := *(*doc.Numbering.AbstractNums)[1].Lvl
+	if len(*doc.Numbering.Nums) != 2 || lvls[0].NumFmt.Val != "decimal" || lvls[0].Start.Val != "3" {
+		t.Fatal("unexpected numbering")
+	}
+	var code, verbatim bool
+	for _, it := range doc.Document.Body.Items {
+		if p, ok := it.(*Paragraph); ok && paragraphStyleID(p) == SOURCE_CODE_STYLE {
+			code = true
+		}
+	}
+	Walk(doc, func(n Node, _ WalkPath) WalkAction {
+		if r, ok := n.(*Run); ok && r.RunProperties != nil && r.RunProperties.RunStyle != nil {
+			verbatim = verbatim || r.RunProperties.RunStyle.Val == VERBATIM_STYLE
+		}
+		return WalkContinue
+	})
+	if !code || !verbatim || doc.Style("Heading1") == nil || doc.Style(QUOTE_STYLE) == nil {
+		t.Fatal("styles not applied")
+	}
+
+	var buf bytes.Buffer
+	_, err = doc.WriteTo(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err = Parse(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(*doc.Numbering.AbstractNums) != 2 || (*(*doc.Numbering.AbstractNums)[0].Lvl)[0] == nil {
+		t.Fatal("numbering not saved")
+	}
+
+	_, err = FromMarkdown(strings.NewReader("![x](missing.png)"), MarkdownOptions{ImageDir: "testdata"})
+	if err == nil {
+		t.Fatal("missing image accepted")
+	}
+	_, err = FromMarkdown(strings.NewReader("![x](../testdata/fumiamayoko.png)"), MarkdownOptions{ImageDir: "testdata"})
+	if err != ErrImageOutOfDir {
+		t.Fatal("expected ErrImageOutOfDir, got", err)
+	}
+	doc, err = FromMarkdown(strings.NewReader("![x](testdata/fumiamayoko.png)"), MarkdownOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.media) != 0 {
+		t.Fatal("local file read without ImageDir")
+	}
+}
+
+func TestMarshalMarkdownParsedList(t *testing.T) {
+	const md = "3. first\n4. second\n    - nested\n        1. deep\n        2. deeper\n    - more\n        1. again\n5. third\n"
+	doc, err := FromMarkdown(strings.NewReader(md), MarkdownOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	_, err = doc.WriteTo(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err = Parse(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sb strings.Builder
+	err = doc.MarshalMarkdown(&sb, MarkdownOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sb.String() != md {
+		t.Fatalf("unexpected markdown:\n%s", sb.String())
+	}
+}
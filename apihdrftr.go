@@ -0,0 +1,223 @@
+/*
+   Copyright (c) 2020 gingfrederik
+   Copyright (c) 2021 Gonzalo Fernandez-Victorio
+   Copyright (c) 2021 Basement Crowd Ltd (https://www.basementcrowd.com)
+   Copyright (c) 2023 Fumiama Minamoto (源文雨)
+
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU Affero General Public License as published
+   by the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU Affero General Public License for more details.
+
+   You should have received a copy of the GNU Affero General Public License
+   along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package docx
+
+import (
+	"strconv"
+	"strings"
+)
+
+// AddHeader adds a header of kind (HDRFTR_DEFAULT, HDRFTR_FIRST or HDRFTR_EVEN)
+// to the last section, or returns the existing one
+//
+//	HDRFTR_EVEN only takes effect with evenAndOddHeaders set in settings.xml
+func (f *Docx) AddHeader(kind string) *Header {
+	if h := f.Header(kind); h != nil {
+		return h
+	}
+	h := &Header{HeaderFooter: HeaderFooter{
+		Items: make([]interface{}, 0, 64),
+		file:  f,
+		name:  f.newPartName("header"),
+	}}
+	h.setNamespaces()
+	h.id = f.addPartRelation(REL_HEADER, h.name)
+	sp := f.Document.Body.sectPr()
+	if sp.HeaderReference == nil {
+		sp.HeaderReference = &[]HeaderReference{}
+	}
+	*sp.HeaderReference = append(*sp.HeaderReference, HeaderReference{Type: kind, Id: h.id})
+	if kind == HDRFTR_FIRST {
+		sp.TitlePg = &TitlePg{}
+	}
+	f.headers = append(f.headers, h)
+	return h
+}
+
+// AddFooter adds a footer of kind (HDRFTR_DEFAULT, HDRFTR_FIRST or HDRFTR_EVEN)
+// to the last section, or returns the existing one
+//
+//	HDRFTR_EVEN only takes effect with evenAndOddHeaders set in settings.xml
+func (f *Docx) AddFooter(kind string) *Footer {
+	if ft := f.Footer(kind); ft != nil {
+		return ft
+	}
+	ft := &Footer{HeaderFooter: HeaderFooter{
+		Items: make([]interface{}, 0, 64),
+		file:  f,
+		name:  f.newPartName("footer"),
+	}}
+	ft.setNamespaces()
+	ft.id = f.addPartRelation(REL_FOOTER, ft.name)
+	sp := f.Document.Body.sectPr()
+	if sp.FooterReference == nil {
+		sp.FooterReference = &[]FooterReference{}
+	}
+	*sp.FooterReference = append(*sp.FooterReference, FooterReference{Type: kind, Id: ft.id})
+	if kind == HDRFTR_FIRST {
+		sp.TitlePg = &TitlePg{}
+	}
+	f.footers = append(f.footers, ft)
+	return ft
+}
+
+// Header returns the header of kind referred by the last section, or nil
+func (f *Docx) Header(kind string) *Header {
+	sp := f.Document.Body.lastSectPr()
+	if sp == nil || sp.HeaderReference == nil {
+		return nil
+	}
+	for _, ref := range *sp.HeaderReference {
+		if ref.Type != kind {
+			continue
+		}
+		for _, h := range f.headers {
+			if h.id == ref.Id {
+				return h
+			}
+		}
+	}
+	return nil
+}
+
+// Footer returns the footer of kind referred by the last section, or nil
+func (f *Docx) Footer(kind string) *Footer {
+	sp := f.Document.Body.lastSectPr()
+	if sp == nil || sp.FooterReference == nil {
+		return nil
+	}
+	for _, ref := range *sp.FooterReference {
+		if ref.Type != kind {
+			continue
+		}
+		for _, ft := range f.footers {
+			if ft.id == ref.Id {
+				return ft
+			}
+		}
+	}
+	return nil
+}
+
+// Headers returns all headers in the file
+func (f *Docx) Headers() []*Header {
+	return f.headers
+}
+
+// Footers returns all footers in the file
+func (f *Docx) Footers() []*Footer {
+	return f.footers
+}
+
+// AddParagraph adds a new paragraph to the header or footer
+func (hf *HeaderFooter) AddParagraph() *Paragraph {
+	p := &Paragraph{
+		Children: make([]interface{}, 0, 64),
+		file:     hf.file,
+	}
+	hf.Items = append(hf.Items, p)
+	return p
+}
+
+// AddTable add a new table to the header or footer by col*row
+func (hf *HeaderFooter) AddTable(row int, col int) *Table {
+	tbl := hf.file.newTable(row, col)
+	hf.Items = append(hf.Items, tbl)
+	return tbl
+}
+
+// String returns the plain text of the header or footer
+func (hf *HeaderFooter) String() string {
+	return itemsString(hf.Items)
+}
+
+// itemsString joins the plain text of paragraphs and tables by line
+func itemsString(items []interface{}) string {
+	sb := strings.Builder{}
+	for _, it := range items {
+		switch o := it.(type) {
+		case *Paragraph:
+			if sb.Len() > 0 {
+				sb.WriteByte('\n')
+			}
+			sb.WriteString(o.String())
+		case *Table:
+			if sb.Len() > 0 {
+				sb.WriteByte('\n')
+			}
+			sb.WriteString(o.String())
+		}
+	}
+	return sb.String()
+}
+
+// newPartName finds an unused word/{prefix}N.xml
+func (f *Docx) newPartName(prefix string) string {
+	for n := 1; ; n++ {
+		name := "word/" + prefix + strconv.Itoa(n) + ".xml"
+		if !f.hasPart(name) {
+			return name
+		}
+	}
+}
+
+// hasPart checks whether the zip path is used in file
+func (f *Docx) hasPart(name string) bool {
+	for _, h := range f.headers {
+		if h.name == name {
+			return true
+		}
+	}
+	for _, ft := range f.footers {
+		if ft.name == name {
+			return true
+		}
+	}
+	for _, n := range []*Notes{f.footnotes, f.endnotes} {
+		if n != nil && n.name == name {
+			return true
+		}
+	}
+	if f.comments != nil && (f.comments.name == name || f.comments.extName == name) {
+		return true
+	}
+	for _, n := range f.tmpfslst {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// addPartRelation refers a part under word/ from document.xml.rels
+//
+//	this func is not thread-safe
+func (f *Docx) addPartRelation(typ, name string) string {
+	rel := Relationship{
+		ID:     f.newRelationID(),
+		Type:   typ,
+		Target: name[len("word/"):],
+	}
+
+	f.docRelation.Relationship = append(f.docRelation.Relationship, rel)
+
+	return rel.ID
+}
@@ -0,0 +1,713 @@
+/*
+   Copyright (c) 2020 gingfrederik
+   Copyright (c) 2021 Gonzalo Fernandez-Victorio
+   Copyright (c) 2021 Basement Crowd Ltd (https://www.basementcrowd.com)
+   Copyright (c) 2023 Fumiama Minamoto (源文雨)
+
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU Affero General Public License as published
+   by the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU Affero General Public License for more details.
+
+   You should have received a copy of the GNU Affero General Public License
+   along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package docx
+
+import (
+	"encoding/base64"
+	"html"
+	"io"
+	"mime"
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// HTMLOptions controls how WriteHTML writes a document
+type HTMLOptions struct {
+	// Fragment writes only a <div> of the body, without <html>, <head> and <body>
+	Fragment bool
+	// ImageDir is the directory images are saved into and linked from.
+	// Images are embedded as data URIs if both ImageDir and SaveImage are empty.
+	ImageDir string
+	// SaveImage stores an image named like image1.png and returns
+	// the link to it. It takes precedence over ImageDir.
+	SaveImage func(name string, data []byte) (link string, err error)
+}
+
+var (
+	// hexColorRegex matches the colors like FF0000 that are written into CSS,
+	// the others like auto or themed ones are left out
+	hexColorRegex = regexp.MustCompile(`^[0-9A-Fa-f]{6}$`)
+	// cssFontReplacer strips what may end a quoted font name in CSS
+	cssFontReplacer = strings.NewReplacer("'", "", "\"", "", ";", "", "\\", "", "\n", "", "\r", "")
+)
+
+// highlightColors maps w:highlight values to CSS colors
+var highlightColors = map[string]string{
+	"black": "#000000", "blue": "#0000ff", "cyan": "#00ffff", "green": "#00ff00",
+	"magenta": "#ff00ff", "red": "#ff0000", "yellow": "#ffff00", "white": "#ffffff",
+	"darkBlue": "#000080", "darkCyan": "#008080", "darkGreen": "#008000",
+	"darkMagenta": "#800080", "darkRed": "#800000", "darkYellow": "#808000",
+	"darkGray": "#808080", "lightGray": "#c0c0c0",
+}
+
+// WriteHTML writes the body of f as HTML for previews. The formatting
+// resolved from styles and direct properties is written as inline CSS,
+// so that no stylesheet is needed.
+//
+// Heading styles become <h1> to <h6>, numbered paragraphs get their list
+// numbers, tables keep their merged cells and borders, and footnotes and
+// endnotes are appended after the body.
+func (f *Docx) WriteHTML(w io.Writer, opts HTMLOptions) error {
+	h := &htmlWriter{
+		f:         f,
+		opts:      opts,
+		numbering: newNumberingState(&f.Numbering),
+		images:    make(map[string]string),
+	}
+	if !opts.Fragment {
+		h.sb.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n</head>\n<body>\n")
+	}
+	h.sb.WriteString("<div class=\"docx\">\n")
+	h.items(f.Document.Body.Items)
+	if len(h.notes.refs) > 0 {
+		h.sb.WriteString("<hr>\n")
+	}
+	for i := 0; i < len(h.notes.refs) && h.err == nil; i++ {
+		h.note(h.notes.refs[i])
+	}
+	h.sb.WriteString("</div>\n")
+	if !opts.Fragment {
+		h.sb.WriteString("</body>\n</html>\n")
+	}
+	if h.err != nil {
+		return h.err
+	}
+	_, err := io.WriteString(w, h.sb.String())
+	return err
+}
+
+// htmlWriter holds the state of one WriteHTML call
+type htmlWriter struct {
+	f         *Docx
+	opts      HTMLOptions
+	sb        strings.Builder
+	numbering *numberingState
+	// images maps rId to the src written
+	images map[string]string
+	notes  noteRefs
+	// note is the ref of the note being written
+	noteRef string
+	err     error
+}
+
+func (h *htmlWriter) items(items []interface{}) {
+	for _, it := range items {
+		switch o := it.(type) {
+		case *Paragraph:
+			h.paragraph(o, nil)
+		case *Table:
+			h.table(o)
+		case *StructuredDocumentTag:
+			if o.SdtContent == nil {
+				continue
+			}
+			if o.SdtContent.Paragraphs != nil {
+				for _, p := range *o.SdtContent.Paragraphs {
+					h.paragraph(p, nil)
+				}
+			}
+			if o.SdtContent.Tables != nil {
+				for _, t := range *o.SdtContent.Tables {
+					h.table(t)
+				}
+			}
+		}
+	}
+}
+
+func (h *htmlWriter) paragraph(p *Paragraph, cell *CellLocation) {
+	pp := h.f.EffectiveParagraphProperties(p, cell)
+	tag := "p"
+	if level := h.f.headingLevel(p); level > 0 && level <= 6 {
+		tag = "h" + strconv.Itoa(level)
+	}
+	h.sb.WriteString("<" + tag)
+	h.style(paragraphCSS(pp))
+	h.sb.WriteByte('>')
+	start := h.sb.Len()
+	if numPr := pp.NumPr; numPr != nil && numPr.NumID != nil && numPr.NumID.Val != 0 {
+		ilvl := 0
+		if numPr.Ilvl != nil {
+			ilvl = numPr.Ilvl.Val
+		}
+		if s := h.numbering.numberedString(numPr.NumID.Val, ilvl); s != "" && s != "＠" {
+			h.sb.WriteString("<span>" + html.EscapeString(s) + " </span>")
+		}
+	}
+	var boxes []*Paragraph
+	h.children(p, p.Children, cell, &boxes)
+	if h.sb.Len() == start {
+		h.sb.WriteString("<br>")
+	}
+	h.sb.WriteString("</" + tag + ">\n")
+	if len(boxes) > 0 {
+		h.sb.WriteString("<div class=\"textbox\">\n")
+		for _, b := range boxes {
+			h.paragraph(b, nil)
+		}
+		h.sb.WriteString("</div>\n")
+	}
+}
+
+func (h *htmlWriter) children(p *Paragraph, items []interface{}, cell *CellLocation, boxes *[]*Paragraph) {
+	for _, c := range items {
+		switch o := c.(type) {
+		case *Run:
+			h.run(p, o, cell, boxes)
+		case *Hyperlink:
+			href := ""
+			if o.ID != "" {
+				href, _ = h.f.ReferTarget(o.ID)
+			} else if o.Anchor != "" {
+				href = "#" + o.Anchor
+			}
+			if safeHref(href) {
+				h.sb.WriteString("<a href=\"" + html.EscapeString(href) + "\">")
+			} else {
+				h.sb.WriteString("<a>")
+			}
+			if o.Runs != nil {
+				for _, r := range *o.Runs {
+					if len(r.Children) == 0 && r.InstrText != "" {
+						// AddLink keeps the text in InstrText
+						text := *r
+						text.Children = []interface{}{&Text{Text: r.InstrText}}
+						r = &text
+					}
+					h.run(p, r, cell, boxes)
+				}
+			}
+			h.sb.WriteString("</a>")
+		case *Insertion:
+			h.children(p, o.Children, cell, boxes)
+		case *MoveTo:
+			h.children(p, o.Children, cell, boxes)
+		}
+	}
+}
+
+func (h *htmlWriter) run(p *Paragraph, r *Run, cell *CellLocation, boxes *[]*Paragraph) {
+	var sb strings.Builder
+	for _, c := range r.Children {
+		switch x := c.(type) {
+		case *Text:
+			sb.WriteString(html.EscapeString(x.Text))
+		case *Tab:
+			sb.WriteString("&emsp;&emsp;")
+		case *BarterRabbet:
+			sb.WriteString("<br>")
+		case *FootnoteReference:
+			ref := strconv.Itoa(x.ID)
+			h.notes.add(ref)
+			sb.WriteString("<sup><a href=\"#fn-" + ref + "\" id=\"fnref-" + ref + "\">" + ref + "</a></sup>")
+		case *EndnoteReference:
+			ref := strconv.Itoa(x.ID)
+			h.notes.add("e" + ref)
+			sb.WriteString("<sup><a href=\"#en-" + ref + "\" id=\"enref-" + ref + "\">" + ref + "</a></sup>")
+		case *FootnoteRef, *EndnoteRef:
+			sb.WriteString("<sup>" + strings.TrimPrefix(h.noteRef, "e") + "</sup>")
+		case *Drawing:
+			*boxes = append(*boxes, x.textBoxes()...)
+			sb.WriteString(h.drawing(x))
+		}
+	}
+	if sb.Len() == 0 {
+		return
+	}
+	css := runCSS(h.f.EffectiveRunProperties(p, r, cell))
+	if len(css) == 0 {
+		h.sb.WriteString(sb.String())
+		return
+	}
+	h.sb.WriteString("<span")
+	h.style(css)
+	h.sb.WriteString(">" + sb.String() + "</span>")
+}
+
+// drawing returns the <img> of the picture in d, or "" if it has none
+func (h *htmlWriter) drawing(d *Drawing) string {
+	gd := d.graphicData()
+	if gd == nil || gd.Pic == nil || gd.Pic.BlipFill == nil {
+		return ""
+	}
+	src := h.image(gd.Pic.BlipFill.Blip.Embed)
+	if src == "" {
+		return ""
+	}
+	var docPr *WPDocPr
+	var extent *WPExtent
+	if d.Inline != nil {
+		docPr, extent = d.Inline.DocPr, d.Inline.Extent
+	} else if d.Anchor != nil {
+		docPr, extent = d.Anchor.DocPr, d.Anchor.Extent
+	}
+	img := "<img src=\"" + html.EscapeString(src) + "\""
+	if docPr != nil {
+		img += " alt=\"" + html.EscapeString(docPr.Name) + "\""
+	}
+	if extent != nil && extent.CX > 0 && extent.CY > 0 {
+		// 9525 EMU per CSS pixel
+		img += " width=\"" + strconv.FormatInt(extent.CX/9525, 10) +
+			"\" height=\"" + strconv.FormatInt(extent.CY/9525, 10) + "\""
+	}
+	return img + ">"
+}
+
+// image returns the src of the image of rId id, a data URI
+// unless the options save images into files
+func (h *htmlWriter) image(id string) string {
+	if src, ok := h.images[id]; ok {
+		return src
+	}
+	target, err := h.f.ReferTarget(id)
+	if err != nil {
+		return ""
+	}
+	media := h.f.Media(path.Base(target))
+	if media == nil {
+		return ""
+	}
+	var src string
+	if h.opts.SaveImage != nil || h.opts.ImageDir != "" {
+		src, err = saveMedia(media, h.opts.ImageDir, h.opts.SaveImage)
+		if err != nil {
+			if h.err == nil {
+				h.err = err
+			}
+			return ""
+		}
+	} else {
+		ext := path.Ext(media.Name)
+		typ := mime.TypeByExtension(ext)
+		if typ == "" {
+			typ = "image/" + strings.TrimPrefix(ext, ".")
+		}
+		src = "data:" + typ + ";base64," + base64.StdEncoding.EncodeToString(media.Data)
+	}
+	h.images[id] = src
+	return src
+}
+
+func (h *htmlWriter) table(t *Table) {
+	css := []string{"border-collapse:collapse"}
+	borders := h.tableBorders(t)
+	if tp := t.TableProperties; tp != nil {
+		if tp.Width != nil && tp.Width.W > 0 {
+			switch tp.Width.Type {
+			case "dxa":
+				css = append(css, "width:"+twipsToPt(tp.Width.W))
+			case "pct":
+				css = append(css, "width:"+strconv.FormatFloat(float64(tp.Width.W)/50, 'f', -1, 64)+"%")
+			}
+		}
+		if tp.Justification != nil {
+			switch tp.Justification.Val {
+			case "center":
+				css = append(css, "margin-left:auto", "margin-right:auto")
+			case "end", "right":
+				css = append(css, "margin-left:auto")
+			}
+		}
+	}
+	cols := t.columns()
+	h.sb.WriteString("<table")
+	h.style(css)
+	h.sb.WriteString(">\n")
+	head := 0
+	for head < len(t.TableRows) && isHeaderRow(t.TableRows[head]) {
+		head++
+	}
+	for ri, row := range t.TableRows {
+		switch {
+		case head == 0:
+		case ri == 0:
+			h.sb.WriteString("<thead>\n")
+		case ri == head:
+			h.sb.WriteString("</thead>\n<tbody>\n")
+		}
+		h.sb.WriteString("<tr")
+		if rp := row.TableRowProperties; rp != nil && rp.TableRowHeight != nil && rp.TableRowHeight.Val > 0 {
+			h.style([]string{"height:" + twipsToPt(rp.TableRowHeight.Val)})
+		}
+		h.sb.WriteString(">")
+		col := 0
+		for ci, c := range row.TableCells {
+			span := gridSpanOf(c)
+			merge := vMergeOf(c)
+			if merge == "continue" {
+				col += span
+				continue
+			}
+			rowspan := 1
+			if merge == "restart" {
+				for r := ri + 1; r < len(t.TableRows); r++ {
+					below := t.gridCell(r, col)
+					if below == nil || vMergeOf(below) != "continue" {
+						break
+					}
+					rowspan++
+				}
+			}
+			h.sb.WriteString("<td")
+			if span > 1 {
+				h.sb.WriteString(" colspan=\"" + strconv.Itoa(span) + "\"")
+			}
+			if rowspan > 1 {
+				h.sb.WriteString(" rowspan=\"" + strconv.Itoa(rowspan) + "\"")
+			}
+			h.style(cellCSS(c, borders, ri == 0, ri+rowspan == len(t.TableRows), col == 0, col+span >= cols))
+			h.sb.WriteString(">")
+			for _, it := range c.Items {
+				if p, ok := it.(*Paragraph); ok {
+					h.paragraph(p, &CellLocation{Table: t, Row: ri, Col: ci})
+					continue
+				}
+				h.items([]interface{}{it})
+			}
+			h.sb.WriteString("</td>")
+			col += span
+		}
+		h.sb.WriteString("</tr>\n")
+	}
+	switch {
+	case head == 0:
+	case head == len(t.TableRows):
+		h.sb.WriteString("</thead>\n")
+	default:
+		h.sb.WriteString("</tbody>\n")
+	}
+	h.sb.WriteString("</table>\n")
+}
+
+// isHeaderRow tells whether row repeats on each page
+func isHeaderRow(row *WTableRow) bool {
+	rp := row.TableRowProperties
+	return rp != nil && rp.TableHeader != nil && isOn(rp.TableHeader.Val)
+}
+
+// tableBorders returns the borders of t, or of its table style
+func (h *htmlWriter) tableBorders(t *Table) *WTableBorders {
+	tp := t.TableProperties
+	if tp == nil {
+		return nil
+	}
+	if tp.TableBorders != nil {
+		return tp.TableBorders
+	}
+	if tp.Style == nil {
+		return nil
+	}
+	chain := h.f.resolvingStyles().chain(STYLE_TABLE, tp.Style.Val)
+	for i := len(chain) - 1; i >= 0; i-- {
+		if chain[i].TableProperties != nil && chain[i].TableProperties.TableBorders != nil {
+			return chain[i].TableProperties.TableBorders
+		}
+	}
+	return nil
+}
+
+func (h *htmlWriter) note(ref string) {
+	n := h.f.noteOf(ref)
+	if n == nil {
+		return
+	}
+	id := "fn-" + ref
+	if strings.HasPrefix(ref, "e") {
+		id = "en-" + ref[1:]
+	}
+	h.noteRef = ref
+	h.sb.WriteString("<div id=\"" + id + "\">\n")
+	for _, it := range n.Items {
+		switch o := it.(type) {
+		case *Paragraph:
+			h.paragraph(o, nil)
+		case *Table:
+			h.table(o)
+		}
+	}
+	h.sb.WriteString("</div>\n")
+	h.noteRef = ""
+}
+
+// style writes the style attribute of css
+func (h *htmlWriter) style(css []string) {
+	if len(css) > 0 {
+		h.sb.WriteString(" style=\"" + html.EscapeString(strings.Join(css, ";")) + "\"")
+	}
+}
+
+// paragraphCSS converts Justification, Spacing, Ind and Shade to CSS
+func paragraphCSS(pp *ParagraphProperties) []string {
+	var top, bottom, left int
+	css := make([]string, 0, 6)
+	if sp := pp.Spacing; sp != nil {
+		top, bottom = sp.Before, sp.After
+		switch {
+		case sp.Line <= 0:
+		case sp.LineRule == "exact" || sp.LineRule == "atLeast":
+			css = append(css, "line-height:"+twipsToPt(int64(sp.Line)))
+		default:
+			// auto is in 240ths of a line
+			css = append(css, "line-height:"+strconv.FormatFloat(float64(sp.Line)/240, 'f', -1, 64))
+		}
+	}
+	if ind := pp.Ind; ind != nil {
+		left = ind.Left
+		switch {
+		case ind.Hanging > 0:
+			css = append(css, "text-indent:-"+twipsToPt(int64(ind.Hanging)))
+		case ind.FirstLine > 0:
+			css = append(css, "text-indent:"+twipsToPt(int64(ind.FirstLine)))
+		}
+	}
+	css = append(css, "margin:"+twipsToPt(int64(top))+" 0 "+twipsToPt(int64(bottom))+" "+twipsToPt(int64(left)))
+	if pp.Justification != nil {
+		switch pp.Justification.Val {
+		case "center":
+			css = append(css, "text-align:center")
+		case "end", "right":
+			css = append(css, "text-align:right")
+		case "both", "distribute":
+			css = append(css, "text-align:justify")
+		}
+	}
+	if c := shadeColor(pp.Shade); c != "" {
+		css = append(css, "background-color:"+c)
+	}
+	return css
+}
+
+// runCSS converts the formatting of rp to CSS
+func runCSS(rp *RunProperties) []string {
+	css := make([]string, 0, 8)
+	if rp.Fonts != nil {
+		font := rp.Fonts.ASCII
+		if font == "" {
+			font = rp.Fonts.HAnsi
+		}
+		if font == "" {
+			font = rp.Fonts.EastAsia
+		}
+		if font != "" {
+			css = append(css, "font-family:'"+cssFontReplacer.Replace(font)+"'")
+		}
+	}
+	if rp.Size != nil {
+		if sz, err := strconv.ParseFloat(rp.Size.Val, 64); err == nil && sz > 0 {
+			css = append(css, "font-size:"+strconv.FormatFloat(sz/2, 'f', -1, 64)+"pt")
+		}
+	}
+	if rp.Bold != nil && isOn(rp.Bold.Val) {
+		css = append(css, "font-weight:bold")
+	}
+	if rp.Italic != nil && isOn(rp.Italic.Val) {
+		css = append(css, "font-style:italic")
+	}
+	if rp.Caps != nil && isOn(rp.Caps.Val) {
+		css = append(css, "text-transform:uppercase")
+	}
+	if c := hexColor(rp.Color); c != "" {
+		css = append(css, "color:"+c)
+	}
+	if rp.Highlight != nil && highlightColors[rp.Highlight.Val] != "" {
+		css = append(css, "background-color:"+highlightColors[rp.Highlight.Val])
+	} else if c := shadeColor(rp.Shade); c != "" {
+		css = append(css, "background-color:"+c)
+	}
+	var decorations []string
+	underline := ""
+	if rp.Underline != nil && rp.Underline.Val != "none" {
+		decorations = append(decorations, "underline")
+		switch {
+		case rp.Underline.Val == "double":
+			underline = "double"
+		case strings.HasPrefix(rp.Underline.Val, "dotted"):
+			underline = "dotted"
+		case strings.Contains(strings.ToLower(rp.Underline.Val), "dash"):
+			underline = "dashed"
+		case strings.HasPrefix(rp.Underline.Val, "wav"):
+			underline = "wavy"
+		}
+	}
+	if rp.Strike != nil && isOn(rp.Strike.Val) {
+		decorations = append(decorations, "line-through")
+	}
+	if len(decorations) > 0 {
+		css = append(css, "text-decoration:"+strings.Join(decorations, " "))
+		if underline != "" {
+			css = append(css, "text-decoration-style:"+underline)
+		}
+	}
+	if rp.VertAlign != nil {
+		switch rp.VertAlign.Val {
+		case "superscript":
+			css = append(css, "vertical-align:super")
+		case "subscript":
+			css = append(css, "vertical-align:sub")
+		}
+	}
+	return css
+}
+
+// cellCSS converts the borders, shading and alignment of c to CSS. The
+// table borders apply on the outer edges of the table, and the inside ones
+// between cells, unless the cell has its own.
+func cellCSS(c *WTableCell, tb *WTableBorders, top, bottom, left, right bool) []string {
+	var own WTableBorders
+	var cp WTableCellProperties
+	if c.TableCellProperties != nil {
+		cp = *c.TableCellProperties
+		if cp.TableBorders != nil {
+			own = *cp.TableBorders
+		}
+	}
+	if tb == nil {
+		tb = &WTableBorders{}
+	}
+	pick := func(b, outer, inner *WTableBorder, edge bool) *WTableBorder {
+		switch {
+		case b != nil:
+			return b
+		case edge:
+			return outer
+		default:
+			return inner
+		}
+	}
+	css := make([]string, 0, 8)
+	for _, side := range []struct {
+		name string
+		b    *WTableBorder
+	}{
+		{"top", pick(own.Top, tb.Top, tb.InsideH, top)},
+		{"right", pick(own.Right, tb.Right, tb.InsideV, right)},
+		{"bottom", pick(own.Bottom, tb.Bottom, tb.InsideH, bottom)},
+		{"left", pick(own.Left, tb.Left, tb.InsideV, left)},
+	} {
+		if b := borderCSS(side.b); b != "" {
+			css = append(css, "border-"+side.name+":"+b)
+		}
+	}
+	if c := shadeColor(cp.Shade); c != "" {
+		css = append(css, "background-color:"+c)
+	}
+	if cp.VAlign != nil {
+		switch cp.VAlign.Val {
+		case "center":
+			css = append(css, "vertical-align:middle")
+		case "bottom":
+			css = append(css, "vertical-align:bottom")
+		default:
+			css = append(css, "vertical-align:top")
+		}
+	}
+	if m := cp.Margins; m != nil {
+		for _, side := range []struct {
+			name string
+			m    *WTableCellMargin
+		}{{"top", m.Top}, {"right", m.Right}, {"bottom", m.Bottom}, {"left", m.Left}} {
+			if side.m != nil && side.m.Type == "dxa" {
+				css = append(css, "padding-"+side.name+":"+twipsToPt(side.m.W))
+			}
+		}
+	}
+	if cp.NoWrap != nil && isOn(cp.NoWrap.Val) {
+		css = append(css, "white-space:nowrap")
+	}
+	if cp.TextDirection != nil {
+		switch cp.TextDirection.Val {
+		case TEXTDIR_TB_RL, "tbRlV":
+			css = append(css, "writing-mode:vertical-rl")
+		case TEXTDIR_BT_LR:
+			css = append(css, "writing-mode:vertical-rl", "transform:rotate(180deg)")
+		}
+	}
+	if w := cp.TableCellWidth; w != nil && w.W > 0 && w.Type == "dxa" {
+		css = append(css, "width:"+twipsToPt(w.W))
+	}
+	return css
+}
+
+// borderCSS converts b to the value of a CSS border, or "" if b is nil
+func borderCSS(b *WTableBorder) string {
+	if b == nil {
+		return ""
+	}
+	style := "solid"
+	switch b.Val {
+	case "nil", "none", "":
+		return "none"
+	case "double":
+		style = "double"
+	case "dotted":
+		style = "dotted"
+	case "dashed", "dashSmallGap", "dotDash", "dotDotDash":
+		style = "dashed"
+	}
+	width := 0.5
+	if b.Size > 4 {
+		// sz is in eighths of a point
+		width = float64(b.Size) / 8
+	}
+	color := "#000000"
+	if hexColorRegex.MatchString(b.Color) {
+		color = "#" + b.Color
+	}
+	return strconv.FormatFloat(width, 'f', -1, 64) + "pt " + style + " " + color
+}
+
+func hexColor(c *Color) string {
+	if c == nil || !hexColorRegex.MatchString(c.Val) {
+		return ""
+	}
+	return "#" + c.Val
+}
+
+func shadeColor(s *Shade) string {
+	if s == nil || !hexColorRegex.MatchString(s.Fill) {
+		return ""
+	}
+	return "#" + s.Fill
+}
+
+// safeHref tells whether href is an anchor or a http, https or mailto URL,
+// the others like javascript: are not written into the HTML
+func safeHref(href string) bool {
+	if strings.HasPrefix(href, "#") {
+		return true
+	}
+	u, err := url.Parse(href)
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https", "mailto":
+		return true
+	}
+	return false
+}
+
+func twipsToPt(v int64) string {
+	return strconv.FormatFloat(float64(v)/20, 'f', -1, 64) + "pt"
+}
@@ -0,0 +1,126 @@
+/*
+   Copyright (c) 2020 gingfrederik
+   Copyright (c) 2021 Gonzalo Fernandez-Victorio
+   Copyright (c) 2021 Basement Crowd Ltd (https://www.basementcrowd.com)
+   Copyright (c) 2023 Fumiama Minamoto (源文雨)
+
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU Affero General Public License as published
+   by the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU Affero General Public License for more details.
+
+   You should have received a copy of the GNU Affero General Public License
+   along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package docx
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestWriteHTML(t *testing.T) {
+	doc := NewA4()
+	doc.AddParagraphStyle("Heading1", "heading 1")
+	doc.AddParagraph().Style("Heading1").AddText("Title <1>")
+
+	p := doc.AddParagraph().Justification("center")
+	p.Properties.Spacing = &Spacing{Before: 240, After: 120, Line: 360, LineRule: "auto"}
+	p.Properties.Ind = &Ind{Left: 720, FirstLine: 420}
+	p.AddText("red").Color("ff0000").Size("28").Bold()
+	p.AddText("marked").Highlight("yellow").Underline("double")
+	p.AddText("shaded").Shade("clear", "auto", "00ff00")
+	p.AddLink("site", "https://example.com/?a=1&b=2")
+	p.AddFootnote("note")
+
+	logo, err := os.ReadFile("testdata/fumiamayoko.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = doc.AddParagraph().AddInlineDrawing(logo)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tbl := doc.AddTable(3, 3)
+	tbl.TableRows[0].TableCells[0].AddParagraph().AddText("wide")
+	tbl.TableRows[0].TableCells[0].TableCellProperties.GridSpan = &WGridSpan{Val: 2}
+	tbl.TableRows[0].TableCells = append(tbl.TableRows[0].TableCells[:1], tbl.TableRows[0].TableCells[2])
+	tbl.TableRows[1].TableCells[2].AddParagraph().AddText("tall")
+	tbl.TableRows[1].TableCells[2].TableCellProperties.VMerge = &WvMerge{Val: "restart"}
+	tbl.TableRows[2].TableCells[2].TableCellProperties.VMerge = &WvMerge{}
+	tbl.TableRows[2].TableCells[0].TableCellProperties.TableBorders = &WTableBorders{
+		Left: &WTableBorder{Val: "dashed", Size: 16, Color: "0000ff"},
+	}
+
+	var sb strings.Builder
+	err = doc.WriteHTML(&sb, HTMLOptions{Fragment: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := sb.String()
+	for _, want := range []string{
+		`<h1 style="margin:0pt 0 0pt 0pt;text-align:justify"><span style="font-size:10.5pt">Title &lt;1&gt;</span></h1>`,
+		`<p style="line-height:1.5;text-indent:21pt;margin:12pt 0 6pt 36pt;text-align:center">`,
+		`<span style="font-size:14pt;font-weight:bold;color:#ff0000">red</span>`,
+		`background-color:#ffff00;text-decoration:underline;text-decoration-style:double">marked</span>`,
+		`background-color:#00ff00">shaded</span>`,
+		`<a href="https://example.com/?a=1&amp;b=2">`,
+		`<sup><a href="#fn-1" id="fnref-1">1</a></sup>`,
+		`<div id="fn-1">`,
+		`<img src="data:image/png;base64,iVBORw0KGgo`,
+		`<td colspan="2" style="border-top:0.5pt solid #000000;`,
+		`<td rowspan="2" style=`,
+		`border-left:2pt dashed #0000ff`,
+	} {
+		if !strings.Contains(s, want) {
+			t.Fatal("not found:", want)
+		}
+	}
+	if strings.Count(s, "<td") != 7 || strings.HasPrefix(s, "<!DOCTYPE") {
+		t.Fatal("unexpected cells")
+	}
+
+	saved := 0
+	sb.Reset()
+	err = doc.WriteHTML(&sb, HTMLOptions{SaveImage: func(name string, data []byte) (string, error) {
+		saved++
+		return "img/" + name, nil
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved != 1 || !strings.Contains(sb.String(), `<img src="img/image1.png"`) || !strings.HasPrefix(sb.String(), "<!DOCTYPE html>") {
+		t.Fatal("image not saved")
+	}
+}
+
+func TestWriteHTMLUnsafe(t *testing.T) {
+	doc := NewA4()
+	p := doc.AddParagraph()
+	p.AddLink("evil", "javascript:alert(1)")
+	p.AddLink("Evil", " JavaScript:alert(1)")
+	p.AddLink("mail", "mailto:a@example.com")
+	p.AddText("colored").Color("000;background:url(http://bad/x)").Shade("clear", "auto", "fff;zz:1").
+		Font("A';}body{x:y", "", "")
+	tbl := doc.AddTable(1, 1)
+	tbl.TableProperties.TableBorders.Top.Color = "000;zz:1"
+	var sb strings.Builder
+	if err := doc.WriteHTML(&sb, HTMLOptions{Fragment: true}); err != nil {
+		t.Fatal(err)
+	}
+	s := sb.String()
+	if strings.Contains(strings.ToLower(s), "javascript") || strings.Count(s, "<a>") != 2 || !strings.Contains(s, `<a href="mailto:a@example.com">`) {
+		t.Fatal("unsafe link written\n" + s)
+	}
+	if strings.Contains(s, "url(") || strings.Contains(s, "zz:1") || !strings.Contains(s, "font-family:&#39;A}body{x:y&#39;;") {
+		t.Fatal("unsafe css written\n" + s)
+	}
+}
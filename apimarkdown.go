@@ -0,0 +1,574 @@
+/*
+   Copyright (c) 2020 gingfrederik
+   Copyright (c) 2021 Gonzalo Fernandez-Victorio
+   Copyright (c) 2021 Basement Crowd Ltd (https://www.basementcrowd.com)
+   Copyright (c) 2023 Fumiama Minamoto (源文雨)
+
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU Affero General Public License as published
+   by the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU Affero General Public License for more details.
+
+   You should have received a copy of the GNU Affero General Public License
+   along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package docx
+
+import (
+	"io"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// MarkdownOptions controls how MarshalMarkdown writes a document
+// and how FromMarkdown reads one
+type MarkdownOptions struct {
+	// ImageDir is the directory images are saved into and linked from
+	// by MarshalMarkdown, and the one relative image paths are read from
+	// by FromMarkdown. MarshalMarkdown links images to their path in the
+	// package like media/image1.png if both ImageDir and SaveImage are empty.
+	// FromMarkdown reads no local files if both ImageDir and LoadImage are
+	// empty, and fails with ErrImageOutOfDir on paths out of ImageDir.
+	ImageDir string
+	// SaveImage stores an image named like image1.png and returns
+	// the link to it. It takes precedence over ImageDir.
+	SaveImage func(name string, data []byte) (link string, err error)
+	// LoadImage reads the image linked as ![alt](link) for FromMarkdown.
+	// It takes precedence over ImageDir.
+	LoadImage func(link string) ([]byte, error)
+}
+
+var (
+	headingStyleRegex = regexp.MustCompile(`(?i)^heading\s*([1-9])$`)
+	markdownEscaper   = strings.NewReplacer(
+		`\`, `\\`, "`", "\\`", `*`, `\*`, `_`, `\_`,
+		`[`, `\[`, `]`, `\]`, `<`, `\<`, `|`, `\|`, `~`, `\~`,
+	)
+	markdownOrderedStart = regexp.MustCompile(`^(\d+)([.)])`)
+)
+
+// MarshalMarkdown writes the body of f as GitHub flavored Markdown.
+//
+// Heading styles become #, numbered paragraphs list items nested by
+// their level, bold, italic and strike runs emphasis, and tables pipe
+// tables with the paragraphs of a cell joined by <br>. Footnotes and
+// endnotes are appended as [^n] and [^en] definitions.
+func (f *Docx) MarshalMarkdown(w io.Writer, opts MarkdownOptions) error {
+	m := &markdownWriter{
+		f:      f,
+		opts:   opts,
+		levels: f.listLevels(),
+		counts: make(map[int]*[9]int),
+		images: make(map[string]string),
+	}
+	m.items(f.Document.Body.Items)
+	for i := 0; i < len(m.notes.refs) && m.err == nil; i++ {
+		m.note(m.notes.refs[i])
+	}
+	if m.err != nil {
+		return m.err
+	}
+	if m.sb.Len() > 0 {
+		m.sb.WriteByte('\n')
+	}
+	_, err := io.WriteString(w, m.sb.String())
+	return err
+}
+
+// markdownWriter holds the state of one MarshalMarkdown call
+type markdownWriter struct {
+	f    *Docx
+	opts MarkdownOptions
+	sb   strings.Builder
+	// list tells whether the last block is a list item
+	list bool
+	// levels maps numId and ilvl to the format of the level
+	levels map[int]map[int]listLevel
+	// counts are the items written of each numId by ilvl
+	counts map[int]*[9]int
+	// images maps rId to the written link
+	images map[string]string
+	notes  noteRefs
+	err    error
+}
+
+// markdownSpan is a piece of inline text
+type markdownSpan struct {
+	text string
+	// raw spans are written as is, without emphasis
+	raw                  bool
+	bold, italic, strike bool
+}
+
+// markdownInline collects the spans of one paragraph
+type markdownInline struct {
+	*markdownWriter
+	p *Paragraph
+	// plain drops run formatting, as in headings
+	plain bool
+	// cell writes line breaks as <br>
+	cell  bool
+	spans []markdownSpan
+	// boxes are the paragraphs in text boxes of drawings
+	boxes []*Paragraph
+}
+
+// block writes s separated from the previous block by a blank line,
+// or by a single newline between list items
+func (m *markdownWriter) block(s string, list bool) {
+	if s == "" {
+		return
+	}
+	if m.sb.Len() > 0 {
+		if list && m.list {
+			m.sb.WriteByte('\n')
+		} else {
+			m.sb.WriteString("\n\n")
+		}
+	}
+	m.sb.WriteString(s)
+	m.list = list
+}
+
+func (m *markdownWriter) items(items []interface{}) {
+	for _, it := range items {
+		switch o := it.(type) {
+		case *Paragraph:
+			m.paragraph(o)
+		case *Table:
+			m.table(o)
+		case *StructuredDocumentTag:
+			if o.SdtContent == nil {
+				continue
+			}
+			if o.SdtContent.Paragraphs != nil {
+				for _, p := range *o.SdtContent.Paragraphs {
+					m.paragraph(p)
+				}
+			}
+			if o.SdtContent.Tables != nil {
+				for _, t := range *o.SdtContent.Tables {
+					m.table(t)
+				}
+			}
+		}
+	}
+}
+
+func (m *markdownWriter) paragraph(p *Paragraph) {
+	level := m.f.headingLevel(p)
+	in := markdownInline{markdownWriter: m, p: p, plain: level > 0}
+	in.children(p.Children)
+	text := strings.TrimSpace(in.String())
+	switch {
+	case text == "":
+	case level > 0:
+		m.block(strings.Repeat("#", level)+" "+text, false)
+	default:
+		numPr := m.f.EffectiveParagraphProperties(p, nil).NumPr
+		if numPr == nil || numPr.NumID == nil || numPr.NumID.Val == 0 {
+			m.block(escapeBlockStart(text), false)
+			break
+		}
+		ilvl := 0
+		if numPr.Ilvl != nil && numPr.Ilvl.Val > 0 && numPr.Ilvl.Val < 9 {
+			ilvl = numPr.Ilvl.Val
+		}
+		counts := m.counts[numPr.NumID.Val]
+		if counts == nil {
+			counts = new([9]int)
+			m.counts[numPr.NumID.Val] = counts
+		}
+		counts[ilvl]++
+		for i := ilvl + 1; i < len(counts); i++ {
+			counts[i] = 0
+		}
+		marker := "- "
+		if l := m.levels[numPr.NumID.Val][ilvl]; l.format != "" && l.format != "bullet" && l.format != "none" {
+			marker = strconv.Itoa(l.start+counts[ilvl]-1) + ". "
+		}
+		m.block(strings.Repeat("    ", ilvl)+marker+text, true)
+	}
+	for _, b := range in.boxes {
+		m.paragraph(b)
+	}
+}
+
+func (m *markdownWriter) table(t *Table) {
+	rows := make([][]string, 0, len(t.TableRows))
+	cols := 0
+	for _, row := range t.TableRows {
+		cells := make([]string, 0, len(row.TableCells))
+		for _, c := range row.TableCells {
+			cells = append(cells, m.cell(c))
+			for i := 1; i < gridSpanOf(c); i++ {
+				cells = append(cells, "")
+			}
+		}
+		if len(cells) > cols {
+			cols = len(cells)
+		}
+		rows = append(rows, cells)
+	}
+	if cols == 0 {
+		return
+	}
+	var sb strings.Builder
+	for i, cells := range rows {
+		if i > 0 {
+			sb.WriteByte('\n')
+		}
+		sb.WriteByte('|')
+		for j := 0; j < cols; j++ {
+			sb.WriteByte(' ')
+			if j < len(cells) {
+				sb.WriteString(cells[j])
+			}
+			sb.WriteString(" |")
+		}
+		if i == 0 {
+			sb.WriteString("\n|")
+			for j := 0; j < cols; j++ {
+				sb.WriteString(" --- |")
+			}
+		}
+	}
+	m.block(sb.String(), false)
+}
+
+// cell joins the paragraphs of c by <br>, flattening the nested tables
+func (m *markdownWriter) cell(c *WTableCell) string {
+	parts := make([]string, 0, len(c.Items))
+	var add func(p *Paragraph)
+	add = func(p *Paragraph) {
+		in := markdownInline{markdownWriter: m, p: p, cell: true}
+		in.children(p.Children)
+		if s := strings.TrimSpace(in.String()); s != "" {
+			parts = append(parts, s)
+		}
+		for _, b := range in.boxes {
+			add(b)
+		}
+	}
+	var flatten func(items []interface{})
+	flatten = func(items []interface{}) {
+		for _, it := range items {
+			switch o := it.(type) {
+			case *Paragraph:
+				add(o)
+			case *Table:
+				for _, row := range o.TableRows {
+					for _, c := range row.TableCells {
+						flatten(c.Items)
+					}
+				}
+			}
+		}
+	}
+	flatten(c.Items)
+	return strings.Join(parts, "<br>")
+}
+
+// note writes the definition of the note referred as ref
+func (m *markdownWriter) note(ref string) {
+	n := m.f.noteOf(ref)
+	if n == nil {
+		return
+	}
+	parts := make([]string, 0, len(n.Items))
+	for _, it := range n.Items {
+		if p, ok := it.(*Paragraph); ok {
+			in := markdownInline{markdownWriter: m, p: p}
+			in.children(p.Children)
+			if s := strings.TrimSpace(in.String()); s != "" {
+				parts = append(parts, s)
+			}
+		}
+	}
+	m.block("[^"+ref+"]: "+strings.Join(parts, " "), true)
+}
+
+// image saves the image of rId id and returns the link to it
+func (m *markdownWriter) image(id string) string {
+	if link, ok := m.images[id]; ok {
+		return link
+	}
+	target, err := m.f.ReferTarget(id)
+	if err != nil {
+		return ""
+	}
+	link := target
+	if m.opts.SaveImage != nil || m.opts.ImageDir != "" {
+		media := m.f.Media(path.Base(target))
+		if media == nil {
+			return ""
+		}
+		link, err = saveMedia(media, m.opts.ImageDir, m.opts.SaveImage)
+		if err != nil {
+			if m.err == nil {
+				m.err = err
+			}
+			return ""
+		}
+	}
+	m.images[id] = link
+	return link
+}
+
+// refer records the note ref and returns its mark
+func (m *markdownWriter) refer(ref string) string {
+	m.notes.add(ref)
+	return "[^" + ref + "]"
+}
+
+func (in *markdownInline) children(items []interface{}) {
+	for _, c := range items {
+		switch o := c.(type) {
+		case *Run:
+			in.run(o)
+		case *Hyperlink:
+			in.hyperlink(o)
+		case *Insertion:
+			in.children(o.Children)
+		case *MoveTo:
+			in.children(o.Children)
+		}
+	}
+}
+
+func (in *markdownInline) run(r *Run) {
+	span := markdownSpan{}
+	if !in.plain {
+		rp := in.f.EffectiveRunProperties(in.p, r, nil)
+		span.bold = rp.Bold != nil && isOn(rp.Bold.Val)
+		span.italic = rp.Italic != nil && isOn(rp.Italic.Val)
+		span.strike = rp.Strike != nil && isOn(rp.Strike.Val)
+	}
+	for _, c := range r.Children {
+		switch x := c.(type) {
+		case *Text:
+			span.text = markdownEscaper.Replace(x.Text)
+			in.spans = append(in.spans, span)
+		case *Tab:
+			span.text = "\t"
+			in.spans = append(in.spans, span)
+		case *BarterRabbet:
+			if in.cell {
+				in.raw("<br>")
+			} else {
+				in.raw("\\\n")
+			}
+		case *FootnoteReference:
+			in.raw(in.refer(strconv.Itoa(x.ID)))
+		case *EndnoteReference:
+			in.raw(in.refer("e" + strconv.Itoa(x.ID)))
+		case *Drawing:
+			in.drawing(x)
+		}
+	}
+}
+
+func (in *markdownInline) hyperlink(h *Hyperlink) {
+	target := ""
+	if h.ID != "" {
+		target, _ = in.f.ReferTarget(h.ID)
+	} else if h.Anchor != "" {
+		target = "#" + h.Anchor
+	}
+	sub := markdownInline{markdownWriter: in.markdownWriter, p: in.p, plain: in.plain, cell: in.cell}
+	if h.Runs != nil {
+		for _, r := range *h.Runs {
+			if len(r.Children) == 0 && r.InstrText != "" {
+				sub.spans = append(sub.spans, markdownSpan{text: markdownEscaper.Replace(r.InstrText)})
+				continue
+			}
+			sub.run(r)
+		}
+	}
+	in.boxes = append(in.boxes, sub.boxes...)
+	text := strings.TrimSpace(sub.String())
+	if target == "" {
+		in.raw(text)
+		return
+	}
+	if text == "" {
+		text = markdownEscaper.Replace(target)
+	}
+	in.raw("[" + text + "](" + markdownLink(target) + ")")
+}
+
+func (in *markdownInline) drawing(d *Drawing) {
+	in.boxes = append(in.boxes, d.textBoxes()...)
+	gd := d.graphicData()
+	if gd == nil || gd.Pic == nil || gd.Pic.BlipFill == nil {
+		return
+	}
+	link := in.image(gd.Pic.BlipFill.Blip.Embed)
+	if link == "" {
+		return
+	}
+	var docPr *WPDocPr
+	if d.Inline != nil {
+		docPr = d.Inline.DocPr
+	} else if d.Anchor != nil {
+		docPr = d.Anchor.DocPr
+	}
+	alt := ""
+	if docPr != nil {
+		alt = markdownEscaper.Replace(docPr.Name)
+	}
+	in.raw("![" + alt + "](" + markdownLink(link) + ")")
+}
+
+func (in *markdownInline) raw(s string) {
+	if s != "" {
+		in.spans = append(in.spans, markdownSpan{text: s, raw: true})
+	}
+}
+
+// String merges the spans with the same format and wraps them in emphasis
+func (in *markdownInline) String() string {
+	var sb strings.Builder
+	for i := 0; i < len(in.spans); {
+		s := in.spans[i]
+		i++
+		if s.raw {
+			sb.WriteString(s.text)
+			continue
+		}
+		text := s.text
+		for ; i < len(in.spans); i++ {
+			n := in.spans[i]
+			if n.raw || n.bold != s.bold || n.italic != s.italic || n.strike != s.strike {
+				break
+			}
+			text += n.text
+		}
+		core := strings.TrimSpace(text)
+		if core == "" || !(s.bold || s.italic || s.strike) {
+			sb.WriteString(text)
+			continue
+		}
+		marker := ""
+		if s.strike {
+			marker += "~~"
+		}
+		if s.bold {
+			marker += "**"
+		}
+		if s.italic {
+			marker += "*"
+		}
+		start := strings.Index(text, core)
+		sb.WriteString(text[:start])
+		sb.WriteString(marker)
+		sb.WriteString(core)
+		for j := len(marker) - 1; j >= 0; j-- {
+			sb.WriteByte(marker[j])
+		}
+		sb.WriteString(text[start+len(core):])
+	}
+	return sb.String()
+}
+
+// escapeBlockStart keeps a paragraph from being read as a heading,
+// a quote, a list item or a rule
+func escapeBlockStart(s string) string {
+	switch s[0] {
+	case '#', '>', '-', '+', '=':
+		return `\` + s
+	}
+	if m := markdownOrderedStart.FindStringSubmatchIndex(s); m != nil {
+		return s[:m[4]] + `\` + s[m[4]:]
+	}
+	return s
+}
+
+// markdownLink escapes the spaces and parentheses of a link destination
+func markdownLink(s string) string {
+	return strings.NewReplacer(" ", "%20", "(", "%28", ")", "%29").Replace(s)
+}
+
+// headingLevel returns the heading level of the style of p, or 0
+// if it is not a heading. The style names and ids along the basedOn
+// chain are matched against "heading N", and "Title" is level 1.
+func (f *Docx) headingLevel(p *Paragraph) int {
+	id := paragraphStyleID(p)
+	if id == "" {
+		return 0
+	}
+	chain := f.resolvingStyles().chain(STYLE_PARAGRAPH, id)
+	names := make([]string, 0, 2*len(chain)+1)
+	names = append(names, id)
+	for i := len(chain) - 1; i >= 0; i-- {
+		names = append(names, chain[i].StyleID)
+		if chain[i].Name != nil {
+			names = append(names, chain[i].Name.Val)
+		}
+	}
+	for _, n := range names {
+		if strings.EqualFold(n, "Title") {
+			return 1
+		}
+		if m := headingStyleRegex.FindStringSubmatch(n); m != nil {
+			return int(m[1][0] - '0')
+		}
+	}
+	return 0
+}
+
+// listLevel is the format of a level of a numbering
+type listLevel struct {
+	format string
+	start  int
+}
+
+// listLevels maps numId and ilvl to the format of the level
+func (f *Docx) listLevels() map[int]map[int]listLevel {
+	abstract := make(map[string]map[int]listLevel)
+	if f.Numbering.AbstractNums != nil {
+		for _, an := range *f.Numbering.AbstractNums {
+			lvls := make(map[int]listLevel)
+			if an.Lvl != nil {
+				for _, l := range *an.Lvl {
+					if l == nil {
+						continue
+					}
+					lvl := listLevel{start: 1}
+					if l.NumFmt != nil && l.NumFmt.CommonAttrVal != nil {
+						lvl.format = l.NumFmt.Val
+					}
+					if l.Start != nil && l.Start.CommonAttrVal != nil {
+						if n, err := strconv.Atoi(l.Start.Val); err == nil {
+							lvl.start = n
+						}
+					}
+					lvls[l.ILvl] = lvl
+				}
+			}
+			abstract[an.AbstractNumID] = lvls
+		}
+	}
+	levels := make(map[int]map[int]listLevel)
+	if f.Numbering.Nums != nil {
+		for _, n := range *f.Numbering.Nums {
+			if n == nil || n.AbstractNumID == nil || n.AbstractNumID.CommonAttrVal == nil {
+				continue
+			}
+			id, err := strconv.Atoi(n.NumID)
+			if err != nil {
+				continue
+			}
+			levels[id] = abstract[n.AbstractNumID.Val]
+		}
+	}
+	return levels
+}
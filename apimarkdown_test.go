@@ -0,0 +1,97 @@
+/*
+   Copyright (c) 2020 gingfrederik
+   Copyright (c) 2021 Gonzalo Fernandez-Victorio
+   Copyright (c) 2021 Basement Crowd Ltd (https://www.basementcrowd.com)
+   Copyright (c) 2023 Fumiama Minamoto (源文雨)
+
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU Affero General Public License as published
+   by the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU Affero General Public License for more details.
+
+   You should have received a copy of the GNU Affero General Public License
+   along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package docx
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestMarshalMarkdown(t *testing.T) {
+	doc := NewA4()
+	doc.AddParagraphStyle("Heading1", "heading 1")
+	doc.AddParagraphStyle("Sub", "Sub").BasedOn = &BasedOn{Val: "Heading1"}
+	doc.AddParagraph().Style("Heading1").AddText("Report *1*").Bold()
+	doc.AddParagraph().Style("Sub").AddText("Scope")
+
+	p := doc.AddParagraph()
+	p.AddText("plain ")
+	p.AddText("bold ").Bold()
+	p.AddText("both").Bold().Italic()
+	p.AddText(" and ")
+	p.AddLink("site", "https://example.com/a b")
+	p.AddFootnote("see [here]")
+	doc.AddParagraph().AddText("1. not a list")
+
+	abstract := []AbstractNum{{AbstractNumID: "90", Lvl: &[]*Lvl{
+		{ILvl: 0, NumFmt: &NumFmt{CommonAttrVal: &CommonAttrVal{Val: "decimal"}}},
+		{ILvl: 1, NumFmt: &NumFmt{CommonAttrVal: &CommonAttrVal{Val: "bullet"}}},
+	}}}
+	nums := []*Num{{NumID: "90", AbstractNumID: &AbstractNumID{CommonAttrVal: &CommonAttrVal{Val: "90"}}}}
+	doc.Numbering.AbstractNums = &abstract
+	doc.Numbering.Nums = &nums
+	for i, s := range []string{"first", "nested", "second"} {
+		lp := doc.AddParagraph()
+		lp.Properties = &ParagraphProperties{NumPr: &NumPr{Ilvl: &Ilvl{Val: i % 2}, NumID: &NumID{Val: 90}}}
+		lp.AddText(s)
+	}
+
+	logo, err := os.ReadFile("testdata/fumiamayoko.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = doc.AddParagraph().AddInlineDrawing(logo)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tbl := doc.AddTable(2, 2)
+	tbl.TableRows[0].TableCells[0].AddParagraph().AddText("Item")
+	tbl.TableRows[0].TableCells[1].AddParagraph().AddText("Notes")
+	tbl.TableRows[1].TableCells[0].AddParagraph().AddText("a|b")
+	tbl.TableRows[1].TableCells[1].AddParagraph().AddText("line 1")
+	tbl.TableRows[1].TableCells[1].AddParagraph().AddText("line 2")
+
+	saved := map[string]int{}
+	var sb strings.Builder
+	err = doc.MarshalMarkdown(&sb, MarkdownOptions{SaveImage: func(name string, data []byte) (string, error) {
+		saved[name] = len(data)
+		return "img/" + name, nil
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "# Report \\*1\\*\n\n" +
+		"# Scope\n\n" +
+		"plain **bold** ***both*** and [site](https://example.com/a%20b)[^1]\n\n" +
+		"1\\. not a list\n\n" +
+		"1. first\n    - nested\n2. second\n\n" +
+		"![图片 1](img/image1.png)\n\n" +
+		"| Item | Notes |\n| --- | --- |\n| a\\|b | line 1<br>line 2 |\n\n" +
+		"[^1]: see \\[here\\]\n"
+	if sb.String() != expected {
+		t.Fatalf("unexpected markdown:\n%s", sb.String())
+	}
+	if len(saved) != 1 || saved["image1.png"] != len(logo) {
+		t.Fatal("unexpected saved images", saved)
+	}
+}
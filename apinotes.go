@@ -0,0 +1,212 @@
+/*
+   Copyright (c) 2020 gingfrederik
+   Copyright (c) 2021 Gonzalo Fernandez-Victorio
+   Copyright (c) 2021 Basement Crowd Ltd (https://www.basementcrowd.com)
+   Copyright (c) 2023 Fumiama Minamoto (源文雨)
+
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU Affero General Public License as published
+   by the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU Affero General Public License for more details.
+
+   You should have received a copy of the GNU Affero General Public License
+   along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package docx
+
+import (
+	"encoding/xml"
+	"strconv"
+	"strings"
+)
+
+// AddFootnote adds a footnote of text, and its reference mark at the end of p
+func (p *Paragraph) AddFootnote(text string) *Note {
+	return p.addNote(false, text)
+}
+
+// AddEndnote adds an endnote of text, and its reference mark at the end of p
+func (p *Paragraph) AddEndnote(text string) *Note {
+	return p.addNote(true, text)
+}
+
+func (p *Paragraph) addNote(endnote bool, text string) *Note {
+	n := p.file.notes(endnote).newNote()
+	var ref, mark interface{}
+	if endnote {
+		ref, mark = &EndnoteReference{ID: n.ID}, &EndnoteRef{}
+	} else {
+		ref, mark = &FootnoteReference{ID: n.ID}, &FootnoteRef{}
+	}
+	p.Children = append(p.Children, &Run{
+		RunProperties: &RunProperties{VertAlign: &VertAlign{Val: "superscript"}},
+		Children:      []interface{}{ref},
+		file:          p.file,
+	})
+	np := n.AddParagraph()
+	np.Children = append(np.Children, &Run{
+		RunProperties: &RunProperties{VertAlign: &VertAlign{Val: "superscript"}},
+		Children:      []interface{}{mark},
+		file:          p.file,
+	})
+	np.AddText(" " + text)
+	return n
+}
+
+// notes returns the footnotes or endnotes part, adding it if not exist
+//
+//	this func is not thread-safe
+func (f *Docx) notes(endnote bool) *Notes {
+	pn, local, typ := &f.footnotes, "footnotes", REL_FOOTNOTES
+	if endnote {
+		pn, local, typ = &f.endnotes, "endnotes", REL_ENDNOTES
+	}
+	if *pn != nil {
+		return *pn
+	}
+	n := newNotes(f, local)
+	n.name = "word/" + local + ".xml"
+	if f.hasPart(n.name) {
+		n.name = f.newPartName(local)
+	}
+	f.addPartRelation(typ, n.name)
+	// Word needs the separators
+	for i, t := range []interface{}{&Separator{}, &ContinuationSeparator{}} {
+		sep := &Note{
+			XMLName: xml.Name{Local: "w:" + n.noteLocal()},
+			Type:    NOTE_SEPARATOR,
+			ID:      i - 1,
+			file:    f,
+		}
+		if i > 0 {
+			sep.Type = NOTE_CONTINUATION_SEPARATOR
+		}
+		sep.AddParagraph().Children = append(make([]interface{}, 0, 1), &Run{
+			Children: []interface{}{t},
+			file:     f,
+		})
+		n.Notes = append(n.Notes, sep)
+	}
+	*pn = n
+	return n
+}
+
+// newNote appends an empty note with a new id
+func (n *Notes) newNote() *Note {
+	id := 1
+	for _, x := range n.Notes {
+		if x.ID >= id {
+			id = x.ID + 1
+		}
+	}
+	note := &Note{
+		XMLName: xml.Name{Local: "w:" + n.noteLocal()},
+		ID:      id,
+		Items:   make([]interface{}, 0, 4),
+		file:    n.file,
+	}
+	n.Notes = append(n.Notes, note)
+	return note
+}
+
+// Footnote returns the footnote of id, or nil
+func (f *Docx) Footnote(id int) *Note {
+	return f.footnotes.note(id)
+}
+
+// Endnote returns the endnote of id, or nil
+func (f *Docx) Endnote(id int) *Note {
+	return f.endnotes.note(id)
+}
+
+// noteOf returns the footnote of ref like 1, or the endnote of ref like e1
+func (f *Docx) noteOf(ref string) *Note {
+	id, err := strconv.Atoi(strings.TrimPrefix(ref, "e"))
+	if err != nil {
+		return nil
+	}
+	if strings.HasPrefix(ref, "e") {
+		return f.Endnote(id)
+	}
+	return f.Footnote(id)
+}
+
+// noteRefs collects the notes referred in text, like 1 for
+// footnote 1 and e1 for endnote 1, in the order they are met
+type noteRefs struct {
+	refs []string
+	seen map[string]struct{}
+}
+
+func (n *noteRefs) add(ref string) {
+	if n.seen == nil {
+		n.seen = make(map[string]struct{})
+	}
+	if _, ok := n.seen[ref]; !ok {
+		n.seen[ref] = struct{}{}
+		n.refs = append(n.refs, ref)
+	}
+}
+
+// Footnotes returns all footnotes but separators in the file
+func (f *Docx) Footnotes() []*Note {
+	return f.footnotes.normal()
+}
+
+// Endnotes returns all endnotes but separators in the file
+func (f *Docx) Endnotes() []*Note {
+	return f.endnotes.normal()
+}
+
+func (n *Notes) note(id int) *Note {
+	if n == nil {
+		return nil
+	}
+	for _, x := range n.Notes {
+		if x.ID == id {
+			return x
+		}
+	}
+	return nil
+}
+
+func (n *Notes) normal() []*Note {
+	if n == nil {
+		return nil
+	}
+	notes := make([]*Note, 0, len(n.Notes))
+	for _, x := range n.Notes {
+		if x.Type == "" {
+			notes = append(notes, x)
+		}
+	}
+	return notes
+}
+
+// AddParagraph adds a new paragraph to note
+func (n *Note) AddParagraph() *Paragraph {
+	p := &Paragraph{
+		Children: make([]interface{}, 0, 64),
+		file:     n.file,
+	}
+	n.Items = append(n.Items, p)
+	return p
+}
+
+// AddTable add a new table to note by col*row
+func (n *Note) AddTable(row int, col int) *Table {
+	tbl := n.file.newTable(row, col)
+	n.Items = append(n.Items, tbl)
+	return tbl
+}
+
+// String returns the plain text of note
+func (n *Note) String() string {
+	return strings.TrimSpace(itemsString(n.Items))
+}
@@ -26,18 +26,28 @@ func (f *Docx) AddParagraph() *Paragraph {
 		Children: make([]interface{}, 0, 64),
 		file:     f,
 	}
-	f.Document.Body.Items = append(f.Document.Body.Items, p)
+	f.Document.Body.append(p)
 	return p
 }
 
 // AddParagraph adds a new paragraph
 func (c *WTableCell) AddParagraph() *Paragraph {
-	c.Paragraphs = append(c.Paragraphs, &Paragraph{
+	p := &Paragraph{
 		Children: make([]interface{}, 0, 64),
 		file:     c.file,
-	})
+	}
+	c.Items = append(c.Items, p)
+	return p
+}
 
-	return c.Paragraphs[len(c.Paragraphs)-1]
+// firstParagraph returns the first paragraph in cell, or nil if there is none
+func (c *WTableCell) firstParagraph() *Paragraph {
+	for _, it := range c.Items {
+		if p, ok := it.(*Paragraph); ok {
+			return p
+		}
+	}
+	return nil
 }
 
 // Justification allows to set para's horizonal alignment
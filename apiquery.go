@@ -0,0 +1,417 @@
+/*
+   Copyright (c) 2020 gingfrederik
+   Copyright (c) 2021 Gonzalo Fernandez-Victorio
+   Copyright (c) 2021 Basement Crowd Ltd (https://www.basementcrowd.com)
+   Copyright (c) 2023 Fumiama Minamoto (源文雨)
+
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU Affero General Public License as published
+   by the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU Affero General Public License for more details.
+
+   You should have received a copy of the GNU Affero General Public License
+   along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package docx
+
+import (
+	"errors"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+var (
+	// ErrInvalidSelector is returned by Find if the selector cannot be parsed
+	ErrInvalidSelector = errors.New("invalid selector")
+	// ErrMatchRemoved is returned by Match if its node is no longer in its parent
+	ErrMatchRemoved = errors.New("matched node is no longer in its parent")
+	// ErrNodeMismatch is returned by Match if a node does not fit in the parent
+	ErrNodeMismatch = errors.New("node does not fit in the parent")
+)
+
+// Match is a node found by Find or FindText with its place in the tree
+type Match struct {
+	Node Node
+	// Path is the ancestors of Node, the root first
+	Path WalkPath
+	// Index is the position of Node in its parent when it was found,
+	// or -1 if the parent holds it in a field
+	Index int
+
+	siblings reflect.Value
+}
+
+// Parent returns the direct parent of the node
+func (m *Match) Parent() Node {
+	return m.Path.Parent()
+}
+
+// Prev returns the node before m in its parent, or nil if there is none
+func (m *Match) Prev() Node {
+	i := m.position()
+	if i <= 0 {
+		return nil
+	}
+	return nodeAt(m.siblings, i-1)
+}
+
+// Next returns the node after m in its parent, or nil if there is none
+func (m *Match) Next() Node {
+	i := m.position()
+	if i < 0 || i+1 >= m.siblings.Len() {
+		return nil
+	}
+	return nodeAt(m.siblings, i+1)
+}
+
+// InsertBefore puts n before the node in its parent.
+//
+// A node of another document is cloned into this one, so the node
+// actually inserted is returned, with the *RelationshipError of the
+// clone if any.
+func (m *Match) InsertBefore(n Node) (Node, error) {
+	return m.insert(n, m.position())
+}
+
+// InsertAfter puts n after the node in its parent, see InsertBefore
+func (m *Match) InsertAfter(n Node) (Node, error) {
+	i := m.position()
+	if i < 0 {
+		return nil, ErrMatchRemoved
+	}
+	return m.insert(n, i+1)
+}
+
+// Remove removes the node from its parent.
+// It returns false if the node is no longer there.
+func (m *Match) Remove() bool {
+	i := m.position()
+	if i < 0 {
+		return false
+	}
+	n := m.siblings.Len()
+	reflect.Copy(m.siblings.Slice(i, n-1), m.siblings.Slice(i+1, n))
+	m.siblings.Index(n - 1).Set(reflect.Zero(m.siblings.Type().Elem()))
+	m.siblings.SetLen(n - 1)
+	return true
+}
+
+// position returns the current index of the node in its parent,
+// which changes if its siblings are edited
+func (m *Match) position() int {
+	if !m.siblings.IsValid() {
+		return -1
+	}
+	for i := 0; i < m.siblings.Len(); i++ {
+		if nodeAt(m.siblings, i) == m.Node {
+			return i
+		}
+	}
+	return -1
+}
+
+func (m *Match) insert(n Node, i int) (Node, error) {
+	if i < 0 {
+		return nil, ErrMatchRemoved
+	}
+	if _, ok := nodeValue(m.siblings.Type().Elem(), n); !ok {
+		return nil, ErrNodeMismatch
+	}
+	var err error
+	if f := m.file(); f != nil {
+		n, err = adopt(f, n)
+	}
+	nv, _ := nodeValue(m.siblings.Type().Elem(), n)
+	m.siblings.Set(reflect.Append(m.siblings, reflect.Zero(m.siblings.Type().Elem())))
+	reflect.Copy(m.siblings.Slice(i+1, m.siblings.Len()), m.siblings.Slice(i, m.siblings.Len()-1))
+	m.siblings.Index(i).Set(nv)
+	return n, err
+}
+
+// file returns the document of the node, or of its nearest ancestor
+// that has one
+func (m *Match) file() *Docx {
+	if f := fileOf(m.Node); f != nil {
+		return f
+	}
+	for i := len(m.Path) - 1; i >= 0; i-- {
+		if f, ok := m.Path[i].(*Docx); ok {
+			return f
+		}
+		if f := fileOf(m.Path[i]); f != nil {
+			return f
+		}
+	}
+	return nil
+}
+
+// nodeAt returns the i-th element of list as a node
+func nodeAt(list reflect.Value, i int) Node {
+	e := list.Index(i)
+	if e.Kind() == reflect.Struct {
+		return e.Addr().Interface()
+	}
+	return e.Interface()
+}
+
+// Find returns the nodes in f matched by selector in document order.
+//
+// See Find for the syntax of selector.
+func (f *Docx) Find(selector string) ([]*Match, error) {
+	return Find(f, selector)
+}
+
+// FindText returns the paragraphs in f whose text matches re
+func (f *Docx) FindText(re *regexp.Regexp) []*Match {
+	return FindText(f, re)
+}
+
+// Find returns the descendants of node matched by selector in document order.
+//
+// A selector is a list of compounds separated by spaces, each one matching
+// a descendant of the node matched by the previous one. A compound is an
+// element name like p, r, t, tbl, tr, tc or hyperlink, or * for any node,
+// followed by attribute tests like [style=Heading1] or [text*="Pricing"].
+// The attributes are style and text, and the operators are = (equals),
+// *= (contains), ^= (begins with) and $= (ends with).
+func Find(node Node, selector string) ([]*Match, error) {
+	sel, err := parseSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+	return findMatches(node, sel.match), nil
+}
+
+// FindText returns the paragraphs in node whose own text matches re
+func FindText(node Node, re *regexp.Regexp) []*Match {
+	return findMatches(node, func(n Node, _ WalkPath) bool {
+		p, ok := n.(*Paragraph)
+		return ok && re.MatchString(paragraphText(p))
+	})
+}
+
+func findMatches(node Node, match func(Node, WalkPath) bool) (matches []*Match) {
+	w := walker{path: make(WalkPath, 0, 16)}
+	w.fn = func(n Node, path WalkPath) WalkAction {
+		if len(path) == 0 || !match(n, path) {
+			return WalkContinue
+		}
+		m := &Match{Node: n, Path: append(WalkPath(nil), path...), Index: -1}
+		if w.siblings.IsValid() {
+			m.Index, m.siblings = w.index, w.siblings
+		}
+		matches = append(matches, m)
+		return WalkContinue
+	}
+	w.visit(node)
+	return
+}
+
+// paragraphText returns the text of p without the text boxes in it
+func paragraphText(p *Paragraph) string {
+	sb := strings.Builder{}
+	Walk(p, func(n Node, _ WalkPath) WalkAction {
+		switch n.(type) {
+		case *Drawing, *Deletion, *MoveFrom:
+			return WalkSkip
+		}
+		writeText(&sb, n)
+		return WalkContinue
+	})
+	return sb.String()
+}
+
+// selectorTypes are the element names in a selector
+var selectorTypes = map[string]reflect.Type{
+	"body":        reflect.TypeOf((*Body)(nil)),
+	"hdr":         reflect.TypeOf((*Header)(nil)),
+	"ftr":         reflect.TypeOf((*Footer)(nil)),
+	"comment":     reflect.TypeOf((*Comment)(nil)),
+	"p":           reflect.TypeOf((*Paragraph)(nil)),
+	"r":           reflect.TypeOf((*Run)(nil)),
+	"t":           reflect.TypeOf((*Text)(nil)),
+	"tab":         reflect.TypeOf((*Tab)(nil)),
+	"br":          reflect.TypeOf((*BarterRabbet)(nil)),
+	"hyperlink":   reflect.TypeOf((*Hyperlink)(nil)),
+	"ins":         reflect.TypeOf((*Insertion)(nil)),
+	"del":         reflect.TypeOf((*Deletion)(nil)),
+	"moveFrom":    reflect.TypeOf((*MoveFrom)(nil)),
+	"moveTo":      reflect.TypeOf((*MoveTo)(nil)),
+	"tbl":         reflect.TypeOf((*Table)(nil)),
+	"tr":          reflect.TypeOf((*WTableRow)(nil)),
+	"tc":          reflect.TypeOf((*WTableCell)(nil)),
+	"sdt":         reflect.TypeOf((*StructuredDocumentTag)(nil)),
+	"drawing":     reflect.TypeOf((*Drawing)(nil)),
+	"pic":         reflect.TypeOf((*Picture)(nil)),
+	"wsp":         reflect.TypeOf((*WordprocessingShape)(nil)),
+	"wpc":         reflect.TypeOf((*WordprocessingCanvas)(nil)),
+	"wgp":         reflect.TypeOf((*WordprocessingGroup)(nil)),
+	"grpSp":       reflect.TypeOf((*WPGGroupShape)(nil)),
+	"txbxContent": reflect.TypeOf((*WTextBoxContent)(nil)),
+}
+
+// selector is a parsed selector, the outermost compound first
+type selector []compound
+
+type compound struct {
+	typ   reflect.Type // typ is nil for any node
+	tests []attrTest
+}
+
+type attrTest struct {
+	name, op, val string
+}
+
+func parseSelector(s string) (selector, error) {
+	var sel selector
+	s = strings.TrimSpace(s)
+	for s != "" {
+		var c compound
+		i := strings.IndexAny(s, "[ \t\n")
+		if i < 0 {
+			i = len(s)
+		}
+		name := s[:i]
+		s = s[i:]
+		if name != "" && name != "*" {
+			typ, ok := selectorTypes[name]
+			if !ok {
+				return nil, ErrInvalidSelector
+			}
+			c.typ = typ
+		}
+		for strings.HasPrefix(s, "[") {
+			j := closingBracket(s)
+			if j < 0 {
+				return nil, ErrInvalidSelector
+			}
+			test, ok := parseAttrTest(s[1:j])
+			if !ok {
+				return nil, ErrInvalidSelector
+			}
+			c.tests = append(c.tests, test)
+			s = s[j+1:]
+		}
+		if name == "" && len(c.tests) == 0 {
+			return nil, ErrInvalidSelector
+		}
+		sel = append(sel, c)
+		rest := strings.TrimLeft(s, " \t\n")
+		if rest == s && s != "" {
+			return nil, ErrInvalidSelector
+		}
+		s = rest
+	}
+	if len(sel) == 0 {
+		return nil, ErrInvalidSelector
+	}
+	return sel, nil
+}
+
+// closingBracket returns the index of the ] that ends s[0],
+// skipping the quoted values
+func closingBracket(s string) int {
+	var quote byte
+	for i := 1; i < len(s); i++ {
+		switch {
+		case quote != 0:
+			if s[i] == quote {
+				quote = 0
+			}
+		case s[i] == '"' || s[i] == '\'':
+			quote = s[i]
+		case s[i] == ']':
+			return i
+		}
+	}
+	return -1
+}
+
+func parseAttrTest(s string) (attrTest, bool) {
+	i := strings.IndexByte(s, '=')
+	if i <= 0 {
+		return attrTest{}, false
+	}
+	test := attrTest{name: s[:i], op: "=", val: strings.TrimSpace(s[i+1:])}
+	if c := test.name[len(test.name)-1]; c == '*' || c == '^' || c == '$' {
+		test.name, test.op = test.name[:len(test.name)-1], string(c)+"="
+	}
+	test.name = strings.TrimSpace(test.name)
+	if test.name != "style" && test.name != "text" {
+		return attrTest{}, false
+	}
+	if n := len(test.val); n >= 2 && (test.val[0] == '"' || test.val[0] == '\'') && test.val[n-1] == test.val[0] {
+		test.val = test.val[1 : n-1]
+	}
+	return test, true
+}
+
+// match tells whether n is matched by the last compound
+// and its ancestors by the others in order
+func (sel selector) match(n Node, path WalkPath) bool {
+	if !sel[len(sel)-1].match(n) {
+		return false
+	}
+	i := len(sel) - 2
+	for j := len(path) - 1; j >= 0 && i >= 0; j-- {
+		if sel[i].match(path[j]) {
+			i--
+		}
+	}
+	return i < 0
+}
+
+func (c *compound) match(n Node) bool {
+	if c.typ != nil && reflect.TypeOf(n) != c.typ {
+		return false
+	}
+	for _, t := range c.tests {
+		if !t.match(n) {
+			return false
+		}
+	}
+	return true
+}
+
+func (t *attrTest) match(n Node) bool {
+	var v string
+	if t.name == "style" {
+		v = styleOf(n)
+	} else {
+		v = PlainText(n)
+	}
+	switch t.op {
+	case "*=":
+		return strings.Contains(v, t.val)
+	case "^=":
+		return strings.HasPrefix(v, t.val)
+	case "$=":
+		return strings.HasSuffix(v, t.val)
+	default:
+		return v == t.val
+	}
+}
+
+// styleOf returns the style id of a paragraph, run or table
+func styleOf(n Node) string {
+	switch o := n.(type) {
+	case *Paragraph:
+		return paragraphStyleID(o)
+	case *Run:
+		if o.RunProperties != nil && o.RunProperties.RunStyle != nil {
+			return o.RunProperties.RunStyle.Val
+		}
+	case *Table:
+		if o.TableProperties != nil && o.TableProperties.Style != nil {
+			return o.TableProperties.Style.Val
+		}
+	}
+	return ""
+}
@@ -0,0 +1,109 @@
+/*
+   Copyright (c) 2020 gingfrederik
+   Copyright (c) 2021 Gonzalo Fernandez-Victorio
+   Copyright (c) 2021 Basement Crowd Ltd (https://www.basementcrowd.com)
+   Copyright (c) 2023 Fumiama Minamoto (源文雨)
+
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU Affero General Public License as published
+   by the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU Affero General Public License for more details.
+
+   You should have received a copy of the GNU Affero General Public License
+   along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package docx
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestFind(t *testing.T) {
+	doc := NewA4()
+	doc.AddParagraph().AddText("Intro")
+	doc.AddParagraph().Style("Heading1").AddText("Pricing")
+	tbl := doc.AddTable(2, 3)
+	tbl.TableRows[0].TableCells[0].AddParagraph().AddText("Item")
+	tbl.TableRows[1].TableCells[0].AddParagraph().AddText("Apple")
+	tbl.TableRows[1].TableCells[0].TableCellProperties.GridSpan = &WGridSpan{Val: 2}
+	tbl.TableRows[1].TableCells = tbl.TableRows[1].TableCells[:2]
+	doc.AddParagraph().Style("Heading1").AddText("Terms")
+
+	ms, err := doc.Find("p[style=Heading1][text^=Pric]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ms) != 1 || ms[0].Index != 1 || ms[0].Parent() != &doc.Document.Body {
+		t.Fatal("unexpected matches", ms)
+	}
+	if ms[0].Next() != tbl || ms[0].Prev() != doc.Document.Body.Items[0] {
+		t.Fatal("unexpected siblings")
+	}
+
+	ms, err = doc.Find(`tbl tc p[text*="pp"]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cell := tbl.Cell(1, 1)
+	if len(ms) != 1 || ms[0].Node != cell.Node.(*WTableCell).Items[0].(*Paragraph) || tbl.Cell(1, 2).Node != tbl.TableRows[1].TableCells[1] || tbl.Cell(1, 3) != nil {
+		t.Fatal("unexpected cell matches", ms)
+	}
+	if cell.Index != 0 || cell.Parent() != tbl.TableRows[1] || cell.Next() != tbl.TableRows[1].TableCells[1] {
+		t.Fatal("cell is not positioned", cell.Index, cell.Parent())
+	}
+	cellItems := func() []interface{} {
+		return tbl.TableRows[1].TableCells[0].Items
+	}
+	p := &Paragraph{file: doc}
+	p.AddText("Banana")
+	if n, err := ms[0].InsertAfter(p); n != p || err != nil || len(cellItems()) != 2 || cellItems()[1].(*Paragraph) != p {
+		t.Fatal("paragraph not inserted", err)
+	}
+	nested := doc.newTable(1, 1)
+	if _, err := ms[0].InsertBefore(nested); err != nil || cellItems()[0] != nested || !tbl.TableRows[1].TableCells[0].Remove(nested) {
+		t.Fatal("table not inserted into cell", err)
+	}
+	if _, err := cell.InsertBefore(&Paragraph{}); err != ErrNodeMismatch {
+		t.Fatal("paragraph inserted into row", err)
+	}
+	if !ms[0].Remove() || cellItems()[0].(*Paragraph) != p || ms[0].Remove() {
+		t.Fatal("paragraph not removed")
+	}
+	if _, err := ms[0].InsertAfter(p); err != ErrMatchRemoved {
+		t.Fatal("inserted after a removed node", err)
+	}
+
+	ms = doc.FindText(regexp.MustCompile(`^(Intro|Terms)$`))
+	if len(ms) != 2 {
+		t.Fatal("unexpected text matches", ms)
+	}
+	np := &Paragraph{file: doc}
+	np.AddText("Notes")
+	if _, err := ms[1].InsertBefore(np); err != nil || PlainText(&doc.Document.Body) != "Intro\nPricing\nItem\nBanana\nNotes\nTerms" {
+		t.Fatal("unexpected body", PlainText(&doc.Document.Body))
+	}
+
+	other := NewA4()
+	op := other.AddParagraph()
+	op.AddLink("link", "https://github.com/fumiama/go-docx")
+	n, err := ms[0].InsertAfter(op)
+	if err != nil || n == op || n.(*Paragraph).file != doc || doc.Document.Body.Items[1] != n {
+		t.Fatal("paragraph of another document not cloned", err)
+	}
+	if _, err := doc.ReferTarget(n.(*Paragraph).Children[0].(*Hyperlink).ID); err != nil {
+		t.Fatal("link not moved", err)
+	}
+
+	for _, s := range []string{"", "x", "p[", "p[size=1]", "p]"} {
+		if _, err := doc.Find(s); err != ErrInvalidSelector {
+			t.Fatal("invalid selector accepted", s)
+		}
+	}
+}
@@ -0,0 +1,310 @@
+/*
+   Copyright (c) 2020 gingfrederik
+   Copyright (c) 2021 Gonzalo Fernandez-Victorio
+   Copyright (c) 2021 Basement Crowd Ltd (https://www.basementcrowd.com)
+   Copyright (c) 2023 Fumiama Minamoto (源文雨)
+
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU Affero General Public License as published
+   by the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU Affero General Public License for more details.
+
+   You should have received a copy of the GNU Affero General Public License
+   along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package docx
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ReplaceAll replaces every key of repl with its value in the text of
+// body, tables, headers, footers, notes, comments and text boxes, and
+// returns the number of substitutions.
+//
+// A key is found even if it is split into several runs, and the
+// value takes the formatting of the run where the key begins.
+// Other runs keep their formatting, so there is no need to
+// merge them by Paragraph.MergeText before.
+func (f *Docx) ReplaceAll(repl map[string]string) int {
+	re := keysRegexp(repl)
+	if re == nil {
+		return 0
+	}
+	return f.replace(re, func(s string, m []int) string {
+		return repl[s[m[0]:m[1]]]
+	})
+}
+
+// ReplaceAllRegexp is like ReplaceAll but replaces the matches of re
+// with repl, in which $1 or ${name} is expanded like regexp.Regexp.Expand
+func (f *Docx) ReplaceAllRegexp(re *regexp.Regexp, repl string) int {
+	return f.replace(re, func(s string, m []int) string {
+		return string(re.ExpandString(nil, repl, s, m))
+	})
+}
+
+// ReplaceAll replaces every key of repl with its value in p,
+// see Docx.ReplaceAll
+func (p *Paragraph) ReplaceAll(repl map[string]string) int {
+	re := keysRegexp(repl)
+	if re == nil {
+		return 0
+	}
+	return p.replace(re, func(s string, m []int) string {
+		return repl[s[m[0]:m[1]]]
+	})
+}
+
+// ReplaceAllRegexp replaces the matches of re with repl in p,
+// see Docx.ReplaceAllRegexp
+func (p *Paragraph) ReplaceAllRegexp(re *regexp.Regexp, repl string) int {
+	return p.replace(re, func(s string, m []int) string {
+		return string(re.ExpandString(nil, repl, s, m))
+	})
+}
+
+// replaceFunc returns the replacement of the match m in s
+type replaceFunc func(s string, m []int) string
+
+// keysRegexp matches any of the keys of repl, the longest first
+func keysRegexp(repl map[string]string) *regexp.Regexp {
+	keys := make([]string, 0, len(repl))
+	for k := range repl {
+		if k != "" {
+			keys = append(keys, k)
+		}
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if len(keys[i]) != len(keys[j]) {
+			return len(keys[i]) > len(keys[j])
+		}
+		return keys[i] < keys[j]
+	})
+	for i, k := range keys {
+		keys[i] = regexp.QuoteMeta(k)
+	}
+	return regexp.MustCompile(strings.Join(keys, "|"))
+}
+
+func (f *Docx) replace(re *regexp.Regexp, fn replaceFunc) (n int) {
+	for _, items := range f.stories() {
+		n += replaceItems(*items, re, fn)
+	}
+	return
+}
+
+func replaceItems(items []interface{}, re *regexp.Regexp, fn replaceFunc) (n int) {
+	for _, it := range items {
+		switch o := it.(type) {
+		case *Paragraph:
+			n += o.replace(re, fn)
+		case *Table:
+			for _, row := range o.TableRows {
+				for _, cell := range row.TableCells {
+					n += replaceItems(cell.Items, re, fn)
+				}
+			}
+		case *StructuredDocumentTag:
+			if c := o.SdtContent; c != nil {
+				if c.Paragraphs != nil {
+					for _, p := range *c.Paragraphs {
+						n += p.replace(re, fn)
+					}
+				}
+				if c.Tables != nil {
+					for _, t := range *c.Tables {
+						n += replaceItems([]interface{}{t}, re, fn)
+					}
+				}
+			}
+		}
+	}
+	return
+}
+
+// textPiece is a text in a run
+type textPiece struct {
+	t *Text
+	r *Run
+}
+
+// replace finds re in each span of text not broken by tabs,
+// breaks or drawings, and replaces the matches by fn
+func (p *Paragraph) replace(re *regexp.Regexp, fn replaceFunc) (n int) {
+	spans := make([][]textPiece, 0, 4)
+	var span []textPiece
+	flush := func() {
+		if len(span) > 0 {
+			spans = append(spans, span)
+			span = nil
+		}
+	}
+	var visit func(children []interface{})
+	visitRun := func(r *Run) {
+		for _, c := range r.Children {
+			switch x := c.(type) {
+			case *Text:
+				span = append(span, textPiece{t: x, r: r})
+			case *Drawing:
+				flush()
+				for _, tp := range x.textBoxes() {
+					n += tp.replace(re, fn)
+				}
+			default:
+				flush()
+			}
+		}
+	}
+	visit = func(children []interface{}) {
+		for _, c := range children {
+			switch o := c.(type) {
+			case *Run:
+				visitRun(o)
+			case *Hyperlink:
+				if o.Runs != nil {
+					for _, r := range *o.Runs {
+						visitRun(r)
+					}
+				}
+			case *Insertion:
+				visit(o.Children)
+			case *MoveTo:
+				visit(o.Children)
+			case *StructuredDocumentTag:
+				// the text of a content control is replaced on its own
+				flush()
+				if o.SdtContent != nil && o.SdtContent.Runs != nil {
+					for _, r := range *o.SdtContent.Runs {
+						visitRun(r)
+					}
+				}
+				flush()
+			}
+		}
+	}
+	visit(p.Children)
+	flush()
+	for _, s := range spans {
+		n += replaceSpan(s, re, fn)
+	}
+	return
+}
+
+// replaceSpan replaces the matches in the joined text of pieces,
+// putting each replacement into the piece where the match begins
+func replaceSpan(pieces []textPiece, re *regexp.Regexp, fn replaceFunc) (n int) {
+	sb := strings.Builder{}
+	starts := make([]int, len(pieces)+1)
+	for i, pc := range pieces {
+		starts[i] = sb.Len()
+		sb.WriteString(pc.t.Text)
+	}
+	starts[len(pieces)] = sb.Len()
+	text := sb.String()
+	ms := re.FindAllStringSubmatchIndex(text, -1)
+	// from the last one, so that the offsets before it are kept
+	for i := len(ms) - 1; i >= 0; i-- {
+		m := ms[i]
+		if m[0] == m[1] {
+			continue
+		}
+		rep := fn(text, m)
+		first := true
+		for j, pc := range pieces {
+			st, end := starts[j], starts[j+1]
+			if end <= m[0] || st >= m[1] {
+				continue
+			}
+			lo, hi := 0, end-st
+			if m[0] > st {
+				lo = m[0] - st
+			}
+			if m[1] < end {
+				hi = m[1] - st
+			}
+			s := pc.t.Text
+			if first {
+				pc.t.Text = s[:lo] + rep + s[hi:]
+				first = false
+			} else {
+				pc.t.Text = s[:lo] + s[hi:]
+			}
+		}
+		n++
+	}
+	if n == 0 {
+		return
+	}
+	for _, pc := range pieces {
+		if pc.t.Text == "" {
+			pc.r.dropChild(pc.t)
+			continue
+		}
+		if strings.TrimSpace(pc.t.Text) != pc.t.Text {
+			pc.t.XMLSpace = "preserve"
+		}
+	}
+	return
+}
+
+// dropChild removes c from r.Children
+func (r *Run) dropChild(c interface{}) {
+	for i, x := range r.Children {
+		if x == c {
+			r.Children = append(r.Children[:i], r.Children[i+1:]...)
+			return
+		}
+	}
+}
+
+// textBoxes returns the paragraphs in the text boxes of d
+func (d *Drawing) textBoxes() []*Paragraph {
+	var g *AGraphic
+	if d.Inline != nil {
+		g = d.Inline.Graphic
+	} else if d.Anchor != nil {
+		g = d.Anchor.Graphic
+	}
+	if g == nil || g.GraphicData == nil {
+		return nil
+	}
+	gd := g.GraphicData
+	elems := []interface{}{gd.Shape}
+	if gd.Canvas != nil {
+		elems = append(elems, gd.Canvas.Items...)
+	}
+	if gd.Group != nil {
+		elems = append(elems, gd.Group.Elems...)
+	}
+	return shapeTextBoxes(elems)
+}
+
+func shapeTextBoxes(elems []interface{}) []*Paragraph {
+	var ps []*Paragraph
+	for _, e := range elems {
+		switch o := e.(type) {
+		case *WordprocessingShape:
+			if o == nil || o.TextBox == nil || o.TextBox.Content == nil {
+				continue
+			}
+			for i := range o.TextBox.Content.Paragraphs {
+				ps = append(ps, &o.TextBox.Content.Paragraphs[i])
+			}
+		case *WPGGroupShape:
+			ps = append(ps, shapeTextBoxes(o.Elems)...)
+		}
+	}
+	return ps
+}
@@ -0,0 +1,108 @@
+/*
+   Copyright (c) 2020 gingfrederik
+   Copyright (c) 2021 Gonzalo Fernandez-Victorio
+   Copyright (c) 2021 Basement Crowd Ltd (https://www.basementcrowd.com)
+   Copyright (c) 2023 Fumiama Minamoto (源文雨)
+
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU Affero General Public License as published
+   by the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU Affero General Public License for more details.
+
+   You should have received a copy of the GNU Affero General Public License
+   along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package docx
+
+import (
+	"bytes"
+	"regexp"
+	"testing"
+)
+
+const placeholderDocument = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" xmlns:wp="http://schemas.openxmlformats.org/drawingml/2006/wordprocessingDrawing" xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main" xmlns:wps="http://schemas.microsoft.com/office/word/2010/wordprocessingShape"><w:body>
+<w:p><w:r><w:t xml:space="preserve">Dear </w:t></w:r><w:r w:rsidR="00A1"><w:rPr><w:b/></w:rPr><w:t>{{customer</w:t></w:r><w:proofErr w:type="spellStart"/><w:r w:rsidR="00B2"><w:rPr><w:i/></w:rPr><w:t>_name}}</w:t></w:r><w:r><w:t>, order {{id}}</w:t></w:r><w:r><w:tab/><w:t>{{id}}.</w:t></w:r></w:p>
+<w:tbl><w:tr><w:tc><w:p><w:r><w:t>{{</w:t></w:r><w:r><w:t>id}}</w:t></w:r></w:p></w:tc></w:tr></w:tbl>
+<w:p><w:r><w:t>Due 2023-01-31</w:t></w:r></w:p>
+<w:p><w:r><w:drawing><wp:inline><wp:extent cx="1" cy="1"/><wp:docPr id="1" name="box"/><a:graphic><a:graphicData uri="http://schemas.microsoft.com/office/word/2010/wordprocessingShape"><wps:wsp><wps:txbx><w:txbxContent><w:p><w:r><w:t>#{{id}}</w:t></w:r></w:p></w:txbxContent></wps:txbx><wps:bodyPr/></wps:wsp></a:graphicData></a:graphic></wp:inline></w:drawing></w:r></w:p>
+</w:body></w:document>`
+
+func TestReplaceAll(t *testing.T) {
+	data := replaceDocument(t, placeholderDocument)
+	doc, err := Parse(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := doc.ReplaceAll(map[string]string{
+		"{{customer_name}}": "ACME Corp.",
+		"{{id}}":            "42",
+	})
+	if n != 5 {
+		t.Fatal("unexpected substitutions", n)
+	}
+	p := doc.Document.Body.Items[0].(*Paragraph)
+	if p.String() != "Dear ACME Corp., order 42\t42." {
+		t.Fatal("unexpected text", p.String())
+	}
+	r := p.Children[1].(*Run)
+	if r.RunProperties.Bold == nil || r.Children[0].(*Text).Text != "ACME Corp." {
+		t.Fatal("formatting of the first run is not kept")
+	}
+	if r = p.Children[2].(*Run); len(r.Children) != 0 || r.RunProperties.Italic == nil {
+		t.Fatal("unexpected second run", r.Children)
+	}
+	if s := doc.Document.Body.Items[1].(*Table).TableRows[0].TableCells[0].Items[0].(*Paragraph).String(); s != "42" {
+		t.Fatal("unexpected cell", s)
+	}
+
+	box := doc.Document.Body.Items[3].(*Paragraph).Children[0].(*Run).Children[0].(*Drawing).textBoxes()
+	if len(box) != 1 || box[0].String() != "#42" {
+		t.Fatal("text box not replaced")
+	}
+
+	n = doc.ReplaceAllRegexp(regexp.MustCompile(`(\d{4})-(\d{2})-(\d{2})`), "$3/$2/$1")
+	if n != 1 {
+		t.Fatal("unexpected substitutions", n)
+	}
+	if s := doc.Document.Body.Items[2].(*Paragraph).String(); s != "Due 31/01/2023" {
+		t.Fatal("unexpected text", s)
+	}
+
+	w := NewA4()
+	w.AddHeader(HDRFTR_DEFAULT).AddParagraph().AddText("{{title}}")
+	if w.ReplaceAll(map[string]string{"{{title}}": "Report"}) != 1 || w.headers[0].String() != "Report" {
+		t.Fatal("header not replaced")
+	}
+}
+
+func TestReplaceAllInContentControls(t *testing.T) {
+	data := replaceDocument(t, `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>
+<w:sdt><w:sdtContent><w:p><w:r><w:t>{{id}}</w:t></w:r></w:p></w:sdtContent></w:sdt>
+<w:tbl><w:tr><w:tc><w:sdt><w:sdtContent><w:p><w:r><w:t>{{id}}</w:t></w:r></w:p></w:sdtContent></w:sdt><w:p/></w:tc></w:tr></w:tbl>
+<w:p><w:r><w:t>No. </w:t></w:r><w:sdt><w:sdtContent><w:r><w:t>{{</w:t></w:r><w:r><w:t>id}}</w:t></w:r></w:sdtContent></w:sdt></w:p>
+</w:body></w:document>`)
+	doc, err := Parse(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := doc.ReplaceAll(map[string]string{"{{id}}": "42"}); n != 3 {
+		t.Fatal("unexpected substitutions", n)
+	}
+	buf := bytes.NewBuffer(make([]byte, 0, 1024*1024))
+	_, err = doc.WriteTo(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	xml := readZipFile(t, buf.Bytes(), "word/document.xml")
+	if bytes.Contains(xml, []byte("{{")) || bytes.Count(xml, []byte("42")) != 3 {
+		t.Fatal("content controls not replaced", string(xml))
+	}
+}
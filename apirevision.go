@@ -0,0 +1,475 @@
+/*
+   Copyright (c) 2020 gingfrederik
+   Copyright (c) 2021 Gonzalo Fernandez-Victorio
+   Copyright (c) 2021 Basement Crowd Ltd (https://www.basementcrowd.com)
+   Copyright (c) 2023 Fumiama Minamoto (源文雨)
+
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU Affero General Public License as published
+   by the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU Affero General Public License for more details.
+
+   You should have received a copy of the GNU Affero General Public License
+   along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package docx
+
+// RevisionRule decides whether a tracked change is accepted or rejected
+type RevisionRule func(rev *Revision) bool
+
+// AllRevisions matches every tracked change
+func AllRevisions(_ *Revision) bool {
+	return true
+}
+
+// RevisionsBy matches the tracked changes made by any of author
+func RevisionsBy(author ...string) RevisionRule {
+	return func(rev *Revision) bool {
+		for _, a := range author {
+			if rev.Author == a {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// AcceptAllRevisions accepts all tracked changes in file
+func (f *Docx) AcceptAllRevisions() {
+	f.AcceptRevisions(AllRevisions)
+}
+
+// RejectAllRevisions rejects all tracked changes in file
+func (f *Docx) RejectAllRevisions() {
+	f.RejectRevisions(AllRevisions)
+}
+
+// AcceptRevisions accepts the tracked changes matched by rule
+func (f *Docx) AcceptRevisions(rule RevisionRule) {
+	f.resolveRevisions(&revisionPass{accept: true, rule: rule})
+}
+
+// RejectRevisions rejects the tracked changes matched by rule
+func (f *Docx) RejectRevisions(rule RevisionRule) {
+	f.resolveRevisions(&revisionPass{rule: rule})
+}
+
+// Revisions returns all tracked changes in file
+func (f *Docx) Revisions() []*Revision {
+	revs := make([]*Revision, 0, 16)
+	f.eachRevision(func(rev *Revision) {
+		revs = append(revs, rev)
+	})
+	return revs
+}
+
+// AddTrackedText adds text at the end of p as an insertion by author
+func (p *Paragraph) AddTrackedText(author, text string) *Run {
+	r := p.AddText(text)
+	p.TrackInsert(r, author)
+	return r
+}
+
+// TrackInsert marks r, a child of p, as inserted by author.
+// It returns nil if r is not found.
+func (p *Paragraph) TrackInsert(r *Run, author string) *Insertion {
+	i := p.IndexOf(r)
+	if i < 0 {
+		return nil
+	}
+	ins := &Insertion{
+		Revision: p.file.newRevision(author),
+		Children: []interface{}{r},
+	}
+	p.Children[i] = ins
+	return ins
+}
+
+// TrackDelete marks r, a child of p, as deleted by author.
+// It returns nil if r is not found.
+func (p *Paragraph) TrackDelete(r *Run, author string) *Deletion {
+	i := p.IndexOf(r)
+	if i < 0 {
+		return nil
+	}
+	for j, c := range r.Children {
+		if t, ok := c.(*Text); ok {
+			r.Children[j] = &DeletedText{XMLSpace: t.XMLSpace, Text: t.Text}
+		}
+	}
+	if r.InstrText != "" {
+		r.InstrText, r.DelInstrText = "", r.InstrText
+	}
+	del := &Deletion{
+		Revision: p.file.newRevision(author),
+		Children: []interface{}{r},
+	}
+	p.Children[i] = del
+	return del
+}
+
+// TrackReplace marks r, a child of p, as deleted by author and
+// inserts text with the same properties after it.
+// It returns nil if r is not found.
+func (p *Paragraph) TrackReplace(r *Run, author, text string) (*Deletion, *Insertion) {
+	i := p.IndexOf(r)
+	if i < 0 {
+		return nil, nil
+	}
+	del := p.TrackDelete(r, author)
+	nr := &Run{
+		RunProperties: &RunProperties{},
+		Children:      []interface{}{&Text{Text: text}},
+		file:          p.file,
+	}
+	if r.RunProperties != nil {
+		rp := newCloner(p.file, nil).clone(r.RunProperties).(*RunProperties)
+		rp.Inserted, rp.Deleted, rp.Change, rp.preserved = nil, nil, nil, nil
+		nr.RunProperties = rp
+	}
+	ins := &Insertion{
+		Revision: p.file.newRevision(author),
+		Children: []interface{}{nr},
+	}
+	children := make([]interface{}, 0, len(p.Children)+1)
+	children = append(children, p.Children[:i+1]...)
+	children = append(children, ins)
+	children = append(children, p.Children[i+1:]...)
+	p.Children = children
+	return del, ins
+}
+
+// TrackProperties keeps the current properties of r so that the
+// following changes to them are tracked as made by author
+func (r *Run) TrackProperties(author string) *RunPropertiesChange {
+	old := &RunProperties{}
+	if r.RunProperties != nil {
+		old = newCloner(r.file, nil).clone(r.RunProperties).(*RunProperties)
+		old.Inserted, old.Deleted, old.Change, old.preserved = nil, nil, nil, nil
+	} else {
+		r.RunProperties = &RunProperties{}
+	}
+	c := &RunPropertiesChange{
+		Revision:      r.file.newRevision(author),
+		RunProperties: old,
+	}
+	r.RunProperties.Change = c
+	return c
+}
+
+// TrackProperties keeps the current properties of p so that the
+// following changes to them are tracked as made by author
+func (p *Paragraph) TrackProperties(author string) *ParagraphPropertiesChange {
+	old := &ParagraphProperties{}
+	if p.Properties != nil {
+		pp := *p.Properties
+		pp.RunProperties, pp.SectPr, pp.Change, pp.preserved = nil, nil, nil, nil
+		old = newCloner(p.file, nil).clone(&pp).(*ParagraphProperties)
+	} else {
+		p.Properties = &ParagraphProperties{}
+	}
+	c := &ParagraphPropertiesChange{
+		Revision:            p.file.newRevision(author),
+		ParagraphProperties: old,
+	}
+	p.Properties.Change = c
+	return c
+}
+
+// newRevision returns the attributes of a new tracked change by author
+//
+//	Date is left empty, set it to something like 2006-01-02T15:04:05Z if needed
+//	this func is not thread-safe
+func (f *Docx) newRevision(author string) Revision {
+	if f.revID == 0 {
+		f.eachRevision(func(rev *Revision) {
+			if rev.ID > f.revID {
+				f.revID = rev.ID
+			}
+		})
+	}
+	f.revID++
+	return Revision{ID: f.revID, Author: author}
+}
+
+// stories returns the items of body, headers, footers, notes and comments
+func (f *Docx) stories() []*[]interface{} {
+	stories := []*[]interface{}{&f.Document.Body.Items}
+	for _, h := range f.headers {
+		stories = append(stories, &h.Items)
+	}
+	for _, ft := range f.footers {
+		stories = append(stories, &ft.Items)
+	}
+	for _, n := range []*Notes{f.footnotes, f.endnotes} {
+		if n == nil {
+			continue
+		}
+		for _, x := range n.Notes {
+			stories = append(stories, &x.Items)
+		}
+	}
+	if f.comments != nil {
+		for _, c := range f.comments.Comments {
+			stories = append(stories, &c.Items)
+		}
+	}
+	return stories
+}
+
+// eachRevision calls fn on every tracked change in file
+func (f *Docx) eachRevision(fn func(*Revision)) {
+	for _, items := range f.stories() {
+		revisionsIn(*items, fn)
+	}
+}
+
+func revisionsIn(items []interface{}, fn func(*Revision)) {
+	runProperties := func(rp *RunProperties) {
+		if rp == nil {
+			return
+		}
+		if rp.Inserted != nil {
+			fn(&rp.Inserted.Revision)
+		}
+		if rp.Deleted != nil {
+			fn(&rp.Deleted.Revision)
+		}
+		if rp.Change != nil {
+			fn(&rp.Change.Revision)
+		}
+	}
+	for _, it := range items {
+		switch o := it.(type) {
+		case *Paragraph:
+			if o.Properties != nil {
+				if o.Properties.Change != nil {
+					fn(&o.Properties.Change.Revision)
+				}
+				runProperties(o.Properties.RunProperties)
+			}
+			revisionsIn(o.Children, fn)
+		case *Table:
+			for _, row := range o.TableRows {
+				for _, cell := range row.TableCells {
+					revisionsIn(cell.Items, fn)
+				}
+			}
+		case *Insertion:
+			fn(&o.Revision)
+			revisionsIn(o.Children, fn)
+		case *Deletion:
+			fn(&o.Revision)
+			revisionsIn(o.Children, fn)
+		case *MoveFrom:
+			fn(&o.Revision)
+			revisionsIn(o.Children, fn)
+		case *MoveTo:
+			fn(&o.Revision)
+			revisionsIn(o.Children, fn)
+		case *MoveRangeStart:
+			fn(&o.Revision)
+		case *Run:
+			runProperties(o.RunProperties)
+		case *Hyperlink:
+			if o.Runs != nil {
+				for _, r := range *o.Runs {
+					runProperties(r.RunProperties)
+				}
+			}
+		}
+	}
+}
+
+// revisionPass accepts or rejects the tracked changes matched by rule
+type revisionPass struct {
+	accept bool
+	rule   RevisionRule
+	// moves are the ids of the resolved move ranges
+	moves map[int]struct{}
+}
+
+func (f *Docx) resolveRevisions(rp *revisionPass) {
+	rp.moves = make(map[int]struct{}, 8)
+	for _, items := range f.stories() {
+		*items = rp.items(*items)
+	}
+}
+
+// items resolves the paragraphs and tables in items, joining
+// the paragraphs whose mark is removed with the next ones
+func (rp *revisionPass) items(items []interface{}) []interface{} {
+	nitems := make([]interface{}, 0, len(items))
+	var joined *Paragraph
+	for _, it := range items {
+		switch o := it.(type) {
+		case *Paragraph:
+			join := rp.paragraph(o)
+			if joined != nil {
+				o.Children = append(joined.Children, o.Children...)
+				joined = nil
+			}
+			if join {
+				joined = o
+				continue
+			}
+		case *Table:
+			for _, row := range o.TableRows {
+				for _, cell := range row.TableCells {
+					cell.Items = rp.items(cell.Items)
+				}
+			}
+		}
+		if joined != nil {
+			nitems = append(nitems, joined)
+			joined = nil
+		}
+		nitems = append(nitems, it)
+	}
+	if joined != nil {
+		nitems = append(nitems, joined)
+	}
+	return nitems
+}
+
+// paragraph resolves p and tells whether its mark is removed
+func (rp *revisionPass) paragraph(p *Paragraph) (join bool) {
+	p.Children = rp.children(p.Children)
+	pp := p.Properties
+	if pp == nil {
+		return false
+	}
+	if c := pp.Change; c != nil && rp.rule(&c.Revision) {
+		if rp.accept {
+			pp.Change = nil
+		} else {
+			old := c.ParagraphProperties
+			if old == nil {
+				old = &ParagraphProperties{}
+			}
+			old.RunProperties, old.SectPr = pp.RunProperties, pp.SectPr
+			p.Properties, pp = old, old
+		}
+	}
+	pp.RunProperties = rp.runProperties(pp.RunProperties)
+	mark := pp.RunProperties
+	if mark == nil {
+		return false
+	}
+	if m := mark.Inserted; m != nil && rp.rule(&m.Revision) {
+		mark.Inserted = nil
+		join = !rp.accept
+	}
+	if m := mark.Deleted; m != nil && rp.rule(&m.Revision) {
+		mark.Deleted = nil
+		join = join || rp.accept
+	}
+	return
+}
+
+// runProperties resolves the property change in r
+func (rp *revisionPass) runProperties(r *RunProperties) *RunProperties {
+	if r == nil || r.Change == nil || !rp.rule(&r.Change.Revision) {
+		return r
+	}
+	if rp.accept {
+		r.Change = nil
+		return r
+	}
+	old := r.Change.RunProperties
+	if old == nil {
+		old = &RunProperties{}
+	}
+	old.Inserted, old.Deleted = r.Inserted, r.Deleted
+	return old
+}
+
+// children resolves the tracked changes in the children of a paragraph
+func (rp *revisionPass) children(children []interface{}) []interface{} {
+	nchildren := make([]interface{}, 0, len(children))
+	for _, c := range children {
+		switch o := c.(type) {
+		case *Insertion:
+			o.Children = rp.children(o.Children)
+			if !rp.rule(&o.Revision) {
+				break
+			}
+			if rp.accept {
+				nchildren = append(nchildren, o.Children...)
+			}
+			continue
+		case *MoveTo:
+			o.Children = rp.children(o.Children)
+			if !rp.rule(&o.Revision) {
+				break
+			}
+			if rp.accept {
+				nchildren = append(nchildren, o.Children...)
+			}
+			continue
+		case *Deletion:
+			o.Children = rp.children(o.Children)
+			if !rp.rule(&o.Revision) {
+				break
+			}
+			if !rp.accept {
+				nchildren = append(nchildren, undelete(o.Children)...)
+			}
+			continue
+		case *MoveFrom:
+			o.Children = rp.children(o.Children)
+			if !rp.rule(&o.Revision) {
+				break
+			}
+			if !rp.accept {
+				nchildren = append(nchildren, undelete(o.Children)...)
+			}
+			continue
+		case *MoveRangeStart:
+			if !rp.rule(&o.Revision) {
+				break
+			}
+			rp.moves[o.ID] = struct{}{}
+			continue
+		case *MoveRangeEnd:
+			if _, ok := rp.moves[o.ID]; ok {
+				continue
+			}
+		case *Run:
+			o.RunProperties = rp.runProperties(o.RunProperties)
+		case *Hyperlink:
+			if o.Runs != nil {
+				for _, r := range *o.Runs {
+					r.RunProperties = rp.runProperties(r.RunProperties)
+				}
+			}
+		}
+		nchildren = append(nchildren, c)
+	}
+	return nchildren
+}
+
+// undelete turns the deleted text in runs back to normal text
+func undelete(children []interface{}) []interface{} {
+	for _, c := range children {
+		r, ok := c.(*Run)
+		if !ok {
+			continue
+		}
+		if r.DelInstrText != "" {
+			r.InstrText, r.DelInstrText = r.DelInstrText, ""
+		}
+		for i, x := range r.Children {
+			if t, ok := x.(*DeletedText); ok {
+				r.Children[i] = &Text{XMLSpace: t.XMLSpace, Text: t.Text}
+			}
+		}
+	}
+	return children
+}
@@ -0,0 +1,336 @@
+/*
+   Copyright (c) 2020 gingfrederik
+   Copyright (c) 2021 Gonzalo Fernandez-Victorio
+   Copyright (c) 2021 Basement Crowd Ltd (https://www.basementcrowd.com)
+   Copyright (c) 2023 Fumiama Minamoto (源文雨)
+
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU Affero General Public License as published
+   by the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU Affero General Public License for more details.
+
+   You should have received a copy of the GNU Affero General Public License
+   along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package docx
+
+import "io/fs"
+
+// Styles returns word/styles.xml. For a new document, it is
+// loaded from the template on first call, or is empty if the
+// template has none or cannot be read.
+//
+//	this func is not thread-safe
+func (f *Docx) Styles() *Styles {
+	if f.styles != nil {
+		return f.styles
+	}
+	s := f.loadTemplateStyles()
+	s.file = f
+	s.name = "word/styles.xml"
+	hasrel := false
+	for _, r := range f.docRelation.Relationship {
+		if r.Type == REL_STYLES {
+			hasrel = true
+			break
+		}
+	}
+	if !hasrel {
+		f.addPartRelation(REL_STYLES, s.name)
+	}
+	f.styles = s
+	return s
+}
+
+// loadTemplateStyles returns the styles of template, which are parsed
+// only once, or empty styles if the template has none
+func (f *Docx) loadTemplateStyles() *Styles {
+	f.tmplStylesMu.Lock()
+	defer f.tmplStylesMu.Unlock()
+	if f.tmplStyles == nil {
+		s := f.templateStyles()
+		if s == nil {
+			s = &Styles{}
+			s.setNamespaces()
+		}
+		f.tmplStyles = s
+	}
+	return f.tmplStyles
+}
+
+// templateStyles parses word/styles.xml in template
+func (f *Docx) templateStyles() *Styles {
+	for _, name := range f.tmpfslst {
+		if name != "word/styles.xml" {
+			continue
+		}
+		var (
+			file fs.File
+			err  error
+			s    *Styles
+		)
+		switch {
+		case f.template != "":
+			file, err = TemplateXMLFS.Open("xml/" + f.template + "/" + name)
+		case f.tmplfs != nil:
+			file, err = f.tmplfs.Open(name)
+		default:
+			return nil
+		}
+		if err == nil {
+			s, err = decodeStyles(file, &ParseOptions{Logger: f.logger})
+			_ = file.Close()
+		}
+		if err != nil && f.logger != nil {
+			f.logger.Println("load styles from template:", err)
+		}
+		return s
+	}
+	return nil
+}
+
+// Style finds the style by its StyleID, or nil
+func (f *Docx) Style(id string) *StyleDefinition {
+	return f.Styles().Style(id)
+}
+
+// AddParagraphStyle adds a paragraph style based on the default one,
+// or returns the existing style of the same id
+func (f *Docx) AddParagraphStyle(id, name string) *StyleDefinition {
+	return f.Styles().add(STYLE_PARAGRAPH, id, name)
+}
+
+// AddCharacterStyle adds a character style based on the default one,
+// or returns the existing style of the same id
+func (f *Docx) AddCharacterStyle(id, name string) *StyleDefinition {
+	return f.Styles().add(STYLE_CHARACTER, id, name)
+}
+
+// AddTableStyle adds a table style based on the default one,
+// or returns the existing style of the same id
+func (f *Docx) AddTableStyle(id, name string) *StyleDefinition {
+	return f.Styles().add(STYLE_TABLE, id, name)
+}
+
+// Style finds the style by its StyleID, or nil
+func (s *Styles) Style(id string) *StyleDefinition {
+	for _, st := range s.Styles {
+		if st.StyleID == id {
+			return st
+		}
+	}
+	return nil
+}
+
+// Default finds the default style of typ like STYLE_PARAGRAPH, or nil
+func (s *Styles) Default(typ string) *StyleDefinition {
+	for _, st := range s.Styles {
+		if st.Type == typ && st.Default != "" && isOn(st.Default) {
+			return st
+		}
+	}
+	return nil
+}
+
+// Add puts def into styles, replacing the one of the same StyleID
+func (s *Styles) Add(def *StyleDefinition) *StyleDefinition {
+	for i, st := range s.Styles {
+		if st.StyleID == def.StyleID {
+			s.Styles[i] = def
+			return def
+		}
+	}
+	s.Styles = append(s.Styles, def)
+	return def
+}
+
+func (s *Styles) add(typ, id, name string) *StyleDefinition {
+	if st := s.Style(id); st != nil {
+		return st
+	}
+	st := &StyleDefinition{
+		Type:        typ,
+		CustomStyle: "1",
+		StyleID:     id,
+		Name:        &StyleName{Val: name},
+		QFormat:     &QFormat{},
+	}
+	if def := s.Default(typ); def != nil {
+		st.BasedOn = &BasedOn{Val: def.StyleID}
+	}
+	return s.Add(st)
+}
+
+// SetBasedOn sets the parent style
+func (s *StyleDefinition) SetBasedOn(id string) *StyleDefinition {
+	s.BasedOn = &BasedOn{Val: id}
+	return s
+}
+
+// SetNext sets the style of the paragraph after this one
+func (s *StyleDefinition) SetNext(id string) *StyleDefinition {
+	s.Next = &NextStyle{Val: id}
+	return s
+}
+
+// SetLink pairs a paragraph style with a character style
+func (s *StyleDefinition) SetLink(id string) *StyleDefinition {
+	s.Link = &LinkedStyle{Val: id}
+	return s
+}
+
+func (s *StyleDefinition) runProperties() *RunProperties {
+	if s.RunProperties == nil {
+		s.RunProperties = &RunProperties{}
+	}
+	return s.RunProperties
+}
+
+// Bold ...
+func (s *StyleDefinition) Bold() *StyleDefinition {
+	s.runProperties().Bold = &Bold{}
+	return s
+}
+
+// Italic ...
+func (s *StyleDefinition) Italic() *StyleDefinition {
+	s.runProperties().Italic = &Italic{}
+	return s
+}
+
+// Color allows to set style color
+func (s *StyleDefinition) Color(color string) *StyleDefinition {
+	s.runProperties().Color = &Color{Val: color}
+	return s
+}
+
+// Size allows to set style size
+func (s *StyleDefinition) Size(size string) *StyleDefinition {
+	s.runProperties().Size = &Size{Val: size}
+	return s
+}
+
+// Font sets the font of the style
+func (s *StyleDefinition) Font(ascii, hansi, hint string) *StyleDefinition {
+	s.runProperties().Fonts = &RunFonts{
+		ASCII: ascii,
+		HAnsi: hansi,
+		Hint:  hint,
+	}
+	return s
+}
+
+// Justification allows to set style's horizonal alignment
+func (s *StyleDefinition) Justification(val string) *StyleDefinition {
+	if s.ParagraphProperties == nil {
+		s.ParagraphProperties = &ParagraphProperties{}
+	}
+	s.ParagraphProperties.Justification = &Justification{Val: val}
+	return s
+}
+
+// TableBorders sets the borders of a table style on all sides and inside
+func (s *StyleDefinition) TableBorders(val string, size int, color string) *StyleDefinition {
+	if s.TableProperties == nil {
+		s.TableProperties = &WTableProperties{}
+	}
+	s.TableProperties.TableBorders = &WTableBorders{
+		Top:     &WTableBorder{Val: val, Size: size, Color: color},
+		Left:    &WTableBorder{Val: val, Size: size, Color: color},
+		Bottom:  &WTableBorder{Val: val, Size: size, Color: color},
+		Right:   &WTableBorder{Val: val, Size: size, Color: color},
+		InsideH: &WTableBorder{Val: val, Size: size, Color: color},
+		InsideV: &WTableBorder{Val: val, Size: size, Color: color},
+	}
+	return s
+}
+
+// Conditional returns the conditional formatting of a table style
+// for typ like TBLSTYLE_FIRST_ROW, adding it if there is none
+func (s *StyleDefinition) Conditional(typ string) *TableStyleProperties {
+	for _, c := range s.TableStyleProperties {
+		if c.Type == typ {
+			return c
+		}
+	}
+	c := &TableStyleProperties{Type: typ}
+	s.TableStyleProperties = append(s.TableStyleProperties, c)
+	return c
+}
+
+func (c *TableStyleProperties) runProperties() *RunProperties {
+	if c.RunProperties == nil {
+		c.RunProperties = &RunProperties{}
+	}
+	return c.RunProperties
+}
+
+// Bold ...
+func (c *TableStyleProperties) Bold() *TableStyleProperties {
+	c.runProperties().Bold = &Bold{}
+	return c
+}
+
+// Italic ...
+func (c *TableStyleProperties) Italic() *TableStyleProperties {
+	c.runProperties().Italic = &Italic{}
+	return c
+}
+
+// Color allows to set text color
+func (c *TableStyleProperties) Color(color string) *TableStyleProperties {
+	c.runProperties().Color = &Color{Val: color}
+	return c
+}
+
+// Size allows to set text size
+func (c *TableStyleProperties) Size(size string) *TableStyleProperties {
+	c.runProperties().Size = &Size{Val: size}
+	return c
+}
+
+// Justification allows to set paragraphs' horizonal alignment
+func (c *TableStyleProperties) Justification(val string) *TableStyleProperties {
+	if c.ParagraphProperties == nil {
+		c.ParagraphProperties = &ParagraphProperties{}
+	}
+	c.ParagraphProperties.Justification = &Justification{Val: val}
+	return c
+}
+
+// Shade allows to set cells' shade
+func (c *TableStyleProperties) Shade(val, color, fill string) *TableStyleProperties {
+	if c.TableCellProperties == nil {
+		c.TableCellProperties = &WTableCellProperties{}
+	}
+	c.TableCellProperties.Shade = &Shade{
+		Val:   val,
+		Color: color,
+		Fill:  fill,
+	}
+	return c
+}
+
+// Style sets the paragraph style by its StyleID
+func (p *Paragraph) Style(id string) *Paragraph {
+	if p.Properties == nil {
+		p.Properties = &ParagraphProperties{}
+	}
+	p.Properties.Style = &Style{Val: id}
+	return p
+}
+
+// Style sets the character style by its StyleID
+func (r *Run) Style(id string) *Run {
+	if r.RunProperties == nil {
+		r.RunProperties = &RunProperties{}
+	}
+	r.RunProperties.RunStyle = &RunStyle{Val: id}
+	return r
+}
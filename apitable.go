@@ -20,10 +20,49 @@
 
 package docx
 
+import (
+	"fmt"
+	"reflect"
+)
+
 // AddTable add a new table to body by col*row
 //
 // unit: twips (1/20 point)
 func (f *Docx) AddTable(row int, col int) *Table {
+	tbl := f.newTable(row, col)
+	f.Document.Body.append(tbl)
+	return tbl
+}
+
+// AddTableTwips add a new table to body by height and width
+//
+// unit: twips (1/20 point)
+func (f *Docx) AddTableTwips(rowHeights []int64, colWidths []int64) *Table {
+	tbl := f.newTableTwips(rowHeights, colWidths)
+	f.Document.Body.append(tbl)
+	return tbl
+}
+
+// AddTable adds a new table by col*row into the cell
+//
+// unit: twips (1/20 point)
+func (c *WTableCell) AddTable(row int, col int) *Table {
+	tbl := c.file.newTable(row, col)
+	c.Items = append(c.Items, tbl)
+	return tbl
+}
+
+// AddTableTwips adds a new table by height and width into the cell
+//
+// unit: twips (1/20 point)
+func (c *WTableCell) AddTableTwips(rowHeights []int64, colWidths []int64) *Table {
+	tbl := c.file.newTableTwips(rowHeights, colWidths)
+	c.Items = append(c.Items, tbl)
+	return tbl
+}
+
+// newTable makes a new table by col*row
+func (f *Docx) newTable(row int, col int) *Table {
 	trs := make([]*WTableRow, row)
 	for i := 0; i < row; i++ {
 		cells := make([]*WTableCell, col)
@@ -57,15 +96,13 @@ func (f *Docx) AddTable(row int, col int) *Table {
 		},
 		TableGrid: &WTableGrid{},
 		TableRows: trs,
+		file:      f,
 	}
-	f.Document.Body.Items = append(f.Document.Body.Items, tbl)
 	return tbl
 }
 
-// AddTableTwips add a new table to body by height and width
-//
-// unit: twips (1/20 point)
-func (f *Docx) AddTableTwips(rowHeights []int64, colWidths []int64) *Table {
+// newTableTwips makes a new table by height and width
+func (f *Docx) newTableTwips(rowHeights []int64, colWidths []int64) *Table {
 	grids := make([]*WGridCol, len(colWidths))
 	trs := make([]*WTableRow, len(rowHeights))
 	for i, w := range colWidths {
@@ -114,11 +151,60 @@ func (f *Docx) AddTableTwips(rowHeights []int64, colWidths []int64) *Table {
 			GridCols: grids,
 		},
 		TableRows: trs,
+		file:      f,
 	}
-	f.Document.Body.Items = append(f.Document.Body.Items, tbl)
 	return tbl
 }
 
+// Style sets the table style by its StyleID. The style must be in
+// Styles, like those of the template or added by AddTableStyle.
+// The borders set by AddTable are direct formatting, which hides
+// those of the style until TableProperties.TableBorders is nil.
+func (t *Table) Style(id string) *Table {
+	if t.TableProperties == nil {
+		t.TableProperties = &WTableProperties{}
+	}
+	t.TableProperties.Style = &WTableStyle{Val: id}
+	return t
+}
+
+// Look sets which conditional formatting of the table style applies
+// by flags like TBLLOOK_FIRST_ROW|TBLLOOK_NO_VBAND
+func (t *Table) Look(flags int) *Table {
+	if t.TableProperties == nil {
+		t.TableProperties = &WTableProperties{}
+	}
+	on := func(f int) int {
+		if flags&f != 0 {
+			return 1
+		}
+		return 0
+	}
+	t.TableProperties.Look = &WTableLook{
+		Val:      fmt.Sprintf("%04X", flags),
+		FirstRow: on(TBLLOOK_FIRST_ROW),
+		LastRow:  on(TBLLOOK_LAST_ROW),
+		FirstCol: on(TBLLOOK_FIRST_COL),
+		LastCol:  on(TBLLOOK_LAST_COL),
+		NoHBand:  on(TBLLOOK_NO_HBAND),
+		NoVBand:  on(TBLLOOK_NO_VBAND),
+	}
+	return t
+}
+
+// HeaderRows makes the first n rows, and only them,
+// repeat at the top of each page the table is on
+func (t *Table) HeaderRows(n int) *Table {
+	for i, w := range t.TableRows {
+		if i < n {
+			w.RepeatHeader()
+		} else if w.TableRowProperties != nil {
+			w.TableRowProperties.TableHeader = nil
+		}
+	}
+	return t
+}
+
 // Justification allows to set table's horizonal alignment
 //
 //	w:jc 属性的取值可以是以下之一：
@@ -145,7 +231,7 @@ func (t *Table) Justification(val string) *Table {
 //		both：两端对齐。
 //		distribute：分散对齐。
 func (w *WTableRow) Justification(val string) *WTableRow {
-	if w.TableRowProperties.Justification == nil {
+	if w.properties().Justification == nil {
 		w.TableRowProperties.Justification = &Justification{Val: val}
 		return w
 	}
@@ -153,12 +239,174 @@ func (w *WTableRow) Justification(val string) *WTableRow {
 	return w
 }
 
+// RepeatHeader makes the row repeat at the top of each page the table is on.
+// It works only if all of the rows above are header rows too.
+func (w *WTableRow) RepeatHeader() *WTableRow {
+	w.properties().TableHeader = &WTableHeader{}
+	return w
+}
+
+// CantSplit keeps the row from breaking across pages
+func (w *WTableRow) CantSplit() *WTableRow {
+	w.properties().CantSplit = &WCantSplit{}
+	return w
+}
+
+// CellSpacing sets the spacing between the cells of the row
+//
+// unit: twips (1/20 point)
+func (w *WTableRow) CellSpacing(width int64) *WTableRow {
+	w.properties().TableCellSpacing = &WTableCellSpacing{W: width, Type: "dxa"}
+	return w
+}
+
+func (w *WTableRow) properties() *WTableRowProperties {
+	if w.TableRowProperties == nil {
+		w.TableRowProperties = &WTableRowProperties{}
+	}
+	return w.TableRowProperties
+}
+
 // Shade allows to set cell's shade
 func (c *WTableCell) Shade(val, color, fill string) *WTableCell {
-	c.TableCellProperties.Shade = &Shade{
+	c.properties().Shade = &Shade{
 		Val:   val,
 		Color: color,
 		Fill:  fill,
 	}
 	return c
 }
+
+// Borders sets the borders of the cell on all four sides,
+// overriding those of the table
+//
+//	val is like single, double, dashed or nil; size is in 1/8 point
+func (c *WTableCell) Borders(val string, size int, color string) *WTableCell {
+	for _, side := range []string{"top", "left", "bottom", "right"} {
+		c.Border(side, val, size, color)
+	}
+	return c
+}
+
+// Border sets the border of the cell on side top, left, bottom, right,
+// insideH or insideV, overriding that of the table. Other sides are ignored.
+//
+//	val is like single, double, dashed or nil; size is in 1/8 point
+func (c *WTableCell) Border(side, val string, size int, color string) *WTableCell {
+	borders := &WTableBorders{}
+	if c.TableCellProperties != nil && c.TableCellProperties.TableBorders != nil {
+		borders = c.TableCellProperties.TableBorders
+	}
+	var b **WTableBorder
+	switch side {
+	case "top":
+		b = &borders.Top
+	case "left":
+		b = &borders.Left
+	case "bottom":
+		b = &borders.Bottom
+	case "right":
+		b = &borders.Right
+	case "insideH":
+		b = &borders.InsideH
+	case "insideV":
+		b = &borders.InsideV
+	default:
+		return c
+	}
+	*b = &WTableBorder{Val: val, Size: size, Color: color}
+	c.properties().TableBorders = borders
+	return c
+}
+
+// Margins sets the space between the borders and the content of the cell
+//
+// unit: twips (1/20 point)
+func (c *WTableCell) Margins(top, left, bottom, right int64) *WTableCell {
+	c.properties().Margins = &WTableCellMargins{
+		Top:    &WTableCellMargin{W: top, Type: "dxa"},
+		Left:   &WTableCellMargin{W: left, Type: "dxa"},
+		Bottom: &WTableCellMargin{W: bottom, Type: "dxa"},
+		Right:  &WTableCellMargin{W: right, Type: "dxa"},
+	}
+	return c
+}
+
+// TextDirection sets the direction of the text like TEXTDIR_TB_RL
+func (c *WTableCell) TextDirection(val string) *WTableCell {
+	c.properties().TextDirection = &WTextDirection{Val: val}
+	return c
+}
+
+// NoWrap keeps the text of the cell from wrapping
+// when the table lays out its columns
+func (c *WTableCell) NoWrap() *WTableCell {
+	c.properties().NoWrap = &WNoWrap{}
+	return c
+}
+
+// FitText shrinks or stretches the text of the cell to fit its width
+func (c *WTableCell) FitText() *WTableCell {
+	c.properties().FitText = &WFitText{}
+	return c
+}
+
+// VAlign sets the vertical alignment of the content: top, center or bottom
+func (c *WTableCell) VAlign(val string) *WTableCell {
+	c.properties().VAlign = &WVerticalAlignment{Val: val}
+	return c
+}
+
+func (c *WTableCell) properties() *WTableCellProperties {
+	if c.TableCellProperties == nil {
+		c.TableCellProperties = &WTableCellProperties{}
+	}
+	return c.TableCellProperties
+}
+
+// Cell returns the cell shown at row and grid column col with its place
+// in t, or nil if there is none. A merged cell is returned for each row
+// and column it covers, so a vertical merge gives its first cell, and the
+// Path of the match is t and the row the cell is in.
+func (t *Table) Cell(row, col int) *Match {
+	row, i, _ := t.mergeOrigin(row, col)
+	if i < 0 {
+		return nil
+	}
+	r := t.TableRows[row]
+	return &Match{
+		Node:     r.TableCells[i],
+		Path:     WalkPath{t, r},
+		Index:    i,
+		siblings: reflect.ValueOf(&r.TableCells).Elem(),
+	}
+}
+
+// gridCell returns the cell in row covering grid column col, or nil if
+// there is none. Unlike Cell, it does not follow vertical merges.
+func (t *Table) gridCell(row, col int) *WTableCell {
+	i, _ := t.cellIndex(row, col)
+	if i < 0 {
+		return nil
+	}
+	return t.TableRows[row].TableCells[i]
+}
+
+// gridSpanOf returns the number of grid columns c spans
+func gridSpanOf(c *WTableCell) int {
+	if c.TableCellProperties != nil && c.TableCellProperties.GridSpan != nil && c.TableCellProperties.GridSpan.Val > 1 {
+		return c.TableCellProperties.GridSpan.Val
+	}
+	return 1
+}
+
+// vMergeOf returns restart, continue or "" if c is not merged vertically
+func vMergeOf(c *WTableCell) string {
+	if c.TableCellProperties == nil || c.TableCellProperties.VMerge == nil {
+		return ""
+	}
+	if c.TableCellProperties.VMerge.Val == "restart" {
+		return "restart"
+	}
+	return "continue"
+}
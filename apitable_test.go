@@ -0,0 +1,149 @@
+/*
+   Copyright (c) 2020 gingfrederik
+   Copyright (c) 2021 Gonzalo Fernandez-Victorio
+   Copyright (c) 2021 Basement Crowd Ltd (https://www.basementcrowd.com)
+   Copyright (c) 2023 Fumiama Minamoto (源文雨)
+
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU Affero General Public License as published
+   by the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU Affero General Public License for more details.
+
+   You should have received a copy of the GNU Affero General Public License
+   along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package docx
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestTableStyle(t *testing.T) {
+	w := NewA4()
+	ts := w.AddTableStyle("Report", "Report").TableBorders("single", 4, "4472C4")
+	ts.Conditional(TBLSTYLE_FIRST_ROW).Bold().Color("FFFFFF").Shade("clear", "auto", "4472C4")
+	ts.Conditional(TBLSTYLE_BAND1_HORZ).Shade("clear", "auto", "D9E2F3")
+	if ts.Conditional(TBLSTYLE_FIRST_ROW).RunProperties.Bold == nil || len(ts.TableStyleProperties) != 2 {
+		t.Fatal("conditional formatting not reused")
+	}
+
+	tbl := w.AddTable(2, 2).Style("Report").Look(TBLLOOK_FIRST_ROW | TBLLOOK_NO_VBAND).HeaderRows(1)
+	tbl.TableProperties.TableBorders = nil
+	if l := tbl.TableProperties.Look; l.Val != "0420" || l.FirstRow != 1 || l.NoVBand != 1 || l.NoHBand != 0 {
+		t.Fatal("unexpected look", l)
+	}
+	tbl.TableRows[1].CantSplit().CellSpacing(20)
+	for i := 0; i < 3; i++ {
+		tbl.AppendRow().TableCells[0].AddParagraph().AddText("data")
+	}
+	for i, row := range tbl.TableRows {
+		if isHeaderRow(row) != (i == 0) {
+			t.Fatal("unexpected header row", i)
+		}
+		if i > 0 && row.TableRowProperties.CantSplit == nil {
+			t.Fatal("cantSplit not copied")
+		}
+	}
+	for row := 0; row < 3; row++ {
+		p := tbl.TableRows[row].TableCells[1].AddParagraph()
+		rp := w.EffectiveRunProperties(p, p.AddText("cell"), &CellLocation{Table: tbl, Row: row, Col: 1})
+		if (rp.Bold != nil) != (row == 0) || (rp.Color != nil) != (row == 0) {
+			t.Fatal("unexpected properties at row", row, rp)
+		}
+	}
+
+	var buf bytes.Buffer
+	_, err := w.WriteTo(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := Parse(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tbl = doc.Document.Body.Items[0].(*Table)
+	if tbl.TableProperties.Style.Val != "Report" || tbl.look() != TBLLOOK_FIRST_ROW|TBLLOOK_NO_VBAND {
+		t.Fatal("style lost")
+	}
+	rp := tbl.TableRows[1].TableRowProperties
+	if !isHeaderRow(tbl.TableRows[0]) || isHeaderRow(tbl.TableRows[1]) || rp.CantSplit == nil || rp.TableCellSpacing.W != 20 {
+		t.Fatal("row properties lost")
+	}
+	st := doc.Style("Report")
+	if st == nil || len(st.TableStyleProperties) != 2 || st.TableStyleProperties[0].TableCellProperties.Shade.Fill != "4472C4" {
+		t.Fatal("table style lost")
+	}
+
+	var sb strings.Builder
+	if err := doc.WriteHTML(&sb, HTMLOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	s := sb.String()
+	if strings.Count(s, "<thead>") != 1 || strings.Index(s, "</thead>\n<tbody>\n<tr") < 0 || strings.Count(s, "<tr") != 5 {
+		t.Fatal("unexpected html\n" + s)
+	}
+	doc, err = FromHTML(strings.NewReader(s), FromHTMLOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tbl = doc.Document.Body.Items[0].(*Table)
+	if len(tbl.TableRows) != 5 || !isHeaderRow(tbl.TableRows[0]) || isHeaderRow(tbl.TableRows[1]) {
+		t.Fatal("header row not read from html")
+	}
+}
+
+func TestTableCellProperties(t *testing.T) {
+	w := NewA4()
+	tbl := w.AddTable(2, 2)
+	head := tbl.TableRows[0].TableCells[0]
+	head.AddParagraph().AddText("項目")
+	head.Borders("single", 12, "FF0000").Border("bottom", "double", 4, "0000FF").
+		Margins(40, 80, 40, 80).TextDirection(TEXTDIR_TB_RL).VAlign("center").NoWrap().FitText()
+	tbl.TableRows[0].TableCells[1].Shade("clear", "auto", "EEEEEE")
+	tbl.TableRows[1].TableCells[0].Border("tl2br", "single", 4, "000000")
+	if cp := tbl.TableRows[1].TableCells[0].TableCellProperties; cp != nil && cp.TableBorders != nil {
+		t.Fatal("borders made for an unknown side")
+	}
+	tbl.TableRows[1].TableCells[1].Border("insideV", "dashed", 4, "000000")
+
+	var buf bytes.Buffer
+	_, err := w.WriteTo(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := Parse(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cp := doc.Document.Body.Items[0].(*Table).TableRows[0].TableCells[0].TableCellProperties
+	if b := cp.TableBorders; b == nil || b.Top.Val != "single" || b.Top.Size != 12 || b.Bottom.Val != "double" || b.Bottom.Color != "0000FF" || b.InsideH != nil {
+		t.Fatal("unexpected borders", b)
+	}
+	if b := doc.Document.Body.Items[0].(*Table).TableRows[1].TableCells[1].TableCellProperties.TableBorders; b == nil || b.InsideV == nil || b.InsideV.Val != "dashed" {
+		t.Fatal("unexpected inside borders", b)
+	}
+	if m := cp.Margins; m == nil || m.Top.W != 40 || m.Left.W != 80 || m.Right.Type != "dxa" {
+		t.Fatal("unexpected margins", m)
+	}
+	if cp.TextDirection == nil || cp.TextDirection.Val != TEXTDIR_TB_RL || cp.NoWrap == nil || cp.FitText == nil || cp.VAlign.Val != "center" {
+		t.Fatal("cell properties lost")
+	}
+
+	var sb strings.Builder
+	if err := doc.WriteHTML(&sb, HTMLOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	for _, css := range []string{"border-bottom:0.5pt double #0000FF", "padding-left:4pt", "white-space:nowrap", "writing-mode:vertical-rl", "vertical-align:middle"} {
+		if !strings.Contains(sb.String(), css) {
+			t.Fatal("missing "+css+" in\n", sb.String())
+		}
+	}
+}
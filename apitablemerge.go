@@ -0,0 +1,295 @@
+/*
+   Copyright (c) 2020 gingfrederik
+   Copyright (c) 2021 Gonzalo Fernandez-Victorio
+   Copyright (c) 2021 Basement Crowd Ltd (https://www.basementcrowd.com)
+   Copyright (c) 2023 Fumiama Minamoto (源文雨)
+
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU Affero General Public License as published
+   by the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU Affero General Public License for more details.
+
+   You should have received a copy of the GNU Affero General Public License
+   along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package docx
+
+import "errors"
+
+var (
+	// ErrCellOutOfTable is returned if a row or column is outside of the table
+	ErrCellOutOfTable = errors.New("cell out of table")
+	// ErrCutMergedCell is returned if a range only covers part of a merged cell
+	ErrCutMergedCell = errors.New("range cuts through merged cell")
+)
+
+// MergeCells merges the cells from row r1 and grid column c1 to row r2 and
+// grid column c2 into one, keeping the content of the others which is not
+// an empty paragraph in it. The range must cover all of the merged cells in it.
+func (t *Table) MergeCells(r1, c1, r2, c2 int) error {
+	if r1 > r2 {
+		r1, r2 = r2, r1
+	}
+	if c1 > c2 {
+		c1, c2 = c2, c1
+	}
+	if r1 < 0 || r2 >= len(t.TableRows) || c1 < 0 {
+		return ErrCellOutOfTable
+	}
+	first := make([]int, r2-r1+1)
+	last := make([]int, r2-r1+1)
+	for r := r1; r <= r2; r++ {
+		i, start := t.cellIndex(r, c1)
+		j, end := t.cellIndex(r, c2)
+		if i < 0 || j < 0 {
+			return ErrCellOutOfTable
+		}
+		if start != c1 || end+gridSpanOf(t.TableRows[r].TableCells[j]) != c2+1 {
+			return ErrCutMergedCell
+		}
+		first[r-r1], last[r-r1] = i, j
+	}
+	if r1 > 0 {
+		for _, c := range t.TableRows[r1].TableCells[first[0] : last[0]+1] {
+			if vMergeOf(c) == "continue" {
+				return ErrCutMergedCell
+			}
+		}
+	}
+	if r2+1 < len(t.TableRows) {
+		col := 0
+		for _, c := range t.TableRows[r2+1].TableCells {
+			if col >= c1 && col <= c2 && vMergeOf(c) == "continue" {
+				return ErrCutMergedCell
+			}
+			col += gridSpanOf(c)
+		}
+	}
+
+	t.fixGrid()
+	width, ok := t.gridWidth(c1, c2)
+	if !ok {
+		width, ok = cellsWidth(t.TableRows[r1].TableCells[first[0] : last[0]+1])
+	}
+	top := t.TableRows[r1].TableCells[first[0]]
+	for r := r1; r <= r2; r++ {
+		for _, c := range t.TableRows[r].TableCells[first[r-r1] : last[r-r1]+1] {
+			if c == top {
+				continue
+			}
+			for _, it := range c.Items {
+				if p, ok := it.(*Paragraph); !ok || len(p.Children) > 0 {
+					top.Items = append(top.Items, it)
+				}
+			}
+		}
+	}
+	for r := r1; r <= r2; r++ {
+		row := t.TableRows[r]
+		c := row.TableCells[first[r-r1]]
+		if c.TableCellProperties == nil {
+			c.TableCellProperties = &WTableCellProperties{}
+		}
+		props := c.TableCellProperties
+		props.GridSpan, props.VMerge = nil, nil
+		if c2 > c1 {
+			props.GridSpan = &WGridSpan{Val: c2 - c1 + 1}
+		}
+		if r2 > r1 {
+			props.VMerge = &WvMerge{}
+			if r == r1 {
+				props.VMerge.Val = "restart"
+			}
+		}
+		if ok {
+			props.TableCellWidth = &WTableCellWidth{W: width, Type: "dxa"}
+		}
+		if c != top {
+			c.Items = nil
+			c.AddParagraph()
+		}
+		row.TableCells = append(row.TableCells[:first[r-r1]+1], row.TableCells[last[r-r1]+1:]...)
+	}
+	return nil
+}
+
+// Unmerge splits the merged cell shown at row and grid column col
+// back into one cell for each grid column and row it covers.
+// The content stays in the first of them.
+func (t *Table) Unmerge(row, col int) error {
+	row, i, start := t.mergeOrigin(row, col)
+	if i < 0 {
+		return ErrCellOutOfTable
+	}
+	t.fixGrid()
+	for r := row; r < len(t.TableRows); r++ {
+		if r > row {
+			j, s := t.cellIndex(r, start)
+			if j < 0 || s != start || vMergeOf(t.TableRows[r].TableCells[j]) != "continue" {
+				break
+			}
+			i = j
+		}
+		cells := t.TableRows[r].TableCells
+		c := cells[i]
+		span := gridSpanOf(c)
+		if c.TableCellProperties == nil {
+			c.TableCellProperties = &WTableCellProperties{}
+		}
+		c.TableCellProperties.GridSpan, c.TableCellProperties.VMerge = nil, nil
+		if len(c.Items) == 0 {
+			c.AddParagraph()
+		}
+		if span == 1 {
+			continue
+		}
+		widths := make([]*WTableCellWidth, span)
+		for k := range widths {
+			if w, ok := t.gridWidth(start+k, start+k); ok {
+				widths[k] = &WTableCellWidth{W: w, Type: "dxa"}
+			} else if cw := c.TableCellProperties.TableCellWidth; cw != nil && cw.Type == "dxa" {
+				widths[k] = &WTableCellWidth{W: cw.W / int64(span), Type: "dxa"}
+			} else {
+				widths[k] = &WTableCellWidth{Type: "auto"}
+			}
+		}
+		split := make([]*WTableCell, span-1)
+		for k := range split {
+			props := newCloner(t.file, nil).clone(c.TableCellProperties).(*WTableCellProperties)
+			props.TableCellWidth = widths[k+1]
+			split[k] = &WTableCell{TableCellProperties: props, file: t.file}
+			split[k].AddParagraph()
+		}
+		c.TableCellProperties.TableCellWidth = widths[0]
+		rest := append(split, cells[i+1:]...)
+		t.TableRows[r].TableCells = append(cells[:i+1], rest...)
+	}
+	return nil
+}
+
+// mergeOrigin returns the row and the index in it of the first cell of the merge
+// shown at row and grid column col with the grid column it starts at,
+// or -1 as the index if there is none
+func (t *Table) mergeOrigin(row, col int) (int, int, int) {
+	i, start := t.cellIndex(row, col)
+	if i < 0 {
+		return row, -1, 0
+	}
+	for row > 0 && vMergeOf(t.TableRows[row].TableCells[i]) == "continue" {
+		j, s := t.cellIndex(row-1, start)
+		if j < 0 || s != start {
+			break
+		}
+		row, i = row-1, j
+	}
+	return row, i, start
+}
+
+// cellIndex returns the index in row of the cell covering grid column col
+// and the grid column it starts at, or -1 as the index if there is none
+func (t *Table) cellIndex(row, col int) (int, int) {
+	if row < 0 || row >= len(t.TableRows) || col < 0 {
+		return -1, 0
+	}
+	start := 0
+	for i, c := range t.TableRows[row].TableCells {
+		span := gridSpanOf(c)
+		if col < start+span {
+			return i, start
+		}
+		start += span
+	}
+	return -1, 0
+}
+
+// columns returns the number of grid columns of t
+func (t *Table) columns() int {
+	cols := 0
+	if t.TableGrid != nil {
+		cols = len(t.TableGrid.GridCols)
+	}
+	for _, row := range t.TableRows {
+		n := 0
+		for _, c := range row.TableCells {
+			n += gridSpanOf(c)
+		}
+		if n > cols {
+			cols = n
+		}
+	}
+	return cols
+}
+
+// fixGrid makes TableGrid have a width for each grid column, taken from
+// the cells just covering it, or shared from the text width if there are none
+func (t *Table) fixGrid() {
+	if t.TableGrid == nil {
+		t.TableGrid = &WTableGrid{}
+	}
+	cols := t.columns()
+	for len(t.TableGrid.GridCols) < cols {
+		t.TableGrid.GridCols = append(t.TableGrid.GridCols, nil)
+	}
+	for col, g := range t.TableGrid.GridCols {
+		if g != nil && g.W > 0 {
+			continue
+		}
+		var w int64
+		for r := range t.TableRows {
+			i, _ := t.cellIndex(r, col)
+			if i < 0 {
+				continue
+			}
+			c := t.TableRows[r].TableCells[i]
+			if cw, ok := cellsWidth([]*WTableCell{c}); ok && gridSpanOf(c) == 1 {
+				w = cw
+				break
+			}
+		}
+		if w == 0 {
+			width := int64(8306)
+			if t.file != nil {
+				width = t.file.textWidth()
+			}
+			w = width / int64(cols)
+		}
+		t.TableGrid.GridCols[col] = &WGridCol{W: w}
+	}
+}
+
+// gridWidth returns the sum of the widths of grid columns c1 to c2
+func (t *Table) gridWidth(c1, c2 int) (int64, bool) {
+	if t.TableGrid == nil || c2 >= len(t.TableGrid.GridCols) {
+		return 0, false
+	}
+	var w int64
+	for _, g := range t.TableGrid.GridCols[c1 : c2+1] {
+		if g == nil || g.W <= 0 {
+			return 0, false
+		}
+		w += g.W
+	}
+	return w, true
+}
+
+// cellsWidth returns the sum of the widths of cells if all are in twips
+func cellsWidth(cells []*WTableCell) (int64, bool) {
+	var w int64
+	for _, c := range cells {
+		if c.TableCellProperties == nil || c.TableCellProperties.TableCellWidth == nil {
+			return 0, false
+		}
+		cw := c.TableCellProperties.TableCellWidth
+		if cw.Type != "dxa" || cw.W <= 0 {
+			return 0, false
+		}
+		w += cw.W
+	}
+	return w, true
+}
@@ -0,0 +1,121 @@
+/*
+   Copyright (c) 2020 gingfrederik
+   Copyright (c) 2021 Gonzalo Fernandez-Victorio
+   Copyright (c) 2021 Basement Crowd Ltd (https://www.basementcrowd.com)
+   Copyright (c) 2023 Fumiama Minamoto (源文雨)
+
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU Affero General Public License as published
+   by the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU Affero General Public License for more details.
+
+   You should have received a copy of the GNU Affero General Public License
+   along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package docx
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strconv"
+	"testing"
+)
+
+func TestMergeCells(t *testing.T) {
+	doc := NewA4()
+	tbl := doc.AddTable(3, 4)
+	for r, row := range tbl.TableRows {
+		for c, cell := range row.TableCells {
+			cell.AddParagraph().AddText(strconv.Itoa(r) + strconv.Itoa(c))
+		}
+	}
+
+	if err := tbl.MergeCells(1, 1, 0, 0); err != nil {
+		t.Fatal(err)
+	}
+	top := tbl.TableRows[0].TableCells[0]
+	if len(tbl.TableRows[0].TableCells) != 3 || len(tbl.TableRows[1].TableCells) != 3 || len(tbl.TableRows[2].TableCells) != 4 {
+		t.Fatal("unexpected cells after merging")
+	}
+	if tbl.Cell(1, 1).Node != top || tbl.Cell(0, 1).Node != top || tbl.gridCell(1, 1) == top || tbl.Cell(1, 2).Node != tbl.TableRows[1].TableCells[1] {
+		t.Fatal("unexpected cell at merged position")
+	}
+	if gridSpanOf(top) != 2 || vMergeOf(top) != "restart" || vMergeOf(tbl.TableRows[1].TableCells[0]) != "continue" {
+		t.Fatal("unexpected merge properties")
+	}
+	if s := PlainText(top); s != "00\n01\n10\n11" {
+		t.Fatal("unexpected merged text", s)
+	}
+	if len(tbl.TableGrid.GridCols) != 4 || top.TableCellProperties.TableCellWidth.W != 2*tbl.TableGrid.GridCols[0].W {
+		t.Fatal("unexpected grid")
+	}
+	if s := tbl.String(); s != "|  :----: | :----: | :----: | :----: |\n| 00 |        | 02 | 03 |\n|        |        | 12 | 13 |\n| 20 | 21 | 22 | 23 |" {
+		t.Fatal("unexpected string\n" + s)
+	}
+
+	if err := tbl.MergeCells(1, 1, 2, 2); err != ErrCutMergedCell {
+		t.Fatal("expected ErrCutMergedCell, got", err)
+	}
+	if err := tbl.MergeCells(2, 0, 2, 4); err != ErrCellOutOfTable {
+		t.Fatal("expected ErrCellOutOfTable, got", err)
+	}
+	if err := tbl.MergeCells(0, 0, 2, 1); err != nil {
+		t.Fatal(err)
+	}
+	if vMergeOf(tbl.TableRows[2].TableCells[0]) != "continue" || tbl.Cell(2, 1).Node != top {
+		t.Fatal("merge not extended")
+	}
+
+	top.Borders("single", 4, "000000").Shade("clear", "auto", "EEEEEE").NoWrap().
+		Margins(0, 0, 0, 0).TextDirection(TEXTDIR_TB_RL).FitText().VAlign("center")
+	tcPr, err := xml.Marshal(top.TableCellProperties)
+	if err != nil {
+		t.Fatal(err)
+	}
+	last := -1
+	for _, tag := range []string{"<w:tcW", "<w:gridSpan", "<w:vMerge", "<w:tcBorders", "<w:shd",
+		"<w:noWrap", "<w:tcMar", "<w:textDirection", "<w:tcFitText", "<w:vAlign"} {
+		i := bytes.Index(tcPr, []byte(tag))
+		if i <= last {
+			t.Fatal("unexpected order of", tag, "in", string(tcPr))
+		}
+		last = i
+	}
+
+	var buf bytes.Buffer
+	_, err = doc.WriteTo(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err = Parse(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tbl = doc.Document.Body.Items[0].(*Table)
+	if PlainText(tbl.Cell(2, 1).Node) != "00\n01\n10\n11\n20\n21" {
+		t.Fatal("unexpected cell of parsed document")
+	}
+
+	if err := tbl.Unmerge(2, 1); err != nil {
+		t.Fatal(err)
+	}
+	for r, row := range tbl.TableRows {
+		if len(row.TableCells) != 4 {
+			t.Fatal("row", r, "not split")
+		}
+		for _, c := range row.TableCells {
+			if gridSpanOf(c) != 1 || vMergeOf(c) != "" || len(c.Items) == 0 {
+				t.Fatal("cell not unmerged in row", r)
+			}
+		}
+	}
+	if PlainText(tbl.Cell(2, 1).Node) != "" || PlainText(tbl.Cell(0, 0).Node) != "00\n01\n10\n11\n20\n21" {
+		t.Fatal("unexpected text after unmerging")
+	}
+}
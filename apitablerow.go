@@ -0,0 +1,269 @@
+/*
+   Copyright (c) 2020 gingfrederik
+   Copyright (c) 2021 Gonzalo Fernandez-Victorio
+   Copyright (c) 2021 Basement Crowd Ltd (https://www.basementcrowd.com)
+   Copyright (c) 2023 Fumiama Minamoto (源文雨)
+
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU Affero General Public License as published
+   by the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU Affero General Public License for more details.
+
+   You should have received a copy of the GNU Affero General Public License
+   along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package docx
+
+// AppendRow adds a new empty row at the end of the table
+// shaped like the last one, and returns it.
+func (t *Table) AppendRow() *WTableRow {
+	return t.InsertRowAt(len(t.TableRows))
+}
+
+// InsertRowAt inserts a new empty row at index i, or at the end if i is
+// out of range, shaped like the row it pushes down or the last one, and
+// returns it. Inside a vertical merge, the new cells continue the merge.
+func (t *Table) InsertRowAt(i int) *WTableRow {
+	if i < 0 || i > len(t.TableRows) {
+		i = len(t.TableRows)
+	}
+	var row *WTableRow
+	switch {
+	case i < len(t.TableRows):
+		row = t.newRowLike(t.TableRows[i], true)
+	case i > 0:
+		row = t.newRowLike(t.TableRows[i-1], false)
+	default:
+		t.fixGrid()
+		row = &WTableRow{TableRowProperties: &WTableRowProperties{}, file: t.file}
+		for _, g := range t.TableGrid.GridCols {
+			c := &WTableCell{
+				TableCellProperties: &WTableCellProperties{
+					TableCellWidth: &WTableCellWidth{W: g.W, Type: "dxa"},
+				},
+				file: t.file,
+			}
+			c.AddParagraph()
+			row.TableCells = append(row.TableCells, c)
+		}
+	}
+	t.insertRow(i, row)
+	return row
+}
+
+// CloneRow inserts a deep copy of the row at index i after it and returns
+// it, or nil if i is out of range. The copies of vertically merged cells
+// continue their merge and are left empty.
+func (t *Table) CloneRow(i int) *WTableRow {
+	if i < 0 || i >= len(t.TableRows) {
+		return nil
+	}
+	row := newCloner(t.file, nil).clone(t.TableRows[i]).(*WTableRow)
+	for _, c := range row.TableCells {
+		if vMergeOf(c) == "" {
+			continue
+		}
+		c.TableCellProperties.VMerge = &WvMerge{}
+		p := c.firstParagraph()
+		c.Items = nil
+		np := c.AddParagraph()
+		if p != nil {
+			np.Properties = p.Properties
+		}
+	}
+	t.insertRow(i+1, row)
+	return row
+}
+
+// DeleteRow removes the row at index i. The content of the vertical
+// merges starting in it moves to their next row.
+func (t *Table) DeleteRow(i int) error {
+	if i < 0 || i >= len(t.TableRows) {
+		return ErrCellOutOfTable
+	}
+	start := 0
+	for _, c := range t.TableRows[i].TableCells {
+		span := gridSpanOf(c)
+		if vMergeOf(c) == "restart" {
+			j, s := t.cellIndex(i+1, start)
+			if j >= 0 && s == start && vMergeOf(t.TableRows[i+1].TableCells[j]) == "continue" {
+				t.TableRows[i+1].TableCells[j].Items = c.Items
+			}
+		}
+		start += span
+	}
+	t.TableRows = append(t.TableRows[:i], t.TableRows[i+1:]...)
+	t.fixMerges()
+	return nil
+}
+
+// InsertColumnAt inserts a new grid column of width twips at grid column i,
+// or at the end if i is out of range. A cell spanning over i gets wider,
+// other rows get a new empty cell shaped like the one they push right.
+// A width of 0 takes the average width of the existing columns.
+func (t *Table) InsertColumnAt(i int, width int64) {
+	t.fixGrid()
+	cols := len(t.TableGrid.GridCols)
+	if i < 0 || i > cols {
+		i = cols
+	}
+	if width <= 0 {
+		if w, ok := t.gridWidth(0, cols-1); ok && cols > 0 {
+			width = w / int64(cols)
+		} else if t.file != nil {
+			width = t.file.textWidth() / int64(cols+1)
+		} else {
+			width = 8306 / int64(cols+1)
+		}
+	}
+	for r, row := range t.TableRows {
+		j, start := t.cellIndex(r, i)
+		if j >= 0 && start < i {
+			props := row.TableCells[j].TableCellProperties
+			props.GridSpan = &WGridSpan{Val: gridSpanOf(row.TableCells[j]) + 1}
+			if cw := props.TableCellWidth; cw != nil && cw.Type == "dxa" {
+				cw.W += width
+			}
+			continue
+		}
+		if j < 0 {
+			j = len(row.TableCells)
+		}
+		var c *WTableCell
+		switch {
+		case j < len(row.TableCells):
+			c = t.newCellLike(row.TableCells[j])
+		case j > 0:
+			c = t.newCellLike(row.TableCells[j-1])
+		default:
+			c = t.newCellLike(&WTableCell{})
+		}
+		c.TableCellProperties.GridSpan = nil
+		c.TableCellProperties.VMerge = nil
+		c.TableCellProperties.TableCellWidth = &WTableCellWidth{W: width, Type: "dxa"}
+		row.TableCells = append(row.TableCells[:j], append([]*WTableCell{c}, row.TableCells[j:]...)...)
+	}
+	grids := t.TableGrid.GridCols
+	t.TableGrid.GridCols = append(grids[:i], append([]*WGridCol{{W: width}}, grids[i:]...)...)
+	if t.TableProperties != nil && t.TableProperties.Width != nil && t.TableProperties.Width.Type == "dxa" {
+		t.TableProperties.Width.W += width
+	}
+}
+
+// DeleteColumn removes grid column i. A cell spanning over it gets
+// narrower, other cells in it are removed with their content,
+// and so are the rows left without cells.
+func (t *Table) DeleteColumn(i int) error {
+	t.fixGrid()
+	if i < 0 || i >= len(t.TableGrid.GridCols) {
+		return ErrCellOutOfTable
+	}
+	width := t.TableGrid.GridCols[i].W
+	rows := t.TableRows[:0]
+	for r, row := range t.TableRows {
+		j, _ := t.cellIndex(r, i)
+		if j >= 0 {
+			c := row.TableCells[j]
+			span := gridSpanOf(c)
+			if span > 1 {
+				c.TableCellProperties.GridSpan = nil
+				if span > 2 {
+					c.TableCellProperties.GridSpan = &WGridSpan{Val: span - 1}
+				}
+				if cw := c.TableCellProperties.TableCellWidth; cw != nil && cw.Type == "dxa" && cw.W > width {
+					cw.W -= width
+				}
+			} else {
+				row.TableCells = append(row.TableCells[:j], row.TableCells[j+1:]...)
+			}
+		}
+		if len(row.TableCells) > 0 {
+			rows = append(rows, row)
+		}
+	}
+	t.TableRows = rows
+	t.TableGrid.GridCols = append(t.TableGrid.GridCols[:i], t.TableGrid.GridCols[i+1:]...)
+	if t.TableProperties != nil && t.TableProperties.Width != nil && t.TableProperties.Width.Type == "dxa" && t.TableProperties.Width.W > width {
+		t.TableProperties.Width.W -= width
+	}
+	return nil
+}
+
+// insertRow puts row at index i of the table
+func (t *Table) insertRow(i int, row *WTableRow) {
+	t.TableRows = append(t.TableRows, nil)
+	copy(t.TableRows[i+1:], t.TableRows[i:])
+	t.TableRows[i] = row
+}
+
+// newRowLike makes an empty row with the properties and cells of row.
+// The new row is a header row and its cells continue the vertical
+// merges of row only if inside is set.
+func (t *Table) newRowLike(row *WTableRow, inside bool) *WTableRow {
+	nr := &WTableRow{file: t.file}
+	if row.TableRowProperties != nil {
+		nr.TableRowProperties = newCloner(t.file, nil).clone(row.TableRowProperties).(*WTableRowProperties)
+		if !inside {
+			nr.TableRowProperties.TableHeader = nil
+		}
+	}
+	nr.TableCells = make([]*WTableCell, len(row.TableCells))
+	for k, c := range row.TableCells {
+		nr.TableCells[k] = t.newCellLike(c)
+		if !inside || vMergeOf(c) != "continue" {
+			nr.TableCells[k].TableCellProperties.VMerge = nil
+		}
+	}
+	return nr
+}
+
+// newCellLike makes an empty cell with the properties of c,
+// holding a paragraph with the properties of its first one
+func (t *Table) newCellLike(c *WTableCell) *WTableCell {
+	nc := &WTableCell{TableCellProperties: &WTableCellProperties{}, file: t.file}
+	if c.TableCellProperties != nil {
+		nc.TableCellProperties = newCloner(t.file, nil).clone(c.TableCellProperties).(*WTableCellProperties)
+	}
+	p := nc.AddParagraph()
+	if fp := c.firstParagraph(); fp != nil && fp.Properties != nil {
+		p.Properties = newCloner(t.file, nil).clone(fp.Properties).(*ParagraphProperties)
+	}
+	return nc
+}
+
+// fixMerges makes each vertical merge start with a restart cell
+// and drops the merges left with a single cell
+func (t *Table) fixMerges() {
+	for r, row := range t.TableRows {
+		start := 0
+		for _, c := range row.TableCells {
+			span := gridSpanOf(c)
+			if vMergeOf(c) == "continue" {
+				j, s := t.cellIndex(r-1, start)
+				if j < 0 || s != start || gridSpanOf(t.TableRows[r-1].TableCells[j]) != span || vMergeOf(t.TableRows[r-1].TableCells[j]) == "" {
+					c.TableCellProperties.VMerge = &WvMerge{Val: "restart"}
+				}
+			}
+			start += span
+		}
+	}
+	for r, row := range t.TableRows {
+		start := 0
+		for _, c := range row.TableCells {
+			span := gridSpanOf(c)
+			if vMergeOf(c) == "restart" {
+				j, s := t.cellIndex(r+1, start)
+				if j < 0 || s != start || gridSpanOf(t.TableRows[r+1].TableCells[j]) != span || vMergeOf(t.TableRows[r+1].TableCells[j]) != "continue" {
+					c.TableCellProperties.VMerge = nil
+				}
+			}
+			start += span
+		}
+	}
+}
@@ -0,0 +1,149 @@
+/*
+   Copyright (c) 2020 gingfrederik
+   Copyright (c) 2021 Gonzalo Fernandez-Victorio
+   Copyright (c) 2021 Basement Crowd Ltd (https://www.basementcrowd.com)
+   Copyright (c) 2023 Fumiama Minamoto (源文雨)
+
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU Affero General Public License as published
+   by the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU Affero General Public License for more details.
+
+   You should have received a copy of the GNU Affero General Public License
+   along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package docx
+
+import (
+	"bytes"
+	"strconv"
+	"testing"
+)
+
+func TestTableRows(t *testing.T) {
+	doc := NewA4()
+	tbl := doc.AddTableTwips([]int64{300, 300}, []int64{2000, 3000})
+	for r, row := range tbl.TableRows {
+		for c, cell := range row.TableCells {
+			cell.AddParagraph().Justification("center").AddText(strconv.Itoa(r) + strconv.Itoa(c))
+		}
+	}
+	tbl.TableRows[0].TableCells[1].Shade("clear", "auto", "D9D9D9")
+
+	row := tbl.AppendRow()
+	if len(tbl.TableRows) != 3 || tbl.TableRows[2] != row || len(row.TableCells) != 2 {
+		t.Fatal("row not appended")
+	}
+	if row.TableRowProperties.TableRowHeight.Val != 300 || row.TableCells[1].TableCellProperties.TableCellWidth.W != 3000 {
+		t.Fatal("properties not copied")
+	}
+	p := row.TableCells[0].Items[0].(*Paragraph)
+	if len(row.TableCells[0].Items) != 1 || len(p.Children) != 0 || p.Properties.Justification.Val != "center" {
+		t.Fatal("unexpected new cell content")
+	}
+	if tbl.InsertRowAt(0).TableCells[1].TableCellProperties.Shade.Fill != "D9D9D9" {
+		t.Fatal("shade not copied")
+	}
+	if err := tbl.DeleteRow(0); err != nil {
+		t.Fatal(err)
+	}
+	if err := tbl.DeleteRow(3); err != ErrCellOutOfTable {
+		t.Fatal("expected ErrCellOutOfTable, got", err)
+	}
+
+	clone := tbl.CloneRow(1)
+	if clone == nil || tbl.TableRows[2] != clone || PlainText(clone.TableCells[0]) != "10" {
+		t.Fatal("row not cloned")
+	}
+	clone.TableCells[0].Items[0].(*Paragraph).Children = nil
+	if PlainText(tbl.TableRows[1].TableCells[0]) != "10" {
+		t.Fatal("clone shares content")
+	}
+	if tbl.CloneRow(4) != nil {
+		t.Fatal("expected nil clone")
+	}
+
+	// rows 0 to 2 in column 0 merged
+	if err := tbl.MergeCells(0, 0, 2, 0); err != nil {
+		t.Fatal(err)
+	}
+	row = tbl.InsertRowAt(1)
+	if vMergeOf(row.TableCells[0]) != "continue" || vMergeOf(row.TableCells[1]) != "" {
+		t.Fatal("inserted row not in merge")
+	}
+	if vMergeOf(tbl.CloneRow(3).TableCells[0]) != "continue" || tbl.Cell(4, 0).Node != tbl.TableRows[0].TableCells[0] {
+		t.Fatal("cloned row not in merge")
+	}
+	if vMergeOf(tbl.AppendRow().TableCells[0]) != "" {
+		t.Fatal("appended row in merge")
+	}
+	// the merge now covers rows 0 to 4
+	for i := 0; i < 4; i++ {
+		if err := tbl.DeleteRow(0); err != nil {
+			t.Fatal(err)
+		}
+		c := tbl.TableRows[0].TableCells[0]
+		if PlainText(c) != "00\n10" {
+			t.Fatal("merge content lost on delete", PlainText(c))
+		}
+		if i < 3 && vMergeOf(c) != "restart" {
+			t.Fatal("merge lost on delete")
+		}
+	}
+	if vMergeOf(tbl.TableRows[0].TableCells[0]) != "" {
+		t.Fatal("single cell merge left")
+	}
+
+	tbl = doc.AddTableTwips([]int64{0, 0}, []int64{1000, 1000, 1000})
+	if err := tbl.MergeCells(0, 0, 0, 1); err != nil {
+		t.Fatal(err)
+	}
+	tbl.InsertColumnAt(1, 500)
+	if len(tbl.TableGrid.GridCols) != 4 || tbl.TableGrid.GridCols[1].W != 500 {
+		t.Fatal("unexpected grid", len(tbl.TableGrid.GridCols))
+	}
+	first := tbl.TableRows[0].TableCells[0]
+	if len(tbl.TableRows[0].TableCells) != 2 || gridSpanOf(first) != 3 || first.TableCellProperties.TableCellWidth.W != 2500 {
+		t.Fatal("merged cell not widened")
+	}
+	if len(tbl.TableRows[1].TableCells) != 4 || tbl.gridCell(1, 1).TableCellProperties.TableCellWidth.W != 500 {
+		t.Fatal("cell not inserted")
+	}
+	tbl.InsertColumnAt(-1, 0)
+	if len(tbl.TableGrid.GridCols) != 5 || tbl.TableGrid.GridCols[4].W != 875 || len(tbl.TableRows[0].TableCells) != 3 {
+		t.Fatal("column not appended")
+	}
+	if err := tbl.DeleteColumn(0); err != nil {
+		t.Fatal(err)
+	}
+	if gridSpanOf(first) != 2 || first.TableCellProperties.TableCellWidth.W != 1500 || len(tbl.TableRows[1].TableCells) != 4 {
+		t.Fatal("merged cell not narrowed")
+	}
+	if err := tbl.DeleteColumn(4); err != ErrCellOutOfTable {
+		t.Fatal("expected ErrCellOutOfTable, got", err)
+	}
+	for len(tbl.TableGrid.GridCols) > 0 {
+		if err := tbl.DeleteColumn(0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(tbl.TableRows) != 0 {
+		t.Fatal("empty rows left")
+	}
+
+	var buf bytes.Buffer
+	_, err := doc.WriteTo(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = Parse(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+}
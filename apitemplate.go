@@ -0,0 +1,653 @@
+/*
+   Copyright (c) 2020 gingfrederik
+   Copyright (c) 2021 Gonzalo Fernandez-Victorio
+   Copyright (c) 2021 Basement Crowd Ltd (https://www.basementcrowd.com)
+   Copyright (c) 2023 Fumiama Minamoto (源文雨)
+
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU Affero General Public License as published
+   by the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU Affero General Public License for more details.
+
+   You should have received a copy of the GNU Affero General Public License
+   along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package docx
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"unicode"
+)
+
+// Execute applies the text/template actions written in the text of f,
+// like {{.Name}}, {{if .Paid}}...{{end}} and {{range .Items}}...{{end}},
+// to data and returns the result as a new document, leaving f unchanged.
+//
+// A block like {{if}} or {{range}} must end in the paragraph, the table cell,
+// the table row or the table it begins in, or ErrCrossingTemplateBlock is
+// returned.
+//
+// A paragraph or table row holding nothing but actions, like {{range .Items}}
+// or {{end}}, is dropped, so that the block repeats or removes the paragraphs
+// or rows in between. A paragraph or row beginning with {{range}}, {{if}} or
+// {{with}} and ending with its {{end}} is repeated or removed as a whole.
+// Actions split into several runs are joined into the first one, and the
+// output of an action takes the formatting of the run it is written in.
+//
+// Besides funcs, which may be nil, the func image takes the bytes of a
+// picture and inserts it by Paragraph.AddInlineDrawing, like {{image .Logo}}.
+func (f *Docx) Execute(data interface{}, funcs template.FuncMap) (*Docx, error) {
+	doc, err := f.Clone()
+	if err != nil {
+		return nil, err
+	}
+	for _, items := range doc.stories() {
+		*items, err = executeItems(doc, *items, data, funcs)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return doc, nil
+}
+
+const (
+	// templateMark begins a mark of a node in the template source
+	templateMark = '\x01'
+	// templateMarkEnd ends a mark of a node in the template source
+	templateMarkEnd = '\x02'
+)
+
+var (
+	// ErrInvalidTemplateMark is returned by Execute if the output
+	// of the template has a broken mark of node
+	ErrInvalidTemplateMark = errors.New("invalid template mark")
+	// ErrCrossingTemplateBlock is returned by Execute if a block
+	// begins and ends in different paragraphs, cells, rows or tables
+	ErrCrossingTemplateBlock = errors.New("template block crosses a paragraph or table")
+)
+
+// templateEscapeFunc is the func added to the end of each action
+// so that its output cannot be taken as a mark of node
+const templateEscapeFunc = "docxEscapeMarks"
+
+// templateMarkEscaper strips the marks in the output of an action
+var templateMarkEscaper = strings.NewReplacer(string(rune(templateMark)), "", string(rune(templateMarkEnd)), "")
+
+var (
+	// templateActionRe matches an action like {{.Name}}
+	templateActionRe = regexp.MustCompile(`\{\{.*?\}\}`)
+	// templateControlRe matches a text of actions without output
+	templateControlRe = regexp.MustCompile(`^\s*(?:\{\{-?\s*(?:range|if|else|end|with|break|continue|/\*|\$\w+\s*:?=)[^}]*\}\}\s*)+$`)
+	// templateBlockRe matches the beginning and the end of a block
+	templateBlockRe = regexp.MustCompile(`^\{\{-?\s*(range|if|with|block|define|else|end)\b`)
+)
+
+// executeItems runs the template written in the text of items
+func executeItems(doc *Docx, items []interface{}, data interface{}, funcs template.FuncMap) ([]interface{}, error) {
+	src := &templateSource{}
+	src.items(items)
+	if src.err != nil {
+		return nil, src.err
+	}
+	text := src.sb.String()
+	if !strings.Contains(text, "{{") {
+		return items, nil
+	}
+	b := &templateBuilder{
+		file:    doc,
+		nodes:   src.nodes,
+		emitted: make(map[*Paragraph]bool, len(src.nodes)),
+	}
+	t, err := template.New("docx").Funcs(template.FuncMap{"image": b.image}).Funcs(funcs).
+		Funcs(template.FuncMap{templateEscapeFunc: escapeMarks}).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	for _, x := range t.Templates() {
+		if x.Tree != nil {
+			escapeActions(x.Tree.Root)
+		}
+	}
+	out := strings.Builder{}
+	err = t.Execute(&out, data)
+	if err != nil {
+		return nil, err
+	}
+	return b.build(out.String())
+}
+
+// templateSource turns items into a template, in which the paragraphs,
+// runs, tables and other nodes are written as marks of their index in nodes
+type templateSource struct {
+	sb    strings.Builder
+	nodes []interface{}
+	err   error
+}
+
+func (s *templateSource) mark(kind byte, node interface{}) {
+	s.sb.WriteByte(templateMark)
+	s.sb.WriteByte(kind)
+	s.sb.WriteString(strconv.Itoa(len(s.nodes)))
+	s.sb.WriteByte(templateMarkEnd)
+	s.nodes = append(s.nodes, node)
+}
+
+// closed records ErrCrossingTemplateBlock if the blocks begun
+// in the source after start do not all end in it
+func (s *templateSource) closed(start int) {
+	if s.err == nil && !templateBlocksClosed(s.sb.String()[start:]) {
+		s.err = ErrCrossingTemplateBlock
+	}
+}
+
+func (s *templateSource) items(items []interface{}) {
+	for _, it := range items {
+		switch o := it.(type) {
+		case *Paragraph:
+			s.paragraph(o)
+		case *Table:
+			s.table(o)
+		default:
+			s.mark('i', it)
+		}
+	}
+}
+
+func (s *templateSource) paragraph(p *Paragraph) {
+	joinActions(p)
+	text, plain := templateText(p)
+	if plain && templateControlRe.MatchString(text) {
+		s.sb.WriteString(text)
+		return
+	}
+	open, end := templateBlock([]*Paragraph{p})
+	s.sb.WriteString(open)
+	s.mark('p', p)
+	start := s.sb.Len()
+	for _, c := range p.Children {
+		r, ok := c.(*Run)
+		if !ok {
+			s.mark('o', c)
+			continue
+		}
+		s.mark('r', r)
+		for _, x := range r.Children {
+			if t, ok := x.(*Text); ok {
+				s.sb.WriteString(t.Text)
+				continue
+			}
+			s.mark('x', x)
+		}
+	}
+	s.closed(start)
+	s.mark('q', nil)
+	s.sb.WriteString(end)
+}
+
+func (s *templateSource) table(t *Table) {
+	s.mark('t', t)
+	tstart := s.sb.Len()
+	for _, row := range t.TableRows {
+		sb := strings.Builder{}
+		plain := true
+		ps := make([]*Paragraph, 0, 8)
+		for _, cell := range row.TableCells {
+			for _, it := range cell.Items {
+				p, ok := it.(*Paragraph)
+				if !ok {
+					plain = false
+					continue
+				}
+				joinActions(p)
+				text, ok := templateText(p)
+				plain = plain && ok
+				sb.WriteString(text)
+				ps = append(ps, p)
+			}
+		}
+		if plain && templateControlRe.MatchString(sb.String()) {
+			s.sb.WriteString(sb.String())
+			continue
+		}
+		open, end := templateBlock(ps)
+		s.sb.WriteString(open)
+		s.mark('w', row)
+		rstart := s.sb.Len()
+		for _, cell := range row.TableCells {
+			s.mark('c', cell)
+			cstart := s.sb.Len()
+			s.items(cell.Items)
+			s.closed(cstart)
+			s.mark('d', nil)
+		}
+		s.closed(rstart)
+		s.mark('v', nil)
+		s.sb.WriteString(end)
+	}
+	s.closed(tstart)
+	s.mark('u', nil)
+}
+
+// templateBlocksClosed reports whether each block begun in text ends in it
+func templateBlocksClosed(text string) bool {
+	depth := 0
+	for _, a := range templateActionRe.FindAllString(text, -1) {
+		m := templateBlockRe.FindStringSubmatch(a)
+		switch {
+		case m == nil:
+		case m[1] == "end":
+			depth--
+		case m[1] == "else":
+			if depth == 0 {
+				return false
+			}
+		default:
+			depth++
+		}
+		if depth < 0 {
+			return false
+		}
+	}
+	return depth == 0
+}
+
+// escapeActions adds the func templateEscapeFunc to the end
+// of each action with output in the template tree n
+func escapeActions(n parse.Node) {
+	switch o := n.(type) {
+	case *parse.ListNode:
+		if o == nil {
+			return
+		}
+		for _, x := range o.Nodes {
+			escapeActions(x)
+		}
+	case *parse.ActionNode:
+		if len(o.Pipe.Decl) > 0 {
+			return
+		}
+		o.Pipe.Cmds = append(o.Pipe.Cmds, &parse.CommandNode{
+			NodeType: parse.NodeCommand,
+			Pos:      o.Pos,
+			Args:     []parse.Node{parse.NewIdentifier(templateEscapeFunc).SetPos(o.Pos)},
+		})
+	case *parse.IfNode:
+		escapeActions(o.List)
+		escapeActions(o.ElseList)
+	case *parse.RangeNode:
+		escapeActions(o.List)
+		escapeActions(o.ElseList)
+	case *parse.WithNode:
+		escapeActions(o.List)
+		escapeActions(o.ElseList)
+	}
+}
+
+// templateImage is the output of the func image, which is a mark
+type templateImage string
+
+// escapeMarks strips the marks in the output v of an action,
+// except the ones of pictures
+func escapeMarks(v interface{}) interface{} {
+	switch o := v.(type) {
+	case nil:
+		return v
+	case templateImage:
+		return string(o)
+	case string:
+		return templateMarkEscaper.Replace(o)
+	}
+	s := fmt.Sprint(v)
+	if strings.ContainsAny(s, string(rune(templateMark))+string(rune(templateMarkEnd))) {
+		return templateMarkEscaper.Replace(s)
+	}
+	return v
+}
+
+// joinActions moves each action split into several runs into the first one
+func joinActions(p *Paragraph) {
+	p.replace(templateActionRe, func(s string, m []int) string {
+		return s[m[0]:m[1]]
+	})
+}
+
+// templateText returns the text of p and whether p has only texts in runs
+func templateText(p *Paragraph) (string, bool) {
+	sb := strings.Builder{}
+	plain := true
+	for _, c := range p.Children {
+		r, ok := c.(*Run)
+		if !ok {
+			plain = false
+			continue
+		}
+		for _, x := range r.Children {
+			if t, ok := x.(*Text); ok {
+				sb.WriteString(t.Text)
+				continue
+			}
+			plain = false
+		}
+	}
+	return sb.String(), plain
+}
+
+// templateBlock strips the action beginning the text of ps and its
+// {{end}} closing the text, and returns them, or empty strings if the
+// text is not such a block
+func templateBlock(ps []*Paragraph) (open, end string) {
+	texts := make([]*Text, 0, 8)
+	sb := strings.Builder{}
+	for _, p := range ps {
+		for _, c := range p.Children {
+			r, ok := c.(*Run)
+			if !ok {
+				continue
+			}
+			for _, x := range r.Children {
+				if t, ok := x.(*Text); ok && t.Text != "" {
+					texts = append(texts, t)
+					sb.WriteString(t.Text)
+				}
+			}
+		}
+	}
+	full := sb.String()
+	actions := templateActionRe.FindAllStringIndex(full, -1)
+	if len(actions) < 2 {
+		return
+	}
+	first, last := actions[0], actions[len(actions)-1]
+	if strings.TrimSpace(full[:first[0]]) != "" || strings.TrimSpace(full[last[1]:]) != "" {
+		return
+	}
+	depth := 0
+	for i, a := range actions {
+		m := templateBlockRe.FindStringSubmatch(full[a[0]:a[1]])
+		switch {
+		case m == nil:
+		case m[1] == "end":
+			depth--
+		case m[1] == "else":
+			if depth == 1 {
+				// the mark would be in only one of the branches
+				return
+			}
+		default:
+			depth++
+		}
+		if i == 0 && depth != 1 {
+			return
+		}
+		if depth == 0 && i < len(actions)-1 {
+			return
+		}
+	}
+	if depth != 0 {
+		return
+	}
+	open, end = full[first[0]:first[1]], full[last[0]:last[1]]
+	// each action is in one text after joined
+	head, tail := texts[0], texts[len(texts)-1]
+	for strings.TrimSpace(head.Text) == "" {
+		head.Text = ""
+		texts = texts[1:]
+		head = texts[0]
+	}
+	head.Text = strings.TrimLeftFunc(head.Text, unicode.IsSpace)[len(open):]
+	for strings.TrimSpace(tail.Text) == "" {
+		tail.Text = ""
+		texts = texts[:len(texts)-1]
+		tail = texts[len(texts)-1]
+	}
+	tail.Text = strings.TrimRightFunc(tail.Text, unicode.IsSpace)
+	tail.Text = tail.Text[:len(tail.Text)-len(end)]
+	return
+}
+
+// templateBuilder makes items from the output of a template source
+type templateBuilder struct {
+	file  *Docx
+	nodes []interface{}
+	// images are the pictures added by the func image
+	images [][]byte
+	// emitted records the paragraphs output once
+	emitted map[*Paragraph]bool
+
+	items []interface{}
+	// tables, rows and cells are the ones being built, the innermost last
+	tables []*Table
+	rows   []*WTableRow
+	cells  []*WTableCell
+	para   *Paragraph
+	src    *Run // src is the run the following text is written in
+	run    *Run
+}
+
+// image is the template func adding a picture
+func (b *templateBuilder) image(pic []byte) templateImage {
+	sb := strings.Builder{}
+	sb.WriteByte(templateMark)
+	sb.WriteByte('m')
+	sb.WriteString(strconv.Itoa(len(b.images)))
+	sb.WriteByte(templateMarkEnd)
+	b.images = append(b.images, pic)
+	return templateImage(sb.String())
+}
+
+func (b *templateBuilder) build(out string) ([]interface{}, error) {
+	for out != "" {
+		i := strings.IndexByte(out, templateMark)
+		if i < 0 {
+			b.text(out)
+			break
+		}
+		b.text(out[:i])
+		out = out[i+1:]
+		j := strings.IndexByte(out, templateMarkEnd)
+		if j < 2 {
+			return nil, ErrInvalidTemplateMark
+		}
+		kind := out[0]
+		n, err := strconv.Atoi(out[1:j])
+		if err != nil {
+			return nil, ErrInvalidTemplateMark
+		}
+		out = out[j+1:]
+		if kind == 'm' {
+			if n >= len(b.images) {
+				return nil, ErrInvalidTemplateMark
+			}
+			err = b.image2run(b.images[n])
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if n >= len(b.nodes) {
+			return nil, ErrInvalidTemplateMark
+		}
+		err = b.node(kind, b.nodes[n])
+		if err != nil {
+			return nil, err
+		}
+	}
+	return b.items, nil
+}
+
+func (b *templateBuilder) image2run(pic []byte) error {
+	if b.para == nil {
+		return nil
+	}
+	_, err := b.para.AddInlineDrawing(pic)
+	b.run = nil
+	return err
+}
+
+// node builds the node marked by kind, returning ErrInvalidTemplateMark
+// if the mark is out of the paragraph or table it belongs to
+func (b *templateBuilder) node(kind byte, node interface{}) error {
+	switch kind {
+	case 'q', 'r', 'x', 'o':
+		if b.para == nil {
+			return ErrInvalidTemplateMark
+		}
+	case 'u', 'w':
+		if len(b.tables) == 0 {
+			return ErrInvalidTemplateMark
+		}
+	case 'v', 'c':
+		if len(b.rows) == 0 || len(b.tables) == 0 {
+			return ErrInvalidTemplateMark
+		}
+	case 'd':
+		if len(b.cells) == 0 || len(b.rows) == 0 {
+			return ErrInvalidTemplateMark
+		}
+	}
+	switch kind {
+	case 'p':
+		src := node.(*Paragraph)
+		p := *src
+		p.Children = make([]interface{}, 0, len(src.Children))
+		if src.Properties != nil {
+			pp := *src.Properties
+			p.Properties = &pp
+		}
+		if b.emitted[src] {
+			// paraId must be unique
+			p.ParaId, p.TextId = "", ""
+		}
+		b.emitted[src] = true
+		b.para, b.src, b.run = &p, nil, nil
+	case 'q':
+		children := b.para.Children[:0]
+		for _, c := range b.para.Children {
+			// runs whose actions output nothing
+			if r, ok := c.(*Run); ok && len(r.Children) == 0 && r.InstrText == "" && r.FldChar == nil {
+				continue
+			}
+			children = append(children, c)
+		}
+		b.para.Children = children
+		b.add(b.para)
+		b.para = nil
+	case 'r':
+		b.src = node.(*Run)
+		b.newRun()
+	case 'x':
+		if b.run == nil {
+			b.newRun()
+		}
+		// a copy for each time it is output, like in a range
+		b.run.Children = append(b.run.Children, newCloner(b.file, nil).clone(node))
+	case 'o':
+		b.para.Children = append(b.para.Children, newCloner(b.file, nil).clone(node))
+		b.run = nil
+	case 'i':
+		b.add(newCloner(b.file, nil).clone(node))
+	case 't':
+		t := *node.(*Table)
+		t.TableRows = make([]*WTableRow, 0, len(t.TableRows))
+		b.tables = append(b.tables, &t)
+	case 'u':
+		t := b.tables[len(b.tables)-1]
+		b.tables = b.tables[:len(b.tables)-1]
+		b.add(t)
+	case 'w':
+		row := *node.(*WTableRow)
+		row.TableCells = make([]*WTableCell, 0, len(row.TableCells))
+		if row.TableRowProperties != nil {
+			rp := *row.TableRowProperties
+			row.TableRowProperties = &rp
+		}
+		b.rows = append(b.rows, &row)
+	case 'v':
+		t := b.tables[len(b.tables)-1]
+		t.TableRows = append(t.TableRows, b.rows[len(b.rows)-1])
+		b.rows = b.rows[:len(b.rows)-1]
+	case 'c':
+		cell := *node.(*WTableCell)
+		cell.Items = make([]interface{}, 0, len(cell.Items))
+		if cell.TableCellProperties != nil {
+			cp := *cell.TableCellProperties
+			cell.TableCellProperties = &cp
+		}
+		b.cells = append(b.cells, &cell)
+	case 'd':
+		cell := b.cells[len(b.cells)-1]
+		b.cells = b.cells[:len(b.cells)-1]
+		if len(cell.Items) == 0 {
+			// a cell needs a paragraph at least
+			cell.AddParagraph()
+		}
+		row := b.rows[len(b.rows)-1]
+		row.TableCells = append(row.TableCells, cell)
+	}
+	return nil
+}
+
+// add appends item to the cell being built, or to the items if there is none
+func (b *templateBuilder) add(item interface{}) {
+	if len(b.cells) > 0 {
+		cell := b.cells[len(b.cells)-1]
+		cell.Items = append(cell.Items, item)
+		return
+	}
+	b.items = append(b.items, item)
+}
+
+// newRun appends a run with the properties of src to the paragraph
+func (b *templateBuilder) newRun() {
+	r := &Run{file: b.file}
+	if b.src != nil {
+		*r = *b.src
+		r.Children = make([]interface{}, 0, len(b.src.Children))
+		if b.src.RunProperties != nil {
+			rp := *b.src.RunProperties
+			r.RunProperties = &rp
+		}
+	}
+	b.para.Children = append(b.para.Children, r)
+	b.run = r
+}
+
+// text writes s into the current run, or drops it if out of paragraphs
+func (b *templateBuilder) text(s string) {
+	if s == "" || b.para == nil {
+		return
+	}
+	if b.run == nil {
+		b.newRun()
+	}
+	for i, line := range strings.Split(s, "\n") {
+		if i > 0 {
+			b.run.Children = append(b.run.Children, &BarterRabbet{})
+		}
+		for j, seg := range strings.Split(line, "\t") {
+			if j > 0 {
+				b.run.Children = append(b.run.Children, &Tab{})
+			}
+			if seg == "" {
+				continue
+			}
+			t := &Text{Text: seg}
+			if strings.TrimSpace(seg) != seg {
+				t.XMLSpace = "preserve"
+			}
+			b.run.Children = append(b.run.Children, t)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+/*
+   Copyright (c) 2020 gingfrederik
+   Copyright (c) 2021 Gonzalo Fernandez-Victorio
+   Copyright (c) 2021 Basement Crowd Ltd (https://www.basementcrowd.com)
+   Copyright (c) 2023 Fumiama Minamoto (源文雨)
+
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU Affero General Public License as published
+   by the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU Affero General Public License for more details.
+
+   You should have received a copy of the GNU Affero General Public License
+   along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package docx
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+const templateDocument = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>
+<w:p><w:r><w:t xml:space="preserve">Invoice for </w:t></w:r><w:r><w:rPr><w:b/></w:rPr><w:t>{{.Cust</w:t></w:r><w:r><w:t>omer}}</w:t></w:r></w:p>
+<w:p><w:r><w:t>{{range .Lines}}</w:t></w:r></w:p>
+<w:p><w:pPr><w:jc w:val="center"/></w:pPr><w:r><w:t xml:space="preserve">- {{.Name}}</w:t></w:r></w:p>
+<w:p><w:r><w:t>{{end}}</w:t></w:r></w:p>
+<w:p><w:r><w:t>{{if .Paid}}Paid{{end}}</w:t></w:r></w:p>
+<w:p><w:r><w:t>{{range .Tags}}#{{.}}{{end}}</w:t></w:r></w:p>
+<w:tbl><w:tr><w:tc><w:p><w:r><w:t>Item</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>Price</w:t></w:r></w:p></w:tc></w:tr>
+<w:tr><w:tc><w:p><w:r><w:t>{{range .Lines}}{{.Name}}</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>{{.Price}}{{end}}</w:t></w:r></w:p></w:tc></w:tr></w:tbl>
+<w:p><w:r><w:t>{{image .Logo}}</w:t></w:r></w:p>
+</w:body></w:document>`
+
+func TestExecute(t *testing.T) {
+	data := replaceDocument(t, templateDocument)
+	tmpl, err := Parse(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	logo, err := os.ReadFile("testdata/fumiamayoko.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	type line struct {
+		Name  string
+		Price int
+	}
+	doc, err := tmpl.Execute(map[string]interface{}{
+		"Customer": "ACME",
+		"Lines":    []line{{"Apple", 3}, {"Pear", 5}},
+		"Paid":     false,
+		"Tags":     []string{"a", "b"},
+		"Logo":     logo,
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	texts := make([]string, 0, 8)
+	var tbl *Table
+	for _, it := range doc.Document.Body.Items {
+		switch o := it.(type) {
+		case *Paragraph:
+			texts = append(texts, o.String())
+		case *Table:
+			tbl = o
+		}
+	}
+	if s := strings.Join(texts, "|"); s != "Invoice for ACME|- Apple|- Pear|#a|#b|![inlnim 图片 1](15c1f9f64c8fb5088c58a6a231bb6912)" {
+		t.Fatal("unexpected text", s)
+	}
+	p := doc.Document.Body.Items[0].(*Paragraph)
+	if r := p.Children[1].(*Run); r.RunProperties.Bold == nil || r.Children[0].(*Text).Text != "ACME" {
+		t.Fatal("formatting of the action is not kept")
+	}
+	if p = doc.Document.Body.Items[2].(*Paragraph); p.Properties == nil || p.Properties.Justification.Val != "center" {
+		t.Fatal("paragraph properties are not kept")
+	}
+	if tbl == nil || len(tbl.TableRows) != 3 || tbl.TableRows[2].TableCells[1].Items[0].(*Paragraph).String() != "5" {
+		t.Fatal("rows are not repeated")
+	}
+	p = doc.Document.Body.Items[len(doc.Document.Body.Items)-1].(*Paragraph)
+	if _, ok := p.Children[0].(*Run).Children[0].(*Drawing); !ok {
+		t.Fatal("image is not added")
+	}
+	if s := tmpl.Document.Body.Items[1].(*Paragraph).String(); s != "{{range .Lines}}" {
+		t.Fatal("template is changed", s)
+	}
+
+	buf := bytes.NewBuffer(make([]byte, 0, 1024*1024))
+	_, err = doc.WriteTo(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = Parse(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = tmpl.Execute(map[string]interface{}{}, nil)
+	if err == nil {
+		t.Fatal("missing error of ranging over nothing is ok")
+	}
+}
+
+func TestExecuteRangeDrawing(t *testing.T) {
+	logo, err := os.ReadFile("testdata/fumiamayoko.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := NewA4()
+	tmpl.AddParagraph().AddText("{{range .}}")
+	p := tmpl.AddParagraph()
+	p.AddText("{{.}}")
+	_, err = p.AddInlineDrawing(logo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl.AddParagraph().AddText("{{end}}")
+	doc, err := tmpl.Execute([]int{1, 2, 3}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	drawings := make(map[*Drawing]bool, 3)
+	ids := make(map[int]bool, 3)
+	for _, it := range doc.Document.Body.Items {
+		p, ok := it.(*Paragraph)
+		if !ok {
+			continue
+		}
+		for _, c := range p.Children {
+			r, ok := c.(*Run)
+			if !ok {
+				continue
+			}
+			for _, rc := range r.Children {
+				if d, ok := rc.(*Drawing); ok {
+					drawings[d] = true
+					ids[d.Inline.DocPr.ID] = true
+				}
+			}
+		}
+	}
+	if len(drawings) != 3 || len(ids) != 3 {
+		t.Fatal("drawings are shared", len(drawings), len(ids))
+	}
+}
+
+func TestExecuteCrossingBlock(t *testing.T) {
+	tmpl := NewA4()
+	tmpl.AddParagraph().AddText("{{if .}}yes")
+	tmpl.AddParagraph().AddText("no{{end}}")
+	if _, err := tmpl.Execute(true, nil); err != ErrCrossingTemplateBlock {
+		t.Fatal("unexpected error", err)
+	}
+
+	tmpl = NewA4()
+	tbl := tmpl.AddTable(1, 2)
+	tbl.TableRows[0].TableCells[0].AddParagraph().AddText("{{range .}}")
+	tbl.TableRows[0].TableCells[0].AddParagraph().AddText("x")
+	tbl.TableRows[0].TableCells[1].AddParagraph().AddText("{{end}}")
+	tbl.TableRows[0].TableCells[1].AddParagraph().AddText("y")
+	if _, err := tmpl.Execute([]int{1, 2}, nil); err != ErrCrossingTemplateBlock {
+		t.Fatal("unexpected error", err)
+	}
+
+	b := &templateBuilder{nodes: []interface{}{nil}}
+	for _, out := range []string{"\x01q0\x02", "\x01x0\x02", "\x01d0\x02", "\x01v0\x02", "\x01u0\x02", "\x01c0\x02"} {
+		if _, err := b.build(out); err != ErrInvalidTemplateMark {
+			t.Fatal("unexpected error", out, err)
+		}
+	}
+}
+
+func TestExecuteEscapeMarks(t *testing.T) {
+	tmpl := NewA4()
+	tmpl.AddParagraph().AddText("{{.}}")
+	tmpl.AddParagraph().AddText("{{printf \"%v\" .}}!")
+	doc, err := tmpl.Execute("a\x01q0\x02b\x01", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Document.Body.Items) != 2 {
+		t.Fatal("unexpected items", len(doc.Document.Body.Items))
+	}
+	for i, want := range []string{"aq0b", "aq0b!"} {
+		if s := doc.Document.Body.Items[i].(*Paragraph).String(); s != want {
+			t.Fatal("unexpected text", s)
+		}
+	}
+}
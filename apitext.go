@@ -30,6 +30,7 @@ func (p *Paragraph) AddTab() *Run {
 	run := &Run{
 		RunProperties: &RunProperties{},
 		Children:      c,
+		file:          p.file,
 	}
 
 	p.Children = append(p.Children, run)
@@ -59,9 +60,26 @@ func (p *Paragraph) AddText(text string) *Run {
 	run := &Run{
 		RunProperties: &RunProperties{},
 		Children:      c,
+		file:          p.file,
 	}
 
 	p.Children = append(p.Children, run)
 
 	return run
 }
+
+// AddField adds a complex field like PAGE or NUMPAGES to paragraph
+// and returns the run holding placeholder, which is shown until
+// the field is updated by the reader
+func (p *Paragraph) AddField(instr, placeholder string) *Run {
+	p.Children = append(p.Children,
+		&Run{RunProperties: &RunProperties{}, FldChar: &FldChar{FldCharType: "begin"}},
+		&Run{RunProperties: &RunProperties{}, InstrText: strings.TrimSpace(instr)},
+		&Run{RunProperties: &RunProperties{}, FldChar: &FldChar{FldCharType: "separate"}},
+	)
+	run := p.AddText(placeholder)
+	p.Children = append(p.Children,
+		&Run{RunProperties: &RunProperties{}, FldChar: &FldChar{FldCharType: "end"}},
+	)
+	return run
+}
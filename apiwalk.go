@@ -0,0 +1,352 @@
+/*
+   Copyright (c) 2020 gingfrederik
+   Copyright (c) 2021 Gonzalo Fernandez-Victorio
+   Copyright (c) 2021 Basement Crowd Ltd (https://www.basementcrowd.com)
+   Copyright (c) 2023 Fumiama Minamoto (源文雨)
+
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU Affero General Public License as published
+   by the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU Affero General Public License for more details.
+
+   You should have received a copy of the GNU Affero General Public License
+   along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package docx
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Node is an element in the document tree, like *Paragraph, *Run or *Text
+type Node interface{}
+
+// WalkPath is the ancestors of a visited node, the root first.
+//
+// It is reused during the walk, copy it if it is kept.
+type WalkPath []Node
+
+// Parent returns the direct parent of the visited node, or nil for the root
+func (p WalkPath) Parent() Node {
+	if len(p) == 0 {
+		return nil
+	}
+	return p[len(p)-1]
+}
+
+type walkOp uint8
+
+const (
+	walkContinue walkOp = iota
+	walkSkip
+	walkStop
+	walkRemove
+	walkReplace
+)
+
+// WalkAction tells Walk what to do after a node is visited
+type WalkAction struct {
+	op   walkOp
+	node Node
+}
+
+var (
+	// WalkContinue walks into the children of the node
+	WalkContinue = WalkAction{op: walkContinue}
+	// WalkSkip leaves out the children of the node
+	WalkSkip = WalkAction{op: walkSkip}
+	// WalkStop ends the walk
+	WalkStop = WalkAction{op: walkStop}
+	// WalkRemove removes the node from its parent
+	WalkRemove = WalkAction{op: walkRemove}
+)
+
+// WalkReplace replaces the node by n in its parent. n is not walked into,
+// and it is ignored if it does not fit in the place of the node.
+func WalkReplace(n Node) WalkAction {
+	return WalkAction{op: walkReplace, node: n}
+}
+
+// WalkFunc is called by Walk on every node
+type WalkFunc func(n Node, path WalkPath) WalkAction
+
+// Walk calls fn on node and all its descendants in document order.
+//
+// It walks into *Docx (body, headers, footers, notes and comments),
+// *Document, *Body, *Header, *Footer, *Note, *Comment, *Paragraph,
+// *Run, *Hyperlink, tracked changes, *Table, *WTableRow, *WTableCell,
+// *StructuredDocumentTag, *Drawing, *WordprocessingShape, *WTextBoxContent,
+// *WordprocessingCanvas, *WordprocessingGroup and *WPGGroupShape.
+//
+// The root and the parts of a document cannot be removed or replaced.
+func Walk(node Node, fn WalkFunc) {
+	w := walker{fn: fn, path: make(WalkPath, 0, 16)}
+	w.visit(node)
+}
+
+type walker struct {
+	fn   WalkFunc
+	path WalkPath
+	stop bool
+	// siblings and index locate the visited node in its parent,
+	// siblings is invalid if the parent holds it in a field
+	siblings reflect.Value
+	index    int
+}
+
+// visit calls fn on n and walks into its children if asked
+func (w *walker) visit(n Node) WalkAction {
+	if w.stop {
+		return WalkSkip
+	}
+	act := w.fn(n, w.path)
+	switch act.op {
+	case walkContinue:
+		w.children(n)
+	case walkStop:
+		w.stop = true
+	}
+	return act
+}
+
+// fixed walks n that cannot be removed or replaced
+func (w *walker) fixed(n Node) {
+	w.siblings = reflect.Value{}
+	w.visit(n)
+}
+
+// list walks the slice that s points to
+func (w *walker) list(s interface{}) {
+	v := reflect.ValueOf(s).Elem()
+	n := 0
+	for i := 0; i < v.Len(); i++ {
+		e := v.Index(i)
+		if !w.stop && !isNilValue(e) {
+			w.siblings, w.index = v, n
+			switch act := w.visit(nodeAt(v, i)); act.op {
+			case walkRemove:
+				continue
+			case walkReplace:
+				setNode(e, act.node)
+			}
+		}
+		if n != i {
+			v.Index(n).Set(e)
+		}
+		n++
+	}
+	for i := n; i < v.Len(); i++ {
+		v.Index(i).Set(reflect.Zero(v.Type().Elem()))
+	}
+	v.SetLen(n)
+}
+
+// field walks the pointer that f points to
+func (w *walker) field(f interface{}) {
+	v := reflect.ValueOf(f).Elem()
+	if isNilValue(v) {
+		return
+	}
+	w.siblings = reflect.Value{}
+	switch act := w.visit(v.Interface()); act.op {
+	case walkRemove:
+		v.Set(reflect.Zero(v.Type()))
+	case walkReplace:
+		setNode(v, act.node)
+	}
+}
+
+func (w *walker) children(n Node) {
+	w.path = append(w.path, n)
+	switch o := n.(type) {
+	case *Docx:
+		w.fixed(&o.Document.Body)
+		for _, h := range o.headers {
+			w.fixed(h)
+		}
+		for _, ft := range o.footers {
+			w.fixed(ft)
+		}
+		for _, ns := range []*Notes{o.footnotes, o.endnotes} {
+			if ns == nil {
+				continue
+			}
+			for _, x := range ns.Notes {
+				w.fixed(x)
+			}
+		}
+		if o.comments != nil {
+			for _, c := range o.comments.Comments {
+				w.fixed(c)
+			}
+		}
+	case *Document:
+		w.fixed(&o.Body)
+	case *Body:
+		w.list(&o.Items)
+	case *Header:
+		w.list(&o.Items)
+	case *Footer:
+		w.list(&o.Items)
+	case *Note:
+		w.list(&o.Items)
+	case *Comment:
+		w.list(&o.Items)
+	case *Paragraph:
+		w.list(&o.Children)
+	case *Run:
+		w.list(&o.Children)
+	case *Hyperlink:
+		if o.Runs != nil {
+			w.list(o.Runs)
+		}
+	case *Insertion:
+		w.list(&o.Children)
+	case *Deletion:
+		w.list(&o.Children)
+	case *MoveFrom:
+		w.list(&o.Children)
+	case *MoveTo:
+		w.list(&o.Children)
+	case *Table:
+		w.list(&o.TableRows)
+	case *WTableRow:
+		w.list(&o.TableCells)
+	case *WTableCell:
+		w.list(&o.Items)
+	case *StructuredDocumentTag:
+		if c := o.SdtContent; c != nil {
+			if c.Paragraphs != nil {
+				w.list(c.Paragraphs)
+			}
+			if c.Runs != nil {
+				w.list(c.Runs)
+			}
+			if c.Tables != nil {
+				w.list(c.Tables)
+			}
+		}
+	case *Drawing:
+		if gd := o.graphicData(); gd != nil {
+			w.field(&gd.Pic)
+			w.field(&gd.Shape)
+			w.field(&gd.Canvas)
+			w.field(&gd.Group)
+		}
+	case *WordprocessingShape:
+		if o.TextBox != nil {
+			w.field(&o.TextBox.Content)
+		}
+	case *WTextBoxContent:
+		w.list(&o.Paragraphs)
+	case *WordprocessingCanvas:
+		w.list(&o.Items)
+	case *WordprocessingGroup:
+		w.list(&o.Elems)
+	case *WPGGroupShape:
+		w.list(&o.Elems)
+	}
+	w.path = w.path[:len(w.path)-1]
+}
+
+func isNilValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Slice, reflect.Map:
+		return v.IsNil()
+	}
+	return false
+}
+
+// setNode puts n into v if it fits
+func setNode(v reflect.Value, n Node) {
+	if nv, ok := nodeValue(v.Type(), n); ok {
+		v.Set(nv)
+	}
+}
+
+// nodeValue returns n as a value of t, or false if it does not fit
+func nodeValue(t reflect.Type, n Node) (reflect.Value, bool) {
+	nv := reflect.ValueOf(n)
+	if !nv.IsValid() {
+		return nv, false
+	}
+	if t.Kind() == reflect.Struct && nv.Kind() == reflect.Ptr {
+		if nv.IsNil() {
+			return nv, false
+		}
+		nv = nv.Elem()
+	}
+	return nv, nv.Type().AssignableTo(t)
+}
+
+// graphicData returns the content of d, or nil if it has none
+func (d *Drawing) graphicData() *AGraphicData {
+	var g *AGraphic
+	if d.Inline != nil {
+		g = d.Inline.Graphic
+	} else if d.Anchor != nil {
+		g = d.Anchor.Graphic
+	}
+	if g == nil {
+		return nil
+	}
+	return g.GraphicData
+}
+
+// keepElements removes the children of node whose type is not in name
+func keepElements(node Node, name []string) {
+	namemap := make(map[string]struct{}, len(name)*2)
+	for _, n := range name {
+		namemap[n] = struct{}{}
+	}
+	Walk(node, func(n Node, path WalkPath) WalkAction {
+		if len(path) == 0 {
+			return WalkContinue
+		}
+		if _, ok := namemap[reflect.TypeOf(n).String()]; ok {
+			return WalkSkip
+		}
+		return WalkRemove
+	})
+}
+
+// PlainText returns the text in node with a newline between paragraphs.
+// Deleted text of tracked changes is left out.
+func PlainText(node Node) string {
+	sb := strings.Builder{}
+	started := false
+	Walk(node, func(n Node, _ WalkPath) WalkAction {
+		switch o := n.(type) {
+		case *Paragraph:
+			if started {
+				sb.WriteByte('\n')
+			}
+			started = true
+		case *Deletion, *MoveFrom:
+			return WalkSkip
+		default:
+			writeText(&sb, o)
+		}
+		return WalkContinue
+	})
+	return sb.String()
+}
+
+// writeText writes n into sb if it is a text node
+func writeText(sb *strings.Builder, n Node) {
+	switch x := n.(type) {
+	case *Text:
+		sb.WriteString(x.Text)
+	case *Tab:
+		sb.WriteByte('\t')
+	case *BarterRabbet:
+		sb.WriteByte('\n')
+	}
+}
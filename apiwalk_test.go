@@ -0,0 +1,97 @@
+/*
+   Copyright (c) 2020 gingfrederik
+   Copyright (c) 2021 Gonzalo Fernandez-Victorio
+   Copyright (c) 2021 Basement Crowd Ltd (https://www.basementcrowd.com)
+   Copyright (c) 2023 Fumiama Minamoto (源文雨)
+
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU Affero General Public License as published
+   by the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU Affero General Public License for more details.
+
+   You should have received a copy of the GNU Affero General Public License
+   along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package docx
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWalk(t *testing.T) {
+	data := replaceDocument(t, placeholderDocument)
+	doc, err := Parse(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := PlainText(doc); s != "Dear {{customer_name}}, order {{id}}\t{{id}}.\n{{id}}\nDue 2023-01-31\n\n#{{id}}" {
+		t.Fatal("unexpected text", s)
+	}
+
+	var path WalkPath
+	Walk(doc, func(n Node, p WalkPath) WalkAction {
+		if x, ok := n.(*Text); ok && x.Text == "#{{id}}" {
+			path = append(path, p...)
+			return WalkStop
+		}
+		return WalkContinue
+	})
+	if len(path) != 9 {
+		t.Fatal("unexpected path", path)
+	}
+	if _, ok := path[4].(*Drawing); !ok {
+		t.Fatal("unexpected drawing", path[4])
+	}
+	if _, ok := path[6].(*WTextBoxContent); !ok {
+		t.Fatal("unexpected text box", path[6])
+	}
+	if _, ok := path.Parent().(*Run); !ok {
+		t.Fatal("unexpected parent", path.Parent())
+	}
+
+	visited := 0
+	Walk(&doc.Document.Body, func(n Node, _ WalkPath) WalkAction {
+		visited++
+		switch n.(type) {
+		case *Paragraph:
+			return WalkSkip
+		case *Table:
+			return WalkStop
+		}
+		return WalkContinue
+	})
+	if visited != 3 {
+		t.Fatal("unexpected visits", visited)
+	}
+
+	Walk(&doc.Document.Body, func(n Node, _ WalkPath) WalkAction {
+		switch n.(type) {
+		case *Tab:
+			return WalkRemove
+		case *Table:
+			p := &Paragraph{file: doc}
+			p.AddText("table")
+			return WalkReplace(p)
+		}
+		return WalkContinue
+	})
+	if s := PlainText(&doc.Document.Body); s != "Dear {{customer_name}}, order {{id}}{{id}}.\ntable\nDue 2023-01-31\n\n#{{id}}" {
+		t.Fatal("unexpected text", s)
+	}
+
+	doc.Document.Body.DropDrawingOf("Shape")
+	if s := PlainText(&doc.Document.Body); s != "Dear {{customer_name}}, order {{id}}{{id}}.\ntable\nDue 2023-01-31\n" {
+		t.Fatal("shape not dropped", s)
+	}
+	doc.Document.Body.Items[0].(*Paragraph).KeepElements("*docx.Hyperlink")
+	if len(doc.Document.Body.Items[0].(*Paragraph).Children) != 0 {
+		t.Fatal("unexpected kept elements")
+	}
+}
@@ -182,20 +182,16 @@ func main() {
 		doc.Document.Body.DropDrawingOf("NilPicture")
 	}
 	if *droppp {
-		for _, it := range doc.Document.Body.Items {
-			switch o := it.(type) {
-			case *docx.Paragraph: // printable
+		docx.Walk(&doc.Document.Body, func(n docx.Node, _ docx.WalkPath) docx.WalkAction {
+			switch o := n.(type) {
+			case *docx.Paragraph: // printable, also in nested tables
 				o.Properties = nil
-			case *docx.Table: // printable
-				for _, tr := range o.TableRows {
-					for _, tc := range tr.TableCells {
-						for _, p := range tc.Paragraphs {
-							p.Properties = nil
-						}
-					}
-				}
+				return docx.WalkSkip
+			case *docx.Run:
+				return docx.WalkSkip
 			}
-		}
+			return docx.WalkContinue
+		})
 	}
 	if *unm {
 		i := strings.LastIndex(*fileLocation, "/")
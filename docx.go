@@ -24,14 +24,18 @@ package docx
 
 import (
 	"archive/zip"
+	"compress/flate"
 	"encoding/xml"
+	"errors"
 	"io"
 	"io/fs"
-	"log"
 	"os"
 	"sync"
 )
 
+// ErrInvalidCompressionLevel is returned by SetCompressionLevel
+var ErrInvalidCompressionLevel = errors.New("invalid compression level")
+
 // Docx is the structure that allow to access the internal represntation
 // in memory of the doc (either read or about to be written)
 type Docx struct {
@@ -44,6 +48,24 @@ type Docx struct {
 
 	Numbering Numbering
 
+	numbering   *numberingState // numbering renders list numbers in Paragraph.String
+	numberingMu sync.Mutex
+
+	headers []*Header // headers are word/headerN.xml
+	footers []*Footer // footers are word/footerN.xml
+
+	styles *Styles // styles is word/styles.xml, loaded from template on first use
+
+	tmplStyles   *Styles // tmplStyles are the styles of template read by the resolver
+	tmplStylesMu sync.Mutex
+
+	footnotes *Notes // footnotes is word/footnotes.xml
+	endnotes  *Notes // endnotes is word/endnotes.xml
+
+	comments *Comments // comments is word/comments.xml
+
+	revID int // revID is the last id of tracked changes, 0 if not counted yet
+
 	rID       uintptr
 	imageID   uintptr
 	docID     uintptr
@@ -54,6 +76,11 @@ type Docx struct {
 	tmplfs   fs.FS
 	tmpfslst []string
 
+	complevel int // complevel is the flate level used by WriteTo
+	compset   bool
+
+	logger Logger // logger is ParseOptions.Logger
+
 	io.Reader
 	io.WriterTo
 }
@@ -94,13 +121,32 @@ func NewA4() *Docx {
 //		docxlib.Parse(file, handler.Size)
 //	}
 func Parse(reader io.ReaderAt, size int64) (doc *Docx, err error) {
+	return ParseWithOptions(reader, size, ParseOptions{})
+}
+
+// ParseOptions tunes the behaviour of ParseWithOptions
+type ParseOptions struct {
+	// Preserve keeps the elements and attributes that are not modelled
+	// as *RawXML in Body.Items, WTableCell.Items, Paragraph.Children and
+	// Run.Children (or inside Table and WTableRow) and writes them back
+	// at their original position. Modelled properties, drawings, sdts and
+	// hyperlinks are written back as they were in source unless modified.
+	Preserve bool
+	// Strict returns the decoding errors that are dropped by default,
+	// such as an element that does not match the expected type.
+	Strict bool
+	// Logger receives the diagnostics such as unsupported elements.
+	// Nothing is logged if it is nil.
+	Logger Logger
+}
+
+// ParseWithOptions is Parse with options
+func ParseWithOptions(reader io.ReaderAt, size int64, opt ParseOptions) (doc *Docx, err error) {
 	zipReader, err := zip.NewReader(reader, size)
 	if err != nil {
 		return nil, err
 	}
-	doc, err = unpack(zipReader)
-	log.Println("docxlib.Parse: unpacked")
-	return
+	return unpack(zipReader, &opt)
 }
 
 // LoadBodyItems will load body and media to a new Docx struct.
@@ -153,12 +199,29 @@ func LoadBodyItems(items []interface{}, media []Media) *Docx {
 	return doc
 }
 
-// WriteTo allows to save a docx to a writer
+// WriteTo allows to save a docx to a writer.
+// The same document is always written into the same bytes:
+// parts are sorted with [Content_Types].xml first and
+// have a fixed modification time.
 func (f *Docx) WriteTo(writer io.Writer) (_ int64, err error) {
 	zipWriter := zip.NewWriter(writer)
-	defer zipWriter.Close()
+	err = f.pack(zipWriter)
+	if err != nil {
+		_ = zipWriter.Close()
+		return
+	}
+	return 0, zipWriter.Close()
+}
 
-	return 0, f.pack(zipWriter)
+// SetCompressionLevel sets the flate level (flate.HuffmanOnly to
+// flate.BestCompression) used by WriteTo
+func (f *Docx) SetCompressionLevel(level int) error {
+	if level < flate.HuffmanOnly || level > flate.BestCompression {
+		return ErrInvalidCompressionLevel
+	}
+	f.complevel = level
+	f.compset = true
+	return nil
 }
 
 // Read is a fake function and cannot be used
@@ -171,4 +234,9 @@ func (f *Docx) UseTemplate(template string, tmpfslst []string, tmplfs fs.FS) {
 	f.template = template
 	f.tmplfs = tmplfs
 	f.tmpfslst = tmpfslst
+	// styles will be loaded from the new template
+	f.styles = nil
+	f.tmplStylesMu.Lock()
+	f.tmplStyles = nil
+	f.tmplStylesMu.Unlock()
 }
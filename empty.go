@@ -89,7 +89,7 @@ func newEmptyA4File() *Docx {
 			},
 			XMLW: XMLNS_W,
 		},
-		rID:      3,
+		rID:      4,
 		slowIDs:  make(map[string]uintptr, 64),
 		template: "a4",
 		tmpfslst: A4TemplateFilesList,
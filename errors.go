@@ -0,0 +1,90 @@
+/*
+   Copyright (c) 2020 gingfrederik
+   Copyright (c) 2021 Gonzalo Fernandez-Victorio
+   Copyright (c) 2021 Basement Crowd Ltd (https://www.basementcrowd.com)
+   Copyright (c) 2023 Fumiama Minamoto (源文雨)
+
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU Affero General Public License as published
+   by the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU Affero General Public License for more details.
+
+   You should have received a copy of the GNU Affero General Public License
+   along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package docx
+
+import (
+	"encoding/xml"
+	"strings"
+)
+
+// PartError is returned by Parse and WriteTo when a part
+// of the package cannot be read or written
+type PartError struct {
+	Part string // Part is the name in zip, e.g. word/document.xml
+	Err  error
+}
+
+// Error implements error
+func (e *PartError) Error() string {
+	return e.Part + ": " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *PartError) Unwrap() error {
+	return e.Err
+}
+
+// newPartError wraps err into *PartError unless it already is
+func newPartError(part string, err error) error {
+	if _, ok := err.(*PartError); ok {
+		return err
+	}
+	return &PartError{Part: part, Err: err}
+}
+
+// RelationshipError is returned when a node is cloned into another document
+// but a relationship it refers to, like a chart, cannot be moved there
+type RelationshipError struct {
+	ID   string // ID is the rId in the source document
+	Type string // Type is the type of the relationship, empty if it is not found
+}
+
+// Error implements error
+func (e *RelationshipError) Error() string {
+	if e.Type == "" {
+		return "relationship " + e.ID + " not found"
+	}
+	return "relationship " + e.ID + " of type " + e.Type + " cannot be moved"
+}
+
+// Logger receives the diagnostics of parsing, e.g. *log.Logger
+type Logger interface {
+	Println(v ...interface{})
+}
+
+// ignorable reports whether err is an "expected element type" error of
+// encoding/xml, which is dropped unless ParseOptions.Strict is set
+func ignorable(d *xml.Decoder, err error) bool {
+	if !strings.HasPrefix(err.Error(), "expected") {
+		return false
+	}
+	ctx := contextOf(d)
+	return ctx == nil || !ctx.opt.Strict
+}
+
+// logln prints v to ParseOptions.Logger of d, if any
+func logln(d *xml.Decoder, v ...interface{}) {
+	ctx := contextOf(d)
+	if ctx == nil || ctx.opt.Logger == nil {
+		return
+	}
+	ctx.opt.Logger.Println(v...)
+}
@@ -0,0 +1,73 @@
+/*
+   Copyright (c) 2020 gingfrederik
+   Copyright (c) 2021 Gonzalo Fernandez-Victorio
+   Copyright (c) 2021 Basement Crowd Ltd (https://www.basementcrowd.com)
+   Copyright (c) 2023 Fumiama Minamoto (源文雨)
+
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU Affero General Public License as published
+   by the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU Affero General Public License for more details.
+
+   You should have received a copy of the GNU Affero General Public License
+   along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package docx
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// logRecorder is a Logger that keeps all lines
+type logRecorder []string
+
+func (l *logRecorder) Println(v ...interface{}) {
+	s := make([]string, len(v))
+	for i, x := range v {
+		s[i], _ = x.(string)
+	}
+	*l = append(*l, strings.Join(s, " "))
+}
+
+func TestPartError(t *testing.T) {
+	// marshal error does not exit
+	w := NewA4()
+	w.AddParagraph().AddText("ok")
+	w.Document.Body.Items = append(w.Document.Body.Items, make(chan int))
+	_, err := w.WriteTo(bytes.NewBuffer(nil))
+	var perr *PartError
+	if !errors.As(err, &perr) || perr.Part != "word/document.xml" {
+		t.Fatal("unexpected error", err)
+	}
+
+	// malformed part
+	src := replaceDocument(t, `<?xml version="1.0" encoding="UTF-8"?><w:document xmlns:w="`+XMLNS_W+`"><w:body><w:p>`)
+	_, err = Parse(bytes.NewReader(src), int64(len(src)))
+	if !errors.As(err, &perr) || perr.Part != "word/document.xml" {
+		t.Fatal("unexpected error", err)
+	}
+
+	// dropped errors are surfaced in strict mode only
+	src = replaceDocument(t, `<?xml version="1.0" encoding="UTF-8"?><w:document xmlns:w="`+XMLNS_W+`"><w:body><w:p><w:pPr><w:pBdr><w:top w:val="single"/></w:pBdr></w:pPr><w:unknown/></w:p></w:body></w:document>`)
+	var logs logRecorder
+	_, err = ParseWithOptions(bytes.NewReader(src), int64(len(src)), ParseOptions{Logger: &logs})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(logs) == 0 {
+		t.Fatal("nothing logged")
+	}
+	_, err = ParseWithOptions(bytes.NewReader(src), int64(len(src)), ParseOptions{Strict: true})
+	if !errors.As(err, &perr) || perr.Part != "word/document.xml" || !strings.HasPrefix(perr.Err.Error(), "expected") {
+		t.Fatal("unexpected error", err)
+	}
+}
@@ -0,0 +1,328 @@
+/*
+   Copyright (c) 2020 gingfrederik
+   Copyright (c) 2021 Gonzalo Fernandez-Victorio
+   Copyright (c) 2021 Basement Crowd Ltd (https://www.basementcrowd.com)
+   Copyright (c) 2023 Fumiama Minamoto (源文雨)
+
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU Affero General Public License as published
+   by the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU Affero General Public License for more details.
+
+   You should have received a copy of the GNU Affero General Public License
+   along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package docx
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+//nolint:revive,stylecheck
+const (
+	// SOURCE_CODE_STYLE is the paragraph style of imported code blocks
+	SOURCE_CODE_STYLE = "SourceCode"
+	// VERBATIM_STYLE is the character style of imported inline code
+	VERBATIM_STYLE = "VerbatimChar"
+	// QUOTE_STYLE is the paragraph style of imported block quotes
+	QUOTE_STYLE = "Quote"
+)
+
+const (
+	// listIndent is the indentation of a list level in twips
+	listIndent = 720
+	// cellMargin is the default left and right margin of a table cell in twips
+	cellMargin = 108
+)
+
+// ErrImageOutOfDir is returned by FromMarkdown and FromHTML if an image
+// refers to a file which is not under the image directory
+var ErrImageOutOfDir = errors.New("image out of image directory")
+
+var (
+	schemeRegex  = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9+.-]{1,31}:`)
+	headingSizes = [...]string{"32", "28", "26", "24", "22", "22"}
+	listBullets  = [...]string{"•", "◦", "▪"}
+)
+
+// importer builds a new document from a markup language,
+// like FromMarkdown and FromHTML do
+type importer struct {
+	f *Docx
+	// width is the text width of the page in twips
+	width     int64
+	imageDir  string
+	loadImage func(link string) ([]byte, error)
+	err       error
+}
+
+func newImporter(imageDir string, loadImage func(link string) ([]byte, error)) *importer {
+	f := NewA4()
+	return &importer{f: f, width: f.textWidth(), imageDir: imageDir, loadImage: loadImage}
+}
+
+// importList is a list being read, with the format of each level
+// taken from its first item
+type importList struct {
+	numID, abstractNumID int
+	set, ordered         [9]bool
+	start                [9]int
+}
+
+// importContext tells where the blocks being read are
+type importContext struct {
+	// cell is the table cell paragraphs go into, or nil for the body
+	cell  *WTableCell
+	quote bool
+	list  *importList
+	level int
+	// item is true until the first paragraph of a list item is written
+	item *bool
+}
+
+// paragraph adds a paragraph of style, or of the style of ctx if empty
+func (m *importer) paragraph(ctx importContext, style string) *Paragraph {
+	var p *Paragraph
+	if ctx.cell != nil {
+		p = ctx.cell.AddParagraph()
+	} else {
+		p = m.f.AddParagraph()
+	}
+	if style == "" && ctx.quote {
+		style = m.style(QUOTE_STYLE)
+	}
+	if style != "" {
+		p.Style(style)
+	}
+	if ctx.list == nil {
+		return p
+	}
+	if p.Properties == nil {
+		p.Properties = &ParagraphProperties{}
+	}
+	if ctx.item != nil && *ctx.item {
+		*ctx.item = false
+		p.Properties.NumPr = &NumPr{Ilvl: &Ilvl{Val: ctx.level}, NumID: &NumID{Val: ctx.list.numID}}
+	} else {
+		p.Properties.Ind = &Ind{Left: listIndent * (ctx.level + 1)}
+	}
+	return p
+}
+
+// style returns id after adding the style if the document lacks it
+func (m *importer) style(id string) string {
+	if m.f.Style(id) != nil {
+		return id
+	}
+	switch id {
+	case SOURCE_CODE_STYLE:
+		m.f.AddParagraphStyle(id, "Source Code").Font("Consolas", "Consolas", "default")
+	case VERBATIM_STYLE:
+		m.f.AddCharacterStyle(id, "Verbatim Char").Font("Consolas", "Consolas", "default")
+	case QUOTE_STYLE:
+		m.f.AddParagraphStyle(id, "Quote").Italic().ParagraphProperties = &ParagraphProperties{
+			Ind: &Ind{Left: listIndent},
+		}
+	default: // Heading1 to Heading6
+		level := int(id[len(id)-1] - '0')
+		st := m.f.AddParagraphStyle(id, "heading "+id[len(id)-1:]).Bold().Size(headingSizes[level-1])
+		st.ParagraphProperties = &ParagraphProperties{KeepNext: &KeepNext{}}
+	}
+	return id
+}
+
+// newList allocates the numbering ids of a new list
+func (m *importer) newList() *importList {
+	l := &importList{numID: 1, abstractNumID: 0}
+	if m.f.Numbering.AbstractNums != nil {
+		for _, an := range *m.f.Numbering.AbstractNums {
+			if id, err := strconv.Atoi(an.AbstractNumID); err == nil && id >= l.abstractNumID {
+				l.abstractNumID = id + 1
+			}
+		}
+	}
+	if m.f.Numbering.Nums != nil {
+		for _, n := range *m.f.Numbering.Nums {
+			if id, err := strconv.Atoi(n.NumID); err == nil && id >= l.numID {
+				l.numID = id + 1
+			}
+		}
+	}
+	return l
+}
+
+// finishList adds the numbering of l into the document
+func (m *importer) finishList(l *importList) {
+	lvls := make([]*Lvl, len(l.set))
+	for i := range lvls {
+		start, numFmt, lvlText, lvlJc := NewStart(), NewNumFmt(), NewLvlText(), NewLvlJc()
+		start.Val, numFmt.Val, lvlText.Val, lvlJc.Val = "1", "bullet", listBullets[i%len(listBullets)], "left"
+		if l.ordered[i] {
+			start.Val, numFmt.Val, lvlText.Val = strconv.Itoa(l.start[i]), "decimal", "%"+strconv.Itoa(i+1)+"."
+		}
+		lvls[i] = &Lvl{
+			ILvl:    i,
+			Start:   start,
+			NumFmt:  numFmt,
+			LvlText: lvlText,
+			LvlJc:   lvlJc,
+			Ppr: &[]ParagraphProperties{{
+				Ind: &Ind{Left: listIndent * (i + 1), Hanging: listIndent / 2},
+			}},
+		}
+	}
+	multi := NewMultiLevelType()
+	multi.Val = "hybridMultilevel"
+	abstractNumID := NewAbstractNumID()
+	abstractNumID.Val = strconv.Itoa(l.abstractNumID)
+	if m.f.Numbering.AbstractNums == nil {
+		m.f.Numbering.AbstractNums = &[]AbstractNum{}
+	}
+	if m.f.Numbering.Nums == nil {
+		m.f.Numbering.Nums = &[]*Num{}
+	}
+	*m.f.Numbering.AbstractNums = append(*m.f.Numbering.AbstractNums, AbstractNum{
+		AbstractNumID:  abstractNumID.Val,
+		Lvl:            &lvls,
+		MultiLevelType: multi,
+	})
+	*m.f.Numbering.Nums = append(*m.f.Numbering.Nums, &Num{
+		NumID:         strconv.Itoa(l.numID),
+		AbstractNumID: abstractNumID,
+	})
+	m.f.ResetNumbering()
+}
+
+// image adds the image at link, or a link to it if it is a URL
+// and LoadImage is nil. It returns the drawing of the image if added.
+func (m *importer) image(p *Paragraph, alt, link string) *Drawing {
+	var data []byte
+	var err error
+	switch {
+	case strings.HasPrefix(link, "data:"):
+		data, err = decodeDataURI(link)
+	case m.loadImage != nil:
+		data, err = m.loadImage(link)
+	case schemeRegex.MatchString(link):
+		if alt == "" {
+			alt = link
+		}
+		p.AddLink(alt, link)
+		return nil
+	case m.imageDir == "":
+		if alt != "" {
+			p.AddText(alt)
+		}
+		return nil
+	default:
+		var name string
+		name, err = m.imagePath(link)
+		if err == nil {
+			data, err = os.ReadFile(name)
+		}
+	}
+	if err != nil {
+		m.err = err
+		return nil
+	}
+	r, err := p.AddInlineDrawing(data)
+	if err != nil {
+		m.err = err
+		return nil
+	}
+	d, ok := r.Children[0].(*Drawing)
+	if !ok {
+		return nil
+	}
+	if alt != "" && d.Inline != nil && d.Inline.DocPr != nil {
+		d.Inline.DocPr.Name = alt
+	}
+	return d
+}
+
+// imagePath returns the file under imageDir that link refers to,
+// or ErrImageOutOfDir if it is absolute or goes up out of imageDir
+func (m *importer) imagePath(link string) (string, error) {
+	name := link
+	if s, err := url.PathUnescape(link); err == nil {
+		name = s
+	}
+	name = filepath.FromSlash(name)
+	if filepath.IsAbs(name) || filepath.VolumeName(name) != "" || strings.HasPrefix(name, string(filepath.Separator)) {
+		return "", ErrImageOutOfDir
+	}
+	for _, e := range strings.Split(filepath.ToSlash(name), "/") {
+		if e == ".." {
+			return "", ErrImageOutOfDir
+		}
+	}
+	dir, err := filepath.Abs(m.imageDir)
+	if err != nil {
+		return "", err
+	}
+	name = filepath.Join(dir, name)
+	rel, err := filepath.Rel(dir, name)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", ErrImageOutOfDir
+	}
+	return name, nil
+}
+
+// preserveSpace keeps the leading and trailing spaces of the texts of r
+func preserveSpace(r *Run) *Run {
+	for _, c := range r.Children {
+		if t, ok := c.(*Text); ok && strings.TrimSpace(t.Text) != t.Text {
+			t.XMLSpace = "preserve"
+		}
+	}
+	return r
+}
+
+// addBreak adds a run of a line break to p
+func (p *Paragraph) addBreak() {
+	p.Children = append(p.Children, &Run{
+		RunProperties: &RunProperties{},
+		Children:      []interface{}{&BarterRabbet{}},
+		file:          p.file,
+	})
+}
+
+// textWidth returns the width between the page margins in twips
+func (f *Docx) textWidth() int64 {
+	if sp := f.Document.Body.lastSectPr(); sp != nil && sp.PgSz != nil && sp.PgMar != nil {
+		w, err1 := strconv.ParseInt(sp.PgSz.W, 10, 64)
+		l, err2 := strconv.ParseInt(sp.PgMar.Left, 10, 64)
+		r, err3 := strconv.ParseInt(sp.PgMar.Right, 10, 64)
+		if err1 == nil && err2 == nil && err3 == nil && w-l-r > 0 {
+			return w - l - r
+		}
+	}
+	return 8306
+}
+
+// decodeDataURI returns the content of a data: URI
+func decodeDataURI(uri string) ([]byte, error) {
+	i := strings.IndexByte(uri, ',')
+	if i < 0 {
+		return nil, errors.New("invalid data uri")
+	}
+	head, data := uri[len("data:"):i], uri[i+1:]
+	if strings.HasSuffix(head, ";base64") {
+		return base64.StdEncoding.DecodeString(strings.Join(strings.Fields(data), ""))
+	}
+	s, err := url.PathUnescape(data)
+	return []byte(s), err
+}
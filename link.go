@@ -33,12 +33,17 @@ var (
 	ErrRefTargetNotFound = errors.New("ref target not found")
 )
 
+// newRelationID allocates a new rId in document.xml.rels
+func (f *Docx) newRelationID() string {
+	return "rId" + strconv.Itoa(int(atomic.AddUintptr(&f.rID, 1)))
+}
+
 // when adding an hyperlink we need to store a reference in the relationship field
 //
 //	this func is not thread-safe
 func (f *Docx) addLinkRelation(link string) string {
 	rel := Relationship{
-		ID:         "rId" + strconv.Itoa(int(atomic.AddUintptr(&f.rID, 1))),
+		ID:         f.newRelationID(),
 		Type:       REL_HYPERLINK,
 		Target:     link,
 		TargetMode: REL_TARGETMODE,
@@ -54,7 +59,7 @@ func (f *Docx) addLinkRelation(link string) string {
 //	this func is not thread-safe
 func (f *Docx) addImageRelation(m Media) string {
 	rel := Relationship{
-		ID:     "rId" + strconv.Itoa(int(atomic.AddUintptr(&f.rID, 1))),
+		ID:     f.newRelationID(),
 		Type:   REL_IMAGE,
 		Target: "media/" + m.Name,
 	}
@@ -20,6 +20,12 @@
 
 package docx
 
+import (
+	"os"
+	"path"
+	"path/filepath"
+)
+
 //nolint:revive,stylecheck
 const MEDIA_FOLDER = `word/media/`
 
@@ -48,3 +54,16 @@ func (f *Docx) addMedia(m Media) {
 	f.mediaNameIdx[m.Name] = len(f.media)
 	f.media = append(f.media, m)
 }
+
+// saveMedia stores m by save, or into dir if save is nil,
+// and returns the link to it
+func saveMedia(m *Media, dir string, save func(name string, data []byte) (string, error)) (string, error) {
+	if save != nil {
+		return save(m.Name, m.Data)
+	}
+	err := os.MkdirAll(dir, 0o755)
+	if err == nil {
+		err = os.WriteFile(filepath.Join(dir, m.Name), m.Data, 0o644)
+	}
+	return path.Join(filepath.ToSlash(dir), m.Name), err
+}
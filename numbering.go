@@ -16,3 +16,203 @@ along with this program.  If not, see <https://www.gnu.org/licenses/>.
 */
 
 package docx
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Numbering ...
+// 一旦変数に格納すれば済むもの。
+// これとは別に、「値の入力⇒定義に基づいた変換」という関数自体を id と結び付けて格納しておく map を用意する。
+
+type abstractNumFunc func(args *[]int) string
+
+// numberingState renders list numbers of one document.
+// It is built from Docx.Numbering on first use and
+// counts the paragraphs rendered by Paragraph.String.
+type numberingState struct {
+	numIDToAbstractNumIDMap map[int]int
+
+	abstractNumIDToAbstractNumMap map[int]*AbstractNum
+
+	// key is NumPr.NumId, value is func
+	abstractNumToFuncMap map[int]map[int]*abstractNumFunc
+
+	// abstractNumId キーごとに、また ilvl 階層ごとに、カウント値を格納する。
+	lvlCountListMap map[int]*[]int
+
+	// 本編では連続出現 ID が同じ限りはカウントで、
+	// そうでない場合はリセットされる。
+	// 間に連続判定関数須。
+	numIDCountMap map[int]int
+
+	logger Logger
+}
+
+func newNumberingState(numbering *Numbering) *numberingState {
+	n := &numberingState{
+		numIDToAbstractNumIDMap:       make(map[int]int),
+		abstractNumIDToAbstractNumMap: make(map[int]*AbstractNum),
+		abstractNumToFuncMap:          make(map[int]map[int]*abstractNumFunc),
+		lvlCountListMap:               make(map[int]*[]int),
+		numIDCountMap:                 make(map[int]int),
+	}
+	if numbering.Nums != nil {
+		n.generateNumIDToAbstractNumIDMap(*numbering.Nums)
+	}
+	if numbering.AbstractNums != nil {
+		n.generateAbstractNumIDToAbstractNumMap(*numbering.AbstractNums)
+		n.generateAbstractNumToFuncMap()
+	}
+	return n
+}
+
+// ResetNumbering drops the list counters used by Paragraph.String,
+// so that the next call renders numbers from the beginning
+// with the current f.Numbering.
+//
+//	It is safe to call concurrently with Paragraph.String.
+func (f *Docx) ResetNumbering() {
+	f.numberingMu.Lock()
+	f.numbering = nil
+	f.numberingMu.Unlock()
+}
+
+// リセットは要らないかもしれない。
+func (n *numberingState) countNumID(numID int) int {
+	n.numIDCountMap[numID]++
+	return n.numIDCountMap[numID] - 1
+}
+
+func getNumberedString(p *Paragraph, numPr *NumPr) string {
+	if p.file == nil || numPr.NumID == nil || numPr.Ilvl == nil {
+		return ""
+	}
+	p.file.numberingMu.Lock()
+	defer p.file.numberingMu.Unlock()
+	if p.file.numbering == nil {
+		p.file.numbering = newNumberingState(&p.file.Numbering)
+		p.file.numbering.logger = p.file.logger
+	}
+	return p.file.numbering.numberedString(numPr.NumID.Val, numPr.Ilvl.Val)
+}
+
+func (n *numberingState) numberedString(numID, iLvl int) string {
+	numIDCount := n.countNumID(numID)
+
+	// 対象 AbstructNumID を取得
+	abstractNumID := n.numIDToAbstractNumIDMap[numID]
+
+	f := n.abstractNumToFuncMap[abstractNumID][iLvl]
+	if f == nil {
+		if n.logger != nil {
+			n.logger.Println("abstractNumID:", abstractNumID, "iLvl:", iLvl, "f is nil")
+		}
+		return "＠"
+	}
+
+	iLvlCountList := n.getILvlCountList(abstractNumID, iLvl, numIDCount == 0)
+
+	return (*f)(iLvlCountList)
+}
+
+func (n *numberingState) generateNumIDToAbstractNumIDMap(nums []*Num) {
+	for _, num := range nums {
+		if num == nil || num.AbstractNumID == nil || num.AbstractNumID.CommonAttrVal == nil {
+			continue
+		}
+		numID, err := GetInt(num.NumID)
+		if err != nil {
+			continue
+		}
+		abstractNumID, err := GetInt(num.AbstractNumID.Val)
+		if err != nil {
+			continue
+		}
+		n.numIDToAbstractNumIDMap[numID] = abstractNumID
+	}
+}
+
+func (n *numberingState) generateAbstractNumIDToAbstractNumMap(abstractNums []AbstractNum) {
+	for i := range abstractNums {
+		abstractNumID, err := GetInt(abstractNums[i].AbstractNumID)
+		if err != nil {
+			continue
+		}
+		n.abstractNumIDToAbstractNumMap[abstractNumID] = &abstractNums[i]
+	}
+}
+
+func (n *numberingState) generateAbstractNumToFuncMap() {
+	for abstractNumID, abstractNum := range n.abstractNumIDToAbstractNumMap {
+		if abstractNum.Lvl == nil {
+			continue
+		}
+		n.abstractNumToFuncMap[abstractNumID] = make(map[int]*abstractNumFunc)
+		for _, lvl := range *abstractNum.Lvl {
+			if lvl == nil || lvl.LvlText == nil || lvl.LvlText.CommonAttrVal == nil {
+				continue
+			}
+			n.abstractNumToFuncMap[abstractNumID][lvl.ILvl] = getAbstractNumFunc(lvl)
+		}
+	}
+}
+
+func (n *numberingState) getILvlCountList(abstractNumID, iLvl int, isRestart bool) *[]int {
+	countList := n.lvlCountListMap[abstractNumID]
+	start := 0
+	if lvl := n.abstractNumIDToAbstractNumMap[abstractNumID].lvl(iLvl); lvl != nil && lvl.Start != nil && lvl.Start.CommonAttrVal != nil {
+		start, _ = GetInt(lvl.Start.Val)
+	}
+
+	if countList == nil || isRestart {
+		countList = &[]int{start}
+		n.lvlCountListMap[abstractNumID] = countList
+	} else {
+		if len(*countList) == iLvl {
+			*countList = append(*countList, start)
+		} else if iLvl < len(*countList) {
+			(*countList)[iLvl]++
+		}
+	}
+	return countList
+}
+
+// lvl finds the level definition by ilvl
+func (a *AbstractNum) lvl(iLvl int) *Lvl {
+	if a == nil || a.Lvl == nil {
+		return nil
+	}
+	for _, lvl := range *a.Lvl {
+		if lvl != nil && lvl.ILvl == iLvl {
+			return lvl
+		}
+	}
+	return nil
+}
+
+func getAbstractNumFunc(lvl *Lvl) *abstractNumFunc {
+	iLvl := lvl.ILvl
+	lvlText := lvl.LvlText.Val
+	numFmt := ""
+	if lvl.NumFmt != nil && lvl.NumFmt.CommonAttrVal != nil {
+		numFmt = lvl.NumFmt.Val
+	}
+
+	f := func(iLvlCountList *[]int) string {
+		list := *iLvlCountList
+		var lvlTextReplaced string
+		// list から順に値を取り出す。
+		for _, val := range list {
+			n := GetFormatNumber(val, numFmt)
+			// lvlText から %i を探し、n に置き換える。
+			// "%i" がなければ何もしない。
+			iStr := strconv.Itoa(iLvl + 1)
+			lvlTextReplaced = strings.Replace(lvlText, "%"+iStr, n, -1)
+		}
+		return lvlTextReplaced
+	}
+
+	return (*abstractNumFunc)(&f)
+}
@@ -0,0 +1,99 @@
+/*
+   Copyright (c) 2020 gingfrederik
+   Copyright (c) 2021 Gonzalo Fernandez-Victorio
+   Copyright (c) 2021 Basement Crowd Ltd (https://www.basementcrowd.com)
+   Copyright (c) 2023 Fumiama Minamoto (源文雨)
+
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU Affero General Public License as published
+   by the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU Affero General Public License for more details.
+
+   You should have received a copy of the GNU Affero General Public License
+   along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package docx
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func newNumberedDocx(lvlText, numFmt string) *Docx {
+	w := NewA4()
+	w.Numbering.AbstractNums = &[]AbstractNum{{
+		AbstractNumID: "0",
+		Lvl: &[]*Lvl{{
+			ILvl:    0,
+			Start:   &Start{CommonAttrVal: &CommonAttrVal{Val: "1"}},
+			NumFmt:  &NumFmt{CommonAttrVal: &CommonAttrVal{Val: numFmt}},
+			LvlText: &LvlText{CommonAttrVal: &CommonAttrVal{Val: lvlText}},
+		}},
+	}}
+	w.Numbering.Nums = &[]*Num{{
+		NumID:         "1",
+		AbstractNumID: &AbstractNumID{CommonAttrVal: &CommonAttrVal{Val: "0"}},
+	}}
+	for i := 0; i < 3; i++ {
+		p := w.AddParagraph()
+		p.Children = append(p.Children, &ParagraphProperties{
+			NumPr: &NumPr{Ilvl: &Ilvl{Val: 0}, NumID: &NumID{Val: 1}},
+		})
+		p.AddText("item")
+	}
+	return w
+}
+
+func numberedStrings(w *Docx) (s []string) {
+	for _, it := range w.Document.Body.Items {
+		if p, ok := it.(*Paragraph); ok {
+			s = append(s, p.String())
+		}
+	}
+	return
+}
+
+func TestNumberingPerDocument(t *testing.T) {
+	a := newNumberedDocx("%1.", "decimal")
+	b := newNumberedDocx("(%1)", "decimalFullWidth")
+	for i, s := range numberedStrings(a) {
+		if exp := strconv.Itoa(i+1) + ".item"; s != exp {
+			t.Fatal("expected", exp, "got", s)
+		}
+	}
+	for i, s := range numberedStrings(b) {
+		if exp := "(" + getDecimalFullWidth(i+1) + ")item"; s != exp {
+			t.Fatal("expected", exp, "got", s)
+		}
+	}
+	// counters continue until reset
+	if s := numberedStrings(a)[0]; s != "4.item" {
+		t.Fatal("expected 4.item got", s)
+	}
+	a.ResetNumbering()
+	if s := numberedStrings(a)[0]; s != "1.item" {
+		t.Fatal("expected 1.item got", s)
+	}
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w := newNumberedDocx("%1.", "decimal")
+			for i, s := range numberedStrings(w) {
+				if exp := strconv.Itoa(i+1) + ".item"; s != exp {
+					t.Error("expected", exp, "got", s)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -23,13 +23,46 @@ package docx
 import (
 	"archive/zip"
 	"bytes"
+	"compress/flate"
 	"encoding/xml"
 	"io"
-	"log"
 	"os"
 	"regexp"
+	"sort"
+	"time"
 )
 
+// packModTime is the fixed modification time of every part so that
+// the same document always packs into the same bytes
+var packModTime = time.Date(1980, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// partOrder returns the parts in writing order: [Content_Types].xml,
+// _rels/.rels and then the others by name
+func partOrder(files map[string]io.Reader) []string {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	rank := func(name string) int {
+		switch name {
+		case "[Content_Types].xml":
+			return 0
+		case "_rels/.rels":
+			return 1
+		default:
+			return 2
+		}
+	}
+	sort.Slice(names, func(i, j int) bool {
+		ri, rj := rank(names[i]), rank(names[j])
+		if ri != rj {
+			return ri < rj
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
 // pack receives a zip file writer (word documents are a zip with multiple xml inside)
 // and writes the relevant files. Some of them come from the empty_constants file,
 // others from the actual in-memory structure
@@ -40,41 +73,138 @@ func (f *Docx) pack(zipWriter *zip.Writer) (err error) {
 		for _, name := range f.tmpfslst {
 			files[name], err = TemplateXMLFS.Open("xml/" + f.template + "/" + name)
 			if err != nil {
-				return
+				return newPartError(name, err)
 			}
 		}
 	} else {
 		for _, name := range f.tmpfslst {
 			files[name], err = f.tmplfs.Open(name)
 			if err != nil {
-				return
+				return newPartError(name, err)
 			}
 		}
 	}
 
-	files["word/_rels/document.xml.rels"] = marshaller{data: &f.docRelation}
-	files["word/document.xml"] = marshaller{data: &f.Document}
 	files["word/numbering.xml"] = marshaller{data: &f.Numbering}
 
+	if f.styles != nil {
+		files[f.styles.name] = marshaller{data: f.styles}
+	}
+
+	// relationships moved into document.xml.rels from sub parts
+	moved := make(map[string]struct{}, 16)
+	for _, n := range []*Notes{f.footnotes, f.endnotes} {
+		if n == nil {
+			continue
+		}
+		err = f.packSubPart(files, n.name, n, n.rels, moved)
+		if err != nil {
+			return
+		}
+	}
+
+	if c := f.comments; c != nil {
+		// extended gives paraId to the comments, so it goes first
+		if ex := c.extended(); ex != nil {
+			files[c.extName] = marshaller{data: ex}
+		}
+		err = f.packSubPart(files, c.name, c, c.rels, moved)
+		if err != nil {
+			return
+		}
+	}
+
+	for _, h := range f.headers {
+		err = f.packSubPart(files, h.name, h, h.rels, moved)
+		if err != nil {
+			return
+		}
+	}
+	for _, ft := range f.footers {
+		err = f.packSubPart(files, ft.name, ft, ft.rels, moved)
+		if err != nil {
+			return
+		}
+	}
+
+	doc, ids, err := marshalPart(&f.Document)
+	if err != nil {
+		return newPartError("word/document.xml", err)
+	}
+	files["word/document.xml"] = bytes.NewReader(doc)
+	files["word/_rels/document.xml.rels"] = marshaller{data: f.docRelations(ids, moved)}
+
+	if r, ok := files["[Content_Types].xml"]; ok {
+		var ct ContentTypes
+		err = xml.NewDecoder(r).Decode(&ct)
+		if err != nil {
+			return newPartError("[Content_Types].xml", err)
+		}
+		f.registerContentTypes(&ct)
+		files["[Content_Types].xml"] = marshaller{data: &ct}
+	}
+
 	for _, m := range f.media {
 		files[m.String()] = bytes.NewReader(m.Data)
 	}
 
-	for path, r := range files {
-		w, err := zipWriter.Create(path)
+	if f.compset {
+		level := f.complevel
+		zipWriter.RegisterCompressor(zip.Deflate, func(w io.Writer) (io.WriteCloser, error) {
+			return flate.NewWriter(w, level)
+		})
+	}
+
+	for _, path := range partOrder(files) {
+		w, err := zipWriter.CreateHeader(&zip.FileHeader{
+			Name:     path,
+			Method:   zip.Deflate,
+			Modified: packModTime,
+		})
 		if err != nil {
-			return err
+			return newPartError(path, err)
 		}
 
-		_, err = io.Copy(w, r)
+		_, err = io.Copy(w, files[path])
 		if err != nil {
-			return err
+			return newPartError(path, err)
 		}
 	}
 
 	return
 }
 
+// packSubPart marshals a sub part into files with the .rels of
+// the relationships it refers to
+func (f *Docx) packSubPart(files map[string]io.Reader, name string, data interface{}, own []Relationship, moved map[string]struct{}) error {
+	part, ids, err := marshalPart(data)
+	if err != nil {
+		return newPartError(name, err)
+	}
+	files[name] = bytes.NewReader(part)
+	if rels := f.subRelations(ids); len(rels.Relationship) > 0 {
+		files[relsNameOf(name)] = marshaller{data: rels}
+	}
+	for _, r := range own {
+		moved[r.ID] = struct{}{}
+	}
+	return nil
+}
+
+// marshalPart marshals data and returns the rIds it refers to
+func marshalPart(data interface{}) ([]byte, map[string]struct{}, error) {
+	buf := bytes.NewBuffer(make([]byte, 0, 4096))
+	_, err := marshaller{data: data}.WriteTo(buf)
+	if err != nil {
+		return nil, nil, err
+	}
+	ids, err := referredRelations(buf.Bytes())
+	if err != nil {
+		return nil, nil, err
+	}
+	return buf.Bytes(), ids, nil
+}
+
 type marshaller struct {
 	data interface{}
 	io.Reader
@@ -99,7 +229,7 @@ func (m marshaller) WriteTo(w io.Writer) (n int64, err error) {
 	if _, ok := m.data.(*Document); ok {
 		marshalled, err := xml.Marshal(m.data)
 		if err != nil {
-			log.Fatal(err)
+			return 0, err
 		}
 
 		// 必要なし。VS Code のある Format Document を実施すると、namespace 文字列が消される、というだけだった。
@@ -111,16 +241,9 @@ func (m marshaller) WriteTo(w io.Writer) (n int64, err error) {
 
 		// w へ書き出す
 		_, err = w.Write(modifiedMarshalled)
-		if err != nil {
-			log.Fatal(err)
-		}
-	} else {
-		err = xml.NewEncoder(w).Encode(m.data)
-		if err != nil {
-			log.Fatal(err)
-		}
+		return 0, err
 	}
-
+	err = xml.NewEncoder(w).Encode(m.data)
 	return
 }
 
@@ -0,0 +1,107 @@
+/*
+   Copyright (c) 2020 gingfrederik
+   Copyright (c) 2021 Gonzalo Fernandez-Victorio
+   Copyright (c) 2021 Basement Crowd Ltd (https://www.basementcrowd.com)
+   Copyright (c) 2023 Fumiama Minamoto (源文雨)
+
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU Affero General Public License as published
+   by the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU Affero General Public License for more details.
+
+   You should have received a copy of the GNU Affero General Public License
+   along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package docx
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/flate"
+	"testing"
+)
+
+func TestDeterministicWriteTo(t *testing.T) {
+	build := func() *Docx {
+		w := NewA4()
+		w.AddParagraph().AddText("determinism").Bold()
+		_, err := w.AddParagraph().AddInlineDrawingFrom("testdata/fumiama.JPG")
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.AddTable(2, 2)
+		w.AddHeader(HDRFTR_DEFAULT).AddParagraph().AddText("header")
+		return w
+	}
+	buf1 := bytes.NewBuffer(make([]byte, 0, 1<<20))
+	_, err := build().WriteTo(buf1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf2 := bytes.NewBuffer(make([]byte, 0, 1<<20))
+	_, err = build().WriteTo(buf2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf1.Bytes(), buf2.Bytes()) {
+		t.Fatal("output is not reproducible")
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf1.Bytes()), int64(buf1.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if zr.File[0].Name != "[Content_Types].xml" {
+		t.Fatal("unexpected first part", zr.File[0].Name)
+	}
+	for i, f := range zr.File {
+		if !f.Modified.Equal(packModTime) {
+			t.Fatal("unexpected time", f.Name, f.Modified)
+		}
+		if i > 2 && zr.File[i-1].Name > f.Name {
+			t.Fatal("unsorted part", f.Name)
+		}
+	}
+
+	// parse and write back is reproducible as well
+	doc, err := Parse(bytes.NewReader(buf1.Bytes()), int64(buf1.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf2.Reset()
+	_, err = doc.WriteTo(buf2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf3 := bytes.NewBuffer(make([]byte, 0, 1<<20))
+	_, err = doc.WriteTo(buf3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf2.Bytes(), buf3.Bytes()) {
+		t.Fatal("parsed output is not reproducible")
+	}
+
+	w := build()
+	if w.SetCompressionLevel(10) != ErrInvalidCompressionLevel {
+		t.Fatal("invalid level accepted")
+	}
+	err = w.SetCompressionLevel(flate.NoCompression)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf3.Reset()
+	_, err = w.WriteTo(buf3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if buf3.Len() <= buf1.Len() {
+		t.Fatal("compression level is not applied", buf3.Len(), buf1.Len())
+	}
+}
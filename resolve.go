@@ -0,0 +1,364 @@
+/*
+   Copyright (c) 2020 gingfrederik
+   Copyright (c) 2021 Gonzalo Fernandez-Victorio
+   Copyright (c) 2021 Basement Crowd Ltd (https://www.basementcrowd.com)
+   Copyright (c) 2023 Fumiama Minamoto (源文雨)
+
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU Affero General Public License as published
+   by the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU Affero General Public License for more details.
+
+   You should have received a copy of the GNU Affero General Public License
+   along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package docx
+
+import (
+	"reflect"
+	"strconv"
+)
+
+// CellLocation locates a paragraph in a table, so that the
+// conditional formatting of the table style can be resolved
+type CellLocation struct {
+	Table    *Table
+	Row, Col int
+}
+
+// conditional formatting types of tblStylePr in the order they apply
+var tableStyleTypes = []string{
+	TBLSTYLE_WHOLE_TABLE,
+	TBLSTYLE_BAND1_VERT, TBLSTYLE_BAND2_VERT, TBLSTYLE_BAND1_HORZ, TBLSTYLE_BAND2_HORZ,
+	TBLSTYLE_FIRST_COL, TBLSTYLE_LAST_COL, TBLSTYLE_FIRST_ROW, TBLSTYLE_LAST_ROW,
+	TBLSTYLE_NE_CELL, TBLSTYLE_NW_CELL, TBLSTYLE_SE_CELL, TBLSTYLE_SW_CELL,
+}
+
+// EffectiveParagraphProperties resolves the properties of p in the order of
+// ECMA-376 17.7.2: document defaults, table style (with the conditional
+// formatting at cell), paragraph style with its basedOn chain and
+// direct formatting. cell is nil outside tables.
+func (f *Docx) EffectiveParagraphProperties(p *Paragraph, cell *CellLocation) *ParagraphProperties {
+	s := f.resolvingStyles()
+	pp := &ParagraphProperties{}
+	if s.DocDefaults != nil && s.DocDefaults.PPrDefault != nil {
+		overlay(pp, s.DocDefaults.PPrDefault.ParagraphProperties)
+	}
+	for _, l := range s.tableLayers(cell) {
+		overlay(pp, l.ParagraphProperties)
+	}
+	for _, st := range s.chain(STYLE_PARAGRAPH, paragraphStyleID(p)) {
+		overlay(pp, st.ParagraphProperties)
+	}
+	if p != nil {
+		overlay(pp, p.Properties)
+	}
+	pp.Style = nil
+	return pp
+}
+
+// EffectiveRunProperties resolves the properties of r in paragraph p in the
+// order of ECMA-376 17.7.2: document defaults, table style (with the conditional
+// formatting at cell), paragraph style, character style, each with its basedOn
+// chain, and direct formatting. cell is nil outside tables.
+func (f *Docx) EffectiveRunProperties(p *Paragraph, r *Run, cell *CellLocation) *RunProperties {
+	s := f.resolvingStyles()
+	rp := &RunProperties{}
+	if s.DocDefaults != nil && s.DocDefaults.RPrDefault != nil {
+		overlay(rp, s.DocDefaults.RPrDefault.RunProperties)
+	}
+	layer := &RunProperties{}
+	for _, l := range s.tableLayers(cell) {
+		overlay(layer, l.RunProperties)
+	}
+	overlayStyle(rp, layer)
+	layer = &RunProperties{}
+	for _, st := range s.chain(STYLE_PARAGRAPH, paragraphStyleID(p)) {
+		overlay(layer, st.RunProperties)
+	}
+	overlayStyle(rp, layer)
+	id := ""
+	if r != nil && r.RunProperties != nil && r.RunProperties.RunStyle != nil {
+		id = r.RunProperties.RunStyle.Val
+	}
+	layer = &RunProperties{}
+	for _, st := range s.chain(STYLE_CHARACTER, id) {
+		overlay(layer, st.RunProperties)
+	}
+	overlayStyle(rp, layer)
+	if r != nil {
+		overlay(rp, r.RunProperties)
+	}
+	rp.RunStyle = nil
+	rp.Style = nil
+	return rp
+}
+
+// MergeSameEffectivePropRuns merges the runs of p that have the same
+// EffectiveRunProperties, even if their direct properties differ
+func (f *Docx) MergeSameEffectivePropRuns(p *Paragraph, cell *CellLocation) RunMergeRule {
+	return func(r1, r2 *Run) bool {
+		if r1 == nil || r2 == nil {
+			return false
+		}
+		return MergeSamePropRuns(
+			&Run{RunProperties: f.EffectiveRunProperties(p, r1, cell)},
+			&Run{RunProperties: f.EffectiveRunProperties(p, r2, cell)},
+		)
+	}
+}
+
+// resolvingStyles returns the styles without changing the document,
+// so that resolvers can be called concurrently
+func (f *Docx) resolvingStyles() *Styles {
+	if f.styles != nil {
+		return f.styles
+	}
+	return f.loadTemplateStyles()
+}
+
+func paragraphStyleID(p *Paragraph) string {
+	if p == nil || p.Properties == nil || p.Properties.Style == nil {
+		return ""
+	}
+	return p.Properties.Style.Val
+}
+
+// chain returns the style of id and its basedOn ancestors, root first.
+// The default style of typ is used if id is empty or not found.
+func (s *Styles) chain(typ, id string) []*StyleDefinition {
+	var st *StyleDefinition
+	if id != "" {
+		st = s.Style(id)
+	}
+	if st == nil {
+		st = s.Default(typ)
+	}
+	chain := make([]*StyleDefinition, 0, 4)
+	for st != nil && len(chain) < 64 {
+		for _, x := range chain {
+			if x == st {
+				st = nil // loop in basedOn
+				break
+			}
+		}
+		if st == nil {
+			break
+		}
+		chain = append(chain, st)
+		if st.BasedOn == nil {
+			break
+		}
+		st = s.Style(st.BasedOn.Val)
+	}
+	for i, j := 0, len(chain)-1; i < j; i, j = i+1, j-1 {
+		chain[i], chain[j] = chain[j], chain[i]
+	}
+	return chain
+}
+
+// tableLayers returns the formatting of the table style applying to cell,
+// whole table first and then the conditional ones
+func (s *Styles) tableLayers(cell *CellLocation) []*TableStyleProperties {
+	if cell == nil || cell.Table == nil {
+		return nil
+	}
+	id := ""
+	tp := cell.Table.TableProperties
+	if tp != nil && tp.Style != nil {
+		id = tp.Style.Val
+	}
+	chain := s.chain(STYLE_TABLE, id)
+	if len(chain) == 0 {
+		return nil
+	}
+	conds := cell.conditions()
+	layers := make([]*TableStyleProperties, 0, 8)
+	for _, typ := range tableStyleTypes {
+		if !conds[typ] {
+			continue
+		}
+		for _, st := range chain {
+			if typ == TBLSTYLE_WHOLE_TABLE {
+				layers = append(layers, &TableStyleProperties{
+					Type:                typ,
+					ParagraphProperties: st.ParagraphProperties,
+					RunProperties:       st.RunProperties,
+					TableProperties:     st.TableProperties,
+					TableRowProperties:  st.TableRowProperties,
+					TableCellProperties: st.TableCellProperties,
+				})
+			}
+			for _, c := range st.TableStyleProperties {
+				if c.Type == typ {
+					layers = append(layers, c)
+				}
+			}
+		}
+	}
+	return layers
+}
+
+// look returns the tblLook flags of t
+func (t *Table) look() int {
+	if t.TableProperties == nil || t.TableProperties.Look == nil {
+		return 0
+	}
+	l := t.TableProperties.Look
+	flags := 0
+	for _, x := range []struct{ v, f int }{
+		{l.FirstRow, TBLLOOK_FIRST_ROW}, {l.LastRow, TBLLOOK_LAST_ROW},
+		{l.FirstCol, TBLLOOK_FIRST_COL}, {l.LastCol, TBLLOOK_LAST_COL},
+		{l.NoHBand, TBLLOOK_NO_HBAND}, {l.NoVBand, TBLLOOK_NO_VBAND},
+	} {
+		if x.v != 0 {
+			flags |= x.f
+		}
+	}
+	if flags == 0 && l.Val != "" {
+		v, err := strconv.ParseUint(l.Val, 16, 16)
+		if err == nil {
+			flags = int(v)
+		}
+	}
+	return flags
+}
+
+// conditions returns the tblStylePr types applying to the cell
+func (c *CellLocation) conditions() map[string]bool {
+	conds := map[string]bool{TBLSTYLE_WHOLE_TABLE: true}
+	rows := len(c.Table.TableRows)
+	cols := 0
+	if c.Row >= 0 && c.Row < rows {
+		cols = len(c.Table.TableRows[c.Row].TableCells)
+	}
+	look := c.Table.look()
+	firstRow := look&TBLLOOK_FIRST_ROW != 0 && c.Row == 0
+	lastRow := look&TBLLOOK_LAST_ROW != 0 && c.Row == rows-1
+	firstCol := look&TBLLOOK_FIRST_COL != 0 && c.Col == 0
+	lastCol := look&TBLLOOK_LAST_COL != 0 && c.Col == cols-1
+	conds[TBLSTYLE_FIRST_ROW] = firstRow
+	conds[TBLSTYLE_LAST_ROW] = lastRow
+	conds[TBLSTYLE_FIRST_COL] = firstCol
+	conds[TBLSTYLE_LAST_COL] = lastCol
+	conds[TBLSTYLE_NW_CELL] = firstRow && firstCol
+	conds[TBLSTYLE_NE_CELL] = firstRow && lastCol
+	conds[TBLSTYLE_SW_CELL] = lastRow && firstCol
+	conds[TBLSTYLE_SE_CELL] = lastRow && lastCol
+	if look&TBLLOOK_NO_HBAND == 0 && !firstRow && !lastRow {
+		r := c.Row
+		if look&TBLLOOK_FIRST_ROW != 0 {
+			r--
+		}
+		conds[TBLSTYLE_BAND1_HORZ] = r%2 == 0
+		conds[TBLSTYLE_BAND2_HORZ] = r%2 == 1
+	}
+	if look&TBLLOOK_NO_VBAND == 0 && !firstCol && !lastCol {
+		col := c.Col
+		if look&TBLLOOK_FIRST_COL != 0 {
+			col--
+		}
+		conds[TBLSTYLE_BAND1_VERT] = col%2 == 0
+		conds[TBLSTYLE_BAND2_VERT] = col%2 == 1
+	}
+	return conds
+}
+
+// overlay sets the properties that are set in src onto dst,
+// both being pointers to the same struct type.
+//
+// A property with only a Val like Bold and Size is replaced as a whole,
+// while others like Spacing and RunFonts are merged by attributes.
+func overlay(dst, src interface{}) {
+	s := reflect.ValueOf(src)
+	if s.IsNil() {
+		return
+	}
+	overlayValue(reflect.ValueOf(dst).Elem(), s.Elem())
+}
+
+func overlayValue(dst, src reflect.Value) {
+	t := src.Type()
+	for i := 0; i < t.NumField(); i++ {
+		ft := t.Field(i)
+		if !ft.IsExported() || ft.Name == "XMLName" {
+			continue
+		}
+		sf, df := src.Field(i), dst.Field(i)
+		if sf.IsZero() || isRevision(ft.Type) {
+			continue
+		}
+		if sf.Kind() != reflect.Pointer || sf.Elem().Kind() != reflect.Struct {
+			df.Set(sf)
+			continue
+		}
+		if isValueOnly(sf.Elem().Type()) {
+			v := reflect.New(sf.Elem().Type())
+			v.Elem().Set(sf.Elem())
+			df.Set(v)
+			continue
+		}
+		if df.IsNil() {
+			df.Set(reflect.New(sf.Elem().Type()))
+		}
+		overlayValue(df.Elem(), sf.Elem())
+	}
+}
+
+// toggleProperties are the run properties of ECMA-376 17.7.3
+var toggleProperties = []string{"Bold", "Italic", "Caps", "Strike"}
+
+// overlayStyle overlays the run properties src of one style type onto rp.
+// Unlike direct formatting, a toggle property turned on in src inverts
+// the one in rp, and turned off leaves it unchanged.
+func overlayStyle(rp, src *RunProperties) {
+	d := reflect.ValueOf(rp).Elem()
+	was := make([]bool, len(toggleProperties))
+	for i, name := range toggleProperties {
+		was[i] = toggleOn(d.FieldByName(name))
+	}
+	overlay(rp, src)
+	s := reflect.ValueOf(src).Elem()
+	for i, name := range toggleProperties {
+		if s.FieldByName(name).IsNil() {
+			continue
+		}
+		f := d.FieldByName(name)
+		v := reflect.New(f.Type().Elem())
+		if was[i] == toggleOn(s.FieldByName(name)) {
+			v.Elem().FieldByName("Val").SetString("0")
+		}
+		f.Set(v)
+	}
+}
+
+// toggleOn tells whether the toggle property v points to is on
+func toggleOn(v reflect.Value) bool {
+	return !v.IsNil() && isOn(v.Elem().FieldByName("Val").String())
+}
+
+// isRevision tells whether t points to a tracked change, which is not a property
+func isRevision(t reflect.Type) bool {
+	if t.Kind() != reflect.Pointer || t.Elem().Kind() != reflect.Struct {
+		return false
+	}
+	f, ok := t.Elem().FieldByName("Revision")
+	return ok && f.Anonymous
+}
+
+// isValueOnly tells whether t has no exported field but XMLName and Val
+func isValueOnly(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		ft := t.Field(i)
+		if ft.IsExported() && ft.Name != "XMLName" && ft.Name != "Val" {
+			return false
+		}
+	}
+	return true
+}
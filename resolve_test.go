@@ -0,0 +1,155 @@
+/*
+   Copyright (c) 2020 gingfrederik
+   Copyright (c) 2021 Gonzalo Fernandez-Victorio
+   Copyright (c) 2021 Basement Crowd Ltd (https://www.basementcrowd.com)
+   Copyright (c) 2023 Fumiama Minamoto (源文雨)
+
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU Affero General Public License as published
+   by the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU Affero General Public License for more details.
+
+   You should have received a copy of the GNU Affero General Public License
+   along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package docx
+
+import (
+	"encoding/xml"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestEffectiveProperties(t *testing.T) {
+	w := NewA4()
+	w.AddParagraphStyle("Big", "Big").Size("40").Color("FF0000").Justification("center")
+	w.AddParagraphStyle("Bigger", "Bigger").SetBasedOn("Big").Size("48")
+	w.AddCharacterStyle("Strong", "Strong").Bold()
+
+	p := w.AddParagraph()
+	if rp := w.EffectiveRunProperties(p, p.AddText("plain"), nil); rp.Size == nil || rp.Size.Val != "21" {
+		t.Fatal("document defaults not applied", rp.Size)
+	}
+
+	p = w.AddParagraph().Style("Bigger")
+	r1 := p.AddText("direct ").Bold()
+	r2 := p.AddText("styled").Style("Strong")
+	for _, r := range []*Run{r1, r2} {
+		rp := w.EffectiveRunProperties(p, r, nil)
+		if rp.Bold == nil || rp.Size.Val != "48" || rp.Color.Val != "FF0000" || rp.Fonts.AsciiTheme != "minorHAnsi" {
+			t.Fatal("unexpected properties", rp)
+		}
+	}
+	if pp := w.EffectiveParagraphProperties(p, nil); pp.Justification == nil || pp.Justification.Val != "center" {
+		t.Fatal("paragraph style not applied")
+	}
+	if n := len(p.MergeText(MergeSamePropRuns).Children); n != 2 {
+		t.Fatal("expected 2 runs but has", n)
+	}
+	if n := len(p.MergeText(w.MergeSameEffectivePropRuns(p, nil)).Children); n != 1 {
+		t.Fatal("expected 1 run but has", n)
+	}
+
+	// table style with conditional formatting
+	ts := w.AddTableStyle("Grid", "Grid").Italic()
+	ts.TableStyleProperties = append(ts.TableStyleProperties, &TableStyleProperties{
+		Type:          "firstRow",
+		RunProperties: &RunProperties{Bold: &Bold{}},
+	}, &TableStyleProperties{
+		Type:          "band2Horz",
+		RunProperties: &RunProperties{Color: &Color{Val: "0000FF"}},
+	})
+	tbl := w.AddTable(3, 2)
+	tbl.TableProperties.Style = &WTableStyle{Val: "Grid"}
+	tbl.TableProperties.Look = &WTableLook{Val: "04A0"}
+	for row := 0; row < 3; row++ {
+		cp := tbl.TableRows[row].TableCells[1].AddParagraph()
+		rp := w.EffectiveRunProperties(cp, cp.AddText("cell"), &CellLocation{Table: tbl, Row: row, Col: 1})
+		if rp.Italic == nil || (rp.Bold != nil) != (row == 0) || (rp.Color != nil) != (row == 2) {
+			t.Fatal("unexpected properties at row", row, rp)
+		}
+	}
+}
+
+func TestEffectiveToggleProperties(t *testing.T) {
+	w := NewA4()
+	w.AddParagraphStyle("Loud", "Loud").Bold().Italic()
+	w.AddCharacterStyle("Strong", "Strong").Bold()
+	w.AddCharacterStyle("Quiet", "Quiet").runProperties().Italic = &Italic{Val: "false"}
+
+	p := w.AddParagraph().Style("Loud")
+	rp := w.EffectiveRunProperties(p, p.AddText("strong").Style("Strong"), nil)
+	if toggleOn(reflect.ValueOf(rp.Bold)) || !toggleOn(reflect.ValueOf(rp.Italic)) {
+		t.Fatal("bold is not toggled off by the character style", rp.Bold, rp.Italic)
+	}
+	rp = w.EffectiveRunProperties(p, p.AddText("quiet").Style("Quiet"), nil)
+	if !toggleOn(reflect.ValueOf(rp.Italic)) {
+		t.Fatal("italic is turned off by a style", rp.Italic)
+	}
+
+	var r Run
+	err := xml.Unmarshal([]byte(`<w:r><w:rPr><w:b w:val="0"/><w:i w:val="false"/><w:caps/></w:rPr><w:t>off</w:t></w:r>`), &r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.RunProperties.Bold.Val != "0" || r.RunProperties.Italic.Val != "false" || r.RunProperties.Caps == nil {
+		t.Fatal("unexpected properties", r.RunProperties)
+	}
+	p = w.AddParagraph().Style("Loud")
+	r.file = w
+	p.Children = append(p.Children, &r)
+	rp = w.EffectiveRunProperties(p, &r, nil)
+	if toggleOn(reflect.ValueOf(rp.Bold)) || toggleOn(reflect.ValueOf(rp.Italic)) {
+		t.Fatal("direct formatting does not turn off", rp.Bold, rp.Italic)
+	}
+
+	sb := strings.Builder{}
+	err = w.MarshalMarkdown(&sb, MarkdownOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(strings.TrimSpace(sb.String()), "\n\noff") {
+		t.Fatal("unexpected markdown", sb.String())
+	}
+	sb.Reset()
+	err = w.WriteHTML(&sb, HTMLOptions{Fragment: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(sb.String(), `<span style="font-size:10.5pt;text-transform:uppercase">off</span>`) {
+		t.Fatal("unexpected html", sb.String())
+	}
+}
+
+func TestResolveDoesNotChangeDocument(t *testing.T) {
+	w := NewA4()
+	p := w.AddParagraph()
+	r := p.AddText("text")
+	nrels := len(w.docRelation.Relationship)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if rp := w.EffectiveRunProperties(p, r, nil); rp.Size == nil || rp.Size.Val != "21" {
+				t.Error("template defaults not applied", rp.Size)
+			}
+		}()
+	}
+	wg.Wait()
+	if w.styles != nil || len(w.docRelation.Relationship) != nrels {
+		t.Fatal("resolving changed the document")
+	}
+	if w.Styles() != w.tmplStyles {
+		t.Fatal("parsed template styles are not reused")
+	}
+}
@@ -23,7 +23,6 @@ package docx
 import (
 	"encoding/xml"
 	"io"
-	"strings"
 )
 
 // WordprocessingCanvas ...
@@ -53,27 +52,27 @@ func (c *WordprocessingCanvas) UnmarshalXML(d *xml.Decoder, _ xml.StartElement)
 			case "bg":
 				c.Background = new(WPCBackground)
 				err = d.DecodeElement(c.Background, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 			case "whole":
 				c.Whole = new(WPCWhole)
 				err = d.DecodeElement(c.Whole, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 			case "wsp":
 				var value WordprocessingShape
 				value.file = c.file
 				err = d.DecodeElement(&value, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 				c.Items = append(c.Items, &value)
 			case "pic":
 				var value Picture
 				err = d.DecodeElement(&value, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 				value.XMLPIC = getAtt(tt.Attr, "pic")
@@ -82,7 +81,7 @@ func (c *WordprocessingCanvas) UnmarshalXML(d *xml.Decoder, _ xml.StartElement)
 				var value WordprocessingGroup
 				value.file = c.file
 				err = d.DecodeElement(&value, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 				c.Items = append(c.Items, &value)
@@ -153,7 +152,7 @@ func (w *WPCWhole) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) (err error)
 			case "ln":
 				w.Line = new(ALine)
 				err = d.DecodeElement(w.Line, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 			default:
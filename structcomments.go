@@ -0,0 +1,339 @@
+/*
+   Copyright (c) 2020 gingfrederik
+   Copyright (c) 2021 Gonzalo Fernandez-Victorio
+   Copyright (c) 2021 Basement Crowd Ltd (https://www.basementcrowd.com)
+   Copyright (c) 2023 Fumiama Minamoto (源文雨)
+
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU Affero General Public License as published
+   by the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU Affero General Public License for more details.
+
+   You should have received a copy of the GNU Affero General Public License
+   along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package docx
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Comments <w:comments> is word/comments.xml
+type Comments struct {
+	XMLName xml.Name `xml:"w:comments"`
+	XMLW    string   `xml:"xmlns:w,attr"`             // cannot be unmarshalled in
+	XMLW14  string   `xml:"xmlns:w14,attr,omitempty"` // cannot be unmarshalled in
+	XMLR    string   `xml:"xmlns:r,attr,omitempty"`   // cannot be unmarshalled in
+	XMLWP   string   `xml:"xmlns:wp,attr,omitempty"`  // cannot be unmarshalled in
+	XMLWPS  string   `xml:"xmlns:wps,attr,omitempty"` // cannot be unmarshalled in
+	XMLWPC  string   `xml:"xmlns:wpc,attr,omitempty"` // cannot be unmarshalled in
+	XMLWPG  string   `xml:"xmlns:wpg,attr,omitempty"` // cannot be unmarshalled in
+
+	// Attrs are the extra namespaces declared in source, only in preserve mode
+	Attrs []xml.Attr `xml:",any,attr"`
+
+	Comments []*Comment
+
+	file *Docx
+	// name is the path in zip like word/comments.xml
+	name string
+	// extName is the path of word/commentsExtended.xml, empty if not exist
+	extName string
+	// rels are the relationships read from its own .rels,
+	// already renumbered into document rId space
+	rels []Relationship
+}
+
+// Comment <w:comment> is the body of a comment
+type Comment struct {
+	XMLName  xml.Name `xml:"w:comment"`
+	ID       int      `xml:"w:id,attr"`
+	Author   string   `xml:"w:author,attr,omitempty"`
+	Date     string   `xml:"w:date,attr,omitempty"`
+	Initials string   `xml:"w:initials,attr,omitempty"`
+
+	Items []interface{}
+
+	// Parent is the comment replied to, read from commentsExtended.xml
+	Parent *Comment `xml:"-"`
+	// Done marks the comment as resolved, read from commentsExtended.xml
+	Done bool `xml:"-"`
+
+	file *Docx
+}
+
+// CommentRangeStart <w:commentRangeStart> marks the beginning of commented text
+type CommentRangeStart struct {
+	XMLName xml.Name `xml:"w:commentRangeStart"`
+	ID      int      `xml:"w:id,attr"`
+}
+
+// CommentRangeEnd <w:commentRangeEnd> marks the end of commented text
+type CommentRangeEnd struct {
+	XMLName xml.Name `xml:"w:commentRangeEnd"`
+	ID      int      `xml:"w:id,attr"`
+}
+
+// CommentReference <w:commentReference> is the mark of a comment in text
+type CommentReference struct {
+	XMLName xml.Name `xml:"w:commentReference"`
+	ID      int      `xml:"w:id,attr"`
+}
+
+// AnnotationRef <w:annotationRef> is the mark at the beginning of a comment
+type AnnotationRef struct {
+	XMLName xml.Name `xml:"w:annotationRef"`
+}
+
+func newComments(f *Docx) *Comments {
+	c := &Comments{file: f}
+	c.XMLW = XMLNS_W
+	c.XMLW14 = XMLNS_W14
+	c.XMLR = XMLNS_R
+	c.XMLWP = XMLNS_WP
+	c.XMLWPS = XMLNS_WPS
+	c.XMLWPC = XMLNS_WPC
+	c.XMLWPG = XMLNS_WPG
+	return c
+}
+
+// UnmarshalXML ...
+func (c *Comments) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	if ctx := contextOf(d); ctx.preserve() {
+		c.Attrs = ctx.rootAttrs(start.Attr, XMLNS_W, XMLNS_W14, XMLNS_R, XMLNS_WP, XMLNS_WPS, XMLNS_WPC, XMLNS_WPG)
+		for _, a := range start.Attr {
+			if a.Name.Space == XMLNS_MC && a.Name.Local == "Ignorable" {
+				c.Attrs = append(c.Attrs, xml.Attr{Name: xml.Name{Local: "mc:Ignorable"}, Value: a.Value})
+			}
+		}
+	}
+	for {
+		t, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		tt, ok := t.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if tt.Name.Local != "comment" {
+			logln(d, "UnmarshalXML Comments unsupported, skip:", tt.Name.Local)
+			err = d.Skip()
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		cm := &Comment{file: c.file}
+		err = d.DecodeElement(cm, &tt)
+		if err != nil && !ignorable(d, err) {
+			return err
+		}
+		c.Comments = append(c.Comments, cm)
+	}
+	return nil
+}
+
+// UnmarshalXML ...
+func (c *Comment) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	for _, a := range start.Attr {
+		switch a.Name.Local {
+		case "id":
+			id, err := strconv.Atoi(a.Value)
+			if err != nil {
+				return err
+			}
+			c.ID = id
+		case "author":
+			c.Author = a.Value
+		case "date":
+			c.Date = a.Value
+		case "initials":
+			c.Initials = a.Value
+		}
+	}
+	b := Body{file: c.file}
+	err := b.UnmarshalXML(d, start)
+	if err != nil && !ignorable(d, err) {
+		return err
+	}
+	c.Items = b.Items
+	return nil
+}
+
+// commentsEx <w15:commentsEx> is word/commentsExtended.xml
+type commentsEx struct {
+	XMLName     xml.Name `xml:"w15:commentsEx"`
+	XMLW15      string   `xml:"xmlns:w15,attr"`
+	XMLMC       string   `xml:"xmlns:mc,attr"`
+	MCIgnorable string   `xml:"mc:Ignorable,attr"`
+
+	Items []commentEx
+}
+
+// commentEx <w15:commentEx> links a comment to the one it replies to
+type commentEx struct {
+	XMLName      xml.Name `xml:"w15:commentEx"`
+	ParaID       string   `xml:"w15:paraId,attr"`
+	ParaIDParent string   `xml:"w15:paraIdParent,attr,omitempty"`
+	Done         string   `xml:"w15:done,attr,omitempty"`
+}
+
+// UnmarshalXML ...
+func (c *commentsEx) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) error {
+	for {
+		t, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		tt, ok := t.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if tt.Name.Local == "commentEx" {
+			c.Items = append(c.Items, commentEx{
+				ParaID:       getAtt(tt.Attr, "paraId"),
+				ParaIDParent: getAtt(tt.Attr, "paraIdParent"),
+				Done:         getAtt(tt.Attr, "done"),
+			})
+		}
+		err = d.Skip()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseCommentRange reads <w:commentRangeStart> or <w:commentRangeEnd>
+func parseCommentRange(d *xml.Decoder, tt xml.StartElement) (interface{}, error) {
+	id, err := strconv.Atoi(getAtt(tt.Attr, "id"))
+	if err != nil {
+		return nil, err
+	}
+	err = d.Skip()
+	if err != nil {
+		return nil, err
+	}
+	if tt.Name.Local == "commentRangeStart" {
+		return &CommentRangeStart{ID: id}, nil
+	}
+	return &CommentRangeEnd{ID: id}, nil
+}
+
+// lastParagraph returns the paragraph whose paraId identifies the comment
+func (c *Comment) lastParagraph() *Paragraph {
+	for i := len(c.Items) - 1; i >= 0; i-- {
+		if p, ok := c.Items[i].(*Paragraph); ok {
+			return p
+		}
+	}
+	return nil
+}
+
+// link sets Parent and Done of comments from commentsExtended.xml
+func (c *Comments) link(ex *commentsEx) {
+	byPara := make(map[string]*Comment, len(c.Comments))
+	for _, cm := range c.Comments {
+		if p := cm.lastParagraph(); p != nil && p.ParaId != "" {
+			byPara[p.ParaId] = cm
+		}
+	}
+	for _, x := range ex.Items {
+		cm, ok := byPara[x.ParaID]
+		if !ok {
+			continue
+		}
+		cm.Done = x.Done == "1"
+		if x.ParaIDParent != "" {
+			cm.Parent = byPara[x.ParaIDParent]
+		}
+	}
+}
+
+// extended builds commentsExtended.xml, adding the part if any
+// comment is a reply or done, or nil if it is not needed
+//
+//	this func is not thread-safe
+func (c *Comments) extended() *commentsEx {
+	if c.extName == "" {
+		needed := false
+		for _, cm := range c.Comments {
+			if cm.Parent != nil || cm.Done {
+				needed = true
+				break
+			}
+		}
+		if !needed {
+			return nil
+		}
+		name := "word/commentsExtended.xml"
+		if c.file.hasPart(name) {
+			name = c.file.newPartName("commentsExtended")
+		}
+		c.file.addPartRelation(REL_COMMENTS_EXTENDED, name)
+		c.extName = name
+	}
+	used := make(map[string]struct{}, len(c.Comments))
+	for _, cm := range c.Comments {
+		if p := cm.lastParagraph(); p != nil && p.ParaId != "" {
+			used[p.ParaId] = struct{}{}
+		}
+	}
+	// paraId must be less than 0x80000000
+	next := uint64(0x10000000)
+	for _, cm := range c.Comments {
+		p := cm.lastParagraph()
+		if p == nil {
+			p = cm.AddParagraph()
+		}
+		if p.ParaId != "" {
+			continue
+		}
+		for {
+			id := strings.ToUpper(strconv.FormatUint(next, 16))
+			next++
+			if _, ok := used[id]; !ok {
+				p.ParaId = id
+				used[id] = struct{}{}
+				break
+			}
+		}
+	}
+	ex := &commentsEx{
+		XMLW15:      XMLNS_W15,
+		XMLMC:       XMLNS_MC,
+		MCIgnorable: "w15",
+		Items:       make([]commentEx, 0, len(c.Comments)),
+	}
+	for _, cm := range c.Comments {
+		x := commentEx{ParaID: cm.lastParagraph().ParaId}
+		if cm.Parent != nil {
+			if p := cm.Parent.lastParagraph(); p != nil {
+				x.ParaIDParent = p.ParaId
+			}
+		}
+		if cm.Done {
+			x.Done = "1"
+		} else {
+			x.Done = "0"
+		}
+		ex.Items = append(ex.Items, x)
+	}
+	return ex
+}
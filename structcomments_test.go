@@ -0,0 +1,110 @@
+/*
+   Copyright (c) 2020 gingfrederik
+   Copyright (c) 2021 Gonzalo Fernandez-Victorio
+   Copyright (c) 2021 Basement Crowd Ltd (https://www.basementcrowd.com)
+   Copyright (c) 2023 Fumiama Minamoto (源文雨)
+
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU Affero General Public License as published
+   by the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU Affero General Public License for more details.
+
+   You should have received a copy of the GNU Affero General Public License
+   along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package docx
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestComments(t *testing.T) {
+	w := NewA4()
+	p := w.AddParagraph()
+	p.AddText("The ")
+	r := p.AddText("quick")
+	p.AddText(" fox.")
+	c := p.AddComment(r, r, "Lint Bot", "Avoid adjectives.\nSee guide.")
+	if c == nil || c.ID != 0 || c.Initials != "LB" {
+		t.Fatal("unexpected comment", c)
+	}
+	if p.AddComment(&Run{}, nil, "x", "y") != nil {
+		t.Fatal("comment on a foreign run")
+	}
+	reply := c.Reply("Jane Doe", "Done.")
+	c.Done = true
+	if reply.ID != 1 || c.Anchor() != "quick" || reply.Anchor() != "quick" {
+		t.Fatal("unexpected reply", reply.ID, c.Anchor(), reply.Anchor())
+	}
+	if p.String() != "The quick fox." {
+		t.Fatal("unexpected text", p.String())
+	}
+
+	buf := bytes.NewBuffer(make([]byte, 0, 1024*1024))
+	_, err := w.WriteTo(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := Parse(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Comments()) != 2 {
+		t.Fatal("unexpected comments", len(doc.Comments()))
+	}
+	c, reply = doc.Comment(0), doc.Comment(1)
+	if c.Author != "Lint Bot" || c.String() != "Avoid adjectives.\nSee guide." || c.Anchor() != "quick" {
+		t.Fatal("unexpected comment", c.Author, c.String(), c.Anchor())
+	}
+	if reply.Parent != c || !c.Done || reply.Done || len(c.Replies()) != 1 {
+		t.Fatal("replies not linked")
+	}
+	if reply.Anchor() != "quick" || reply.String() != "Done." {
+		t.Fatal("unexpected reply", reply.Anchor(), reply.String())
+	}
+	ct := readZipFile(t, buf.Bytes(), "[Content_Types].xml")
+	if !bytes.Contains(ct, []byte(CONTENT_TYPE_COMMENTS)) || !bytes.Contains(ct, []byte(CONTENT_TYPE_COMMENTS_EXTENDED)) {
+		t.Fatal("no content types", string(ct))
+	}
+
+	// round-trip keeps comments as they are
+	buf2 := bytes.NewBuffer(make([]byte, 0, 1024*1024))
+	_, err = doc.WriteTo(buf2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"word/comments.xml", "word/commentsExtended.xml"} {
+		if !bytes.Equal(readZipFile(t, buf.Bytes(), name), readZipFile(t, buf2.Bytes(), name)) {
+			t.Fatal(name, "changed")
+		}
+	}
+}
+
+func TestCommentReplyInHeader(t *testing.T) {
+	w := NewA4()
+	p := w.AddHeader(HDRFTR_DEFAULT).AddParagraph()
+	r := p.AddText("Draft")
+	c := p.AddComment(r, r, "Lint Bot", "Remove before release.")
+	reply := c.Reply("Jane Doe", "Will do.")
+	if c.Anchor() != "Draft" || reply.Anchor() != "Draft" {
+		t.Fatal("unexpected anchors", c.Anchor(), reply.Anchor())
+	}
+	refs := 0
+	for _, x := range p.Children {
+		if run, ok := x.(*Run); ok && len(run.Children) == 1 {
+			if ref, ok := run.Children[0].(*CommentReference); ok && ref.ID == reply.ID {
+				refs++
+			}
+		}
+	}
+	if refs != 1 {
+		t.Fatal("reply reference not in header", refs)
+	}
+}
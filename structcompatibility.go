@@ -35,7 +35,7 @@ func (a *AlternateContent) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) erro
 				var value MCChoice
 				value.file = a.file
 				err = d.DecodeElement(&value, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 				a.Choice = &value
@@ -86,7 +86,7 @@ func (c *MCChoice) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) error {
 				var value Drawing
 				value.file = c.file
 				err = d.DecodeElement(&value, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 				c.Elems = append(c.Elems, &value)
@@ -0,0 +1,165 @@
+/*
+   Copyright (c) 2020 gingfrederik
+   Copyright (c) 2021 Gonzalo Fernandez-Victorio
+   Copyright (c) 2021 Basement Crowd Ltd (https://www.basementcrowd.com)
+   Copyright (c) 2023 Fumiama Minamoto (源文雨)
+
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU Affero General Public License as published
+   by the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU Affero General Public License for more details.
+
+   You should have received a copy of the GNU Affero General Public License
+   along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package docx
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+)
+
+//nolint:revive,stylecheck
+const (
+	XMLNS_CONTENT_TYPES = `http://schemas.openxmlformats.org/package/2006/content-types`
+
+	CONTENT_TYPE_HEADER = `application/vnd.openxmlformats-officedocument.wordprocessingml.header+xml`
+	CONTENT_TYPE_FOOTER = `application/vnd.openxmlformats-officedocument.wordprocessingml.footer+xml`
+	CONTENT_TYPE_STYLES = `application/vnd.openxmlformats-officedocument.wordprocessingml.styles+xml`
+
+	CONTENT_TYPE_FOOTNOTES = `application/vnd.openxmlformats-officedocument.wordprocessingml.footnotes+xml`
+	CONTENT_TYPE_ENDNOTES  = `application/vnd.openxmlformats-officedocument.wordprocessingml.endnotes+xml`
+	CONTENT_TYPE_COMMENTS  = `application/vnd.openxmlformats-officedocument.wordprocessingml.comments+xml`
+
+	CONTENT_TYPE_COMMENTS_EXTENDED = `application/vnd.openxmlformats-officedocument.wordprocessingml.commentsExtended+xml`
+)
+
+// ContentTypes is [Content_Types].xml
+type ContentTypes struct {
+	XMLName   xml.Name `xml:"Types"`
+	Xmlns     string   `xml:"xmlns,attr"`
+	Defaults  []ContentTypeDefault
+	Overrides []ContentTypeOverride
+}
+
+// ContentTypeDefault maps a file extension to its content type
+type ContentTypeDefault struct {
+	XMLName     xml.Name `xml:"Default"`
+	Extension   string   `xml:"Extension,attr"`
+	ContentType string   `xml:"ContentType,attr"`
+}
+
+// ContentTypeOverride sets the content type of a single part
+type ContentTypeOverride struct {
+	XMLName     xml.Name `xml:"Override"`
+	PartName    string   `xml:"PartName,attr"`
+	ContentType string   `xml:"ContentType,attr"`
+}
+
+// UnmarshalXML ...
+func (c *ContentTypes) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) error {
+	c.Xmlns = XMLNS_CONTENT_TYPES
+	for {
+		t, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if tt, ok := t.(xml.StartElement); ok {
+			switch tt.Name.Local {
+			case "Default":
+				c.Defaults = append(c.Defaults, ContentTypeDefault{
+					Extension:   getAtt(tt.Attr, "Extension"),
+					ContentType: getAtt(tt.Attr, "ContentType"),
+				})
+			case "Override":
+				c.Overrides = append(c.Overrides, ContentTypeOverride{
+					PartName:    getAtt(tt.Attr, "PartName"),
+					ContentType: getAtt(tt.Attr, "ContentType"),
+				})
+			}
+			err = d.Skip()
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// AddDefault registers the content type of an extension if it is not present
+func (c *ContentTypes) AddDefault(ext, contentType string) {
+	ext = strings.ToLower(ext)
+	for _, d := range c.Defaults {
+		if strings.ToLower(d.Extension) == ext {
+			return
+		}
+	}
+	c.Defaults = append(c.Defaults, ContentTypeDefault{Extension: ext, ContentType: contentType})
+}
+
+// AddOverride registers or replaces the content type of a part
+//
+//	partName is the path in zip like word/header1.xml
+func (c *ContentTypes) AddOverride(partName, contentType string) {
+	if !strings.HasPrefix(partName, "/") {
+		partName = "/" + partName
+	}
+	for i, o := range c.Overrides {
+		if o.PartName == partName {
+			c.Overrides[i].ContentType = contentType
+			return
+		}
+	}
+	c.Overrides = append(c.Overrides, ContentTypeOverride{PartName: partName, ContentType: contentType})
+}
+
+// registerContentTypes adds the parts and media generated from memory
+// into the content types read from template
+func (f *Docx) registerContentTypes(c *ContentTypes) {
+	for _, m := range f.media {
+		i := strings.LastIndex(m.Name, ".")
+		if i < 0 {
+			continue
+		}
+		ext := strings.ToLower(m.Name[i+1:])
+		switch ext {
+		case "jpg", "jpeg":
+			c.AddDefault(ext, "image/jpeg")
+		case "svg":
+			c.AddDefault(ext, "image/svg+xml")
+		default:
+			c.AddDefault(ext, "image/"+ext)
+		}
+	}
+	for _, h := range f.headers {
+		c.AddOverride(h.name, CONTENT_TYPE_HEADER)
+	}
+	for _, ft := range f.footers {
+		c.AddOverride(ft.name, CONTENT_TYPE_FOOTER)
+	}
+	if f.styles != nil {
+		c.AddOverride(f.styles.name, CONTENT_TYPE_STYLES)
+	}
+	if f.footnotes != nil {
+		c.AddOverride(f.footnotes.name, CONTENT_TYPE_FOOTNOTES)
+	}
+	if f.endnotes != nil {
+		c.AddOverride(f.endnotes.name, CONTENT_TYPE_ENDNOTES)
+	}
+	if f.comments != nil {
+		c.AddOverride(f.comments.name, CONTENT_TYPE_COMMENTS)
+		if f.comments.extName != "" {
+			c.AddOverride(f.comments.extName, CONTENT_TYPE_COMMENTS_EXTENDED)
+		}
+	}
+}
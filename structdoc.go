@@ -23,10 +23,7 @@ package docx
 import (
 	"encoding/xml"
 	"io"
-	"log"
-	"reflect"
 	"regexp"
-	"strings"
 )
 
 //nolint:revive,stylecheck
@@ -84,7 +81,7 @@ func (b *Body) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) error {
 				var value Paragraph
 				value.file = b.file
 				err = d.DecodeElement(&value, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 				b.Items = append(b.Items, &value)
@@ -92,14 +89,14 @@ func (b *Body) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) error {
 				var value Table
 				value.file = b.file
 				err = d.DecodeElement(&value, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 				b.Items = append(b.Items, &value)
 			case "sdt":
 				var value StructuredDocumentTag
-				err = d.DecodeElement(&value, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				value.preserved, err = decodePreserved(d, &tt, &value)
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 				b.Items = append(b.Items, &value)
@@ -107,8 +104,8 @@ func (b *Body) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) error {
 			case "sectPr":
 				// SectionProperties
 				var value SectPr
-				err = d.DecodeElement(&value, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				value.preserved, err = decodePreserved(d, &tt, &value)
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 				b.Items = append(b.Items, &value)
@@ -120,8 +117,22 @@ func (b *Body) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) error {
 				}
 				value.Val = v
 				b.Items = append(b.Items, &value)
+			case "commentRangeStart", "commentRangeEnd":
+				value, err := parseCommentRange(d, tt)
+				if err != nil {
+					return err
+				}
+				b.Items = append(b.Items, value)
 			default:
-				log.Println("Unsupported tag in doc body: ", tt.Name.Local)
+				if contextOf(d).preserve() {
+					value, err := decodeRaw(d, tt)
+					if err != nil {
+						return err
+					}
+					b.Items = append(b.Items, value)
+					continue
+				}
+				logln(d, "Unsupported tag in doc body: ", tt.Name.Local)
 				err = d.Skip() // skip unsupported tags
 				if err != nil {
 					return err
@@ -132,49 +143,58 @@ func (b *Body) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) error {
 	return nil
 }
 
+// lastSectPr returns the trailing section properties of body, or nil
+func (b *Body) lastSectPr() *SectPr {
+	if len(b.Items) == 0 {
+		return nil
+	}
+	sp, _ := b.Items[len(b.Items)-1].(*SectPr)
+	return sp
+}
+
+// sectPr returns the trailing section properties of body,
+// and creates an A4 one if it does not exist
+func (b *Body) sectPr() *SectPr {
+	if sp := b.lastSectPr(); sp != nil {
+		return sp
+	}
+	sp := &SectPr{
+		PgSz: &PgSz{W: "11906", H: "16838"},
+		PgMar: &PgMar{
+			Top: "1440", Right: "1800", Bottom: "1440", Left: "1800",
+			Header: "851", Footer: "992", Gutter: "0",
+		},
+	}
+	b.Items = append(b.Items, sp)
+	return sp
+}
+
+// append adds item to body, before the trailing section properties
+func (b *Body) append(item interface{}) {
+	n := len(b.Items)
+	if b.lastSectPr() == nil {
+		b.Items = append(b.Items, item)
+		return
+	}
+	b.Items = append(b.Items, b.Items[n-1])
+	b.Items[n-1] = item
+}
+
 // KeepElements keep named elems amd removes others
 //
 // names: *docx.Paragraph *docx.Table
 func (b *Body) KeepElements(name ...string) {
-	items := make([]interface{}, 0, len(b.Items))
-	namemap := make(map[string]struct{}, len(name)*2)
-	for _, n := range name {
-		namemap[n] = struct{}{}
-	}
-	for _, item := range b.Items {
-		_, ok := namemap[reflect.ValueOf(item).Type().String()]
-		if ok {
-			items = append(items, item)
-		}
-	}
-	b.Items = items
+	keepElements(b, name)
 }
 
 // DropDrawingOf drops all matched drawing in body
 // name: Canvas, Shape, Group, ShapeAndCanvas, ShapeAndCanvasAndGroup, NilPicture
 func (b *Body) DropDrawingOf(name string) {
-	for _, item := range b.Items {
-		switch o := item.(type) {
-		case *Paragraph:
-			f := reflect.ValueOf(o).MethodByName("Drop" + name)
-			if !f.IsValid() {
-				continue
-			}
-			_ = f.Call(nil)
-		case *Table:
-			for _, tr := range o.TableRows {
-				for _, tc := range tr.TableCells {
-					for _, p := range tc.Paragraphs {
-						f := reflect.ValueOf(p).MethodByName("Drop" + name)
-						if !f.IsValid() {
-							continue
-						}
-						_ = f.Call(nil)
-					}
-				}
-			}
-		}
+	rule, ok := drawingRules[name]
+	if !ok {
+		return
 	}
+	dropDrawings(b, rule)
 }
 
 // Document <w:document>
@@ -197,11 +217,27 @@ type Document struct {
 
 	MCIgnorable string `xml:"mc:Ignorable,attr,omitempty"`
 
+	// Attrs are the extra namespaces declared in source, only in preserve mode
+	Attrs []xml.Attr `xml:",any,attr"`
+
+	// Background <w:background> is kept as is in preserve mode
+	Background *RawXML
+
 	Body Body `xml:"w:body"`
 }
 
 // UnmarshalXML ...
-func (doc *Document) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) error {
+func (doc *Document) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	ctx := contextOf(d)
+	if ctx.preserve() {
+		doc.Attrs = ctx.rootAttrs(start.Attr, XMLNS_W, XMLNS_W10, XMLNS_W14, XMLNS_W15, XMLNS_R,
+			XMLNS_WP, XMLNS_WPS, XMLNS_WPC, XMLNS_WPG, XMLNS_MC, XMLNS_WP14, XMLNS_O, XMLNS_V)
+		for _, a := range start.Attr {
+			if a.Name.Space == XMLNS_MC && a.Name.Local == "Ignorable" {
+				doc.MCIgnorable = a.Value
+			}
+		}
+	}
 	for {
 		t, err := d.Token()
 		if err == io.EOF {
@@ -214,7 +250,14 @@ func (doc *Document) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) error {
 		if tt, ok := t.(xml.StartElement); ok {
 			if tt.Name.Local == "body" {
 				err = d.DecodeElement(&doc.Body, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err != nil && !ignorable(d, err) {
+					return err
+				}
+				continue
+			}
+			if tt.Name.Local == "background" && ctx.preserve() {
+				doc.Background, err = decodeRaw(d, tt)
+				if err != nil {
 					return err
 				}
 				continue
@@ -241,7 +284,8 @@ func SplitDocxByPlainTextRegex(re *regexp.Regexp) ParagraphSplitRule {
 // SplitByParagraph splits a doc to many docs by using a matched paragraph
 // as the separator.
 //
-// The separator will be placed to the first doc item
+// The separator will be placed to the first doc item. The references
+// that cannot be moved into a new doc, like charts, are left empty.
 func (f *Docx) SplitByParagraph(separator ParagraphSplitRule) (docs []*Docx) {
 	items := f.Document.Body.Items
 newdoclop:
@@ -300,11 +344,9 @@ newdoclop:
 					docs = append(docs, ndoc)
 					continue newdoclop
 				}
-				np := o.copymedia(ndoc)
-				ndoc.Document.Body.Items = append(ndoc.Document.Body.Items, &np)
+				ndoc.Document.Body.Items = append(ndoc.Document.Body.Items, newCloner(f, ndoc).clone(o))
 			case *Table:
-				nt := o.copymedia(ndoc)
-				ndoc.Document.Body.Items = append(ndoc.Document.Body.Items, &nt)
+				ndoc.Document.Body.Items = append(ndoc.Document.Body.Items, newCloner(f, ndoc).clone(o))
 			default:
 				ndoc.Document.Body.Items = append(ndoc.Document.Body.Items, o)
 			}
@@ -318,86 +360,15 @@ newdoclop:
 	return
 }
 
-func (r *Run) copymedia(to *Docx) *Run {
-	nr := *r
-	nr.Children = make([]interface{}, 0, len(r.Children))
-	nr.file = to
-	for _, rc := range r.Children {
-		if d, ok := rc.(*Drawing); ok {
-			nr.Children = append(nr.Children, d.copymedia(to))
-			continue
-		}
-		nr.Children = append(nr.Children, rc)
-	}
-	return &nr
-}
-
-func (p *Paragraph) copymedia(to *Docx) (np Paragraph) {
-	np = *p
-	np.Children = make([]interface{}, 0, len(p.Children))
-	np.file = to
-	for _, pc := range p.Children {
-		if r, ok := pc.(*Run); ok {
-			np.Children = append(np.Children, r.copymedia(to))
-			continue
-		}
-		if h, ok := pc.(*Hyperlink); ok {
-			tgt, err := p.file.ReferTarget(h.ID)
-			if err != nil {
-				continue
-			}
-			rid := to.addLinkRelation(tgt)
-
-			copiedRuns := make([]*Run, 0, len(*h.Runs))
-			for _, r := range *h.Runs {
-				copiedRuns = append(copiedRuns, r.copymedia(to))
-			}
-
-			np.Children = append(np.Children, &Hyperlink{
-				ID:   rid,
-				Runs: &copiedRuns,
-			},
-			)
-			continue
-		}
-		np.Children = append(np.Children, pc)
-	}
-	return
-}
-
-func (t *Table) copymedia(to *Docx) (nt Table) {
-	nt = *t
-	nt.TableRows = make([]*WTableRow, 0, len(t.TableRows))
-	nt.file = to
-	for _, tr := range t.TableRows {
-		ntr := *tr
-		ntr.TableCells = make([]*WTableCell, 0, len(tr.TableCells))
-		ntr.file = to
-		for _, tc := range tr.TableCells {
-			ntc := *tc
-			ntc.Paragraphs = make([]*Paragraph, 0, len(tc.Paragraphs))
-			ntc.file = to
-			for _, p := range tc.Paragraphs {
-				np := p.copymedia(to)
-				ntc.Paragraphs = append(ntc.Paragraphs, &np)
-			}
-			ntr.TableCells = append(ntr.TableCells, &ntc)
-		}
-		nt.TableRows = append(nt.TableRows, &ntr)
-	}
-	return
-}
-
-// AppendFile appends all contents in af to f
+// AppendFile appends all contents in af to f. The references
+// that cannot be moved into f, like charts, are left empty.
 func (f *Docx) AppendFile(af *Docx) {
 	for _, item := range af.Document.Body.Items {
 		switch o := item.(type) {
 		case *Paragraph:
-			np := o.copymedia(f)
-			f.Document.Body.Items = append(f.Document.Body.Items, &np)
+			f.Document.Body.Items = append(f.Document.Body.Items, newCloner(af, f).clone(o))
 		case *Table:
-			nt := o.copymedia(f)
-			f.Document.Body.Items = append(f.Document.Body.Items, &nt)
+			f.Document.Body.Items = append(f.Document.Body.Items, newCloner(af, f).clone(o))
 		default:
 			f.Document.Body.Items = append(f.Document.Body.Items, o)
 		}
@@ -25,9 +25,7 @@ import (
 	"encoding/hex"
 	"encoding/xml"
 	"io"
-	"strconv"
 	"strings"
-	"sync/atomic"
 )
 
 //nolint:revive,stylecheck
@@ -48,7 +46,14 @@ type Drawing struct {
 	Inline  *WPInline
 	Anchor  *WPAnchor
 
-	file *Docx
+	file      *Docx
+	preserved *preserved
+}
+
+// MarshalXML writes the source back in preserve mode if it is not modified
+func (r *Drawing) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type plain Drawing
+	return marshalPreserved(e, start, (*plain)(r), r.preserved)
 }
 
 // UnmarshalXML ...
@@ -68,14 +73,14 @@ func (r *Drawing) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) error {
 				r.Inline = new(WPInline)
 				r.Inline.file = r.file
 				err = d.DecodeElement(r.Inline, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 			case "anchor":
 				r.Anchor = new(WPAnchor)
 				r.Anchor.file = r.file
 				err = d.DecodeElement(r.Anchor, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 			default:
@@ -90,22 +95,6 @@ func (r *Drawing) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) error {
 	return nil
 }
 
-func (r *Drawing) copymedia(to *Docx) *Drawing {
-	if r.Inline != nil {
-		return &Drawing{
-			Inline: r.Inline.copymedia(to),
-			file:   to,
-		}
-	}
-	if r.Anchor != nil {
-		return &Drawing{
-			Anchor: r.Anchor.copymedia(to),
-			file:   to,
-		}
-	}
-	return &Drawing{file: to}
-}
-
 // WPInline is an element that represents an inline image within a text paragraph.
 //
 // It contains information about the image's size and position,
@@ -195,13 +184,13 @@ func (r *WPInline) UnmarshalXML(d *xml.Decoder, start xml.StartElement) (err err
 			case "docPr":
 				r.DocPr = new(WPDocPr)
 				err = d.DecodeElement(r.DocPr, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 			case "cNvGraphicFramePr":
 				var value WPCNvGraphicFramePr
 				err = d.DecodeElement(&value, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 				r.CNvGraphicFramePr = &value
@@ -209,7 +198,7 @@ func (r *WPInline) UnmarshalXML(d *xml.Decoder, start xml.StartElement) (err err
 				var value AGraphic
 				value.file = r.file
 				err = d.DecodeElement(&value, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 				r.Graphic = &value
@@ -283,64 +272,6 @@ func (r *WPInline) String() string {
 	return "![inln?](unknown)"
 }
 
-func (r *WPInline) copymedia(to *Docx) *WPInline {
-	if r.Graphic.GraphicData.Pic != nil {
-		if r.Graphic.GraphicData.Pic.BlipFill != nil {
-			tgt, err := r.file.ReferTarget(r.Graphic.GraphicData.Pic.BlipFill.Blip.Embed)
-			if err != nil {
-				return nil
-			}
-			format := tgt[strings.LastIndex(tgt, ".")+1:]
-			idn := int(atomic.AddUintptr(&to.docID, 1))
-			id := int(to.IncreaseID("图片"))
-			ids := strconv.Itoa(id)
-			m := r.file.Media(tgt[6:])
-			if m == nil {
-				return nil
-			}
-			rid := to.addImage(format, m.Data)
-			inln := *r
-			grph := *r.Graphic
-			inln.Graphic = &grph
-			grphdata := *r.Graphic.GraphicData
-			grph.GraphicData = &grphdata
-			pic := *r.Graphic.GraphicData.Pic
-			grphdata.Pic = &pic
-			grphdata.file = to
-			grph.file = to
-			inln.file = to
-
-			inln.DocPr = &WPDocPr{
-				ID:   idn,
-				Name: "图片 " + ids,
-			}
-			pic.NonVisualPicProperties = &PICNonVisualPicProperties{
-				NonVisualDrawingProperties: NonVisualProperties{
-					ID:   id,
-					Name: "图片 " + ids,
-				},
-				CNvPicPr: r.Graphic.GraphicData.Pic.NonVisualPicProperties.CNvPicPr,
-			}
-			pic.BlipFill = &PICBlipFill{
-				Blip: ABlip{
-					Embed:  rid,
-					Cstate: r.Graphic.GraphicData.Pic.BlipFill.Blip.Cstate,
-				},
-				Stretch: r.Graphic.GraphicData.Pic.BlipFill.Stretch,
-			}
-			return &inln
-		}
-		return nil
-	}
-	if r.Graphic.GraphicData.Shape != nil { // shape has no media
-		return r
-	}
-	if r.Graphic.GraphicData.Canvas != nil { //TODO: copy canvas media
-		return r
-	}
-	return nil
-}
-
 // WPExtent represents the extent of a drawing in a Word document.
 //
 //	CX CY 's unit is English Metric Units, which is 1/914400 inch
@@ -510,7 +441,7 @@ func (a *AGraphic) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
 				var value AGraphicData
 				value.file = a.file
 				err = d.DecodeElement(&value, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 				value.URI = getAtt(tt.Attr, "uri")
@@ -564,7 +495,7 @@ func (a *AGraphicData) UnmarshalXML(d *xml.Decoder, start xml.StartElement) erro
 			case "pic":
 				var value Picture
 				err = d.DecodeElement(&value, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 				value.XMLPIC = getAtt(tt.Attr, "pic")
@@ -573,7 +504,7 @@ func (a *AGraphicData) UnmarshalXML(d *xml.Decoder, start xml.StartElement) erro
 				var value WordprocessingShape
 				value.file = a.file
 				err = d.DecodeElement(&value, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 				a.Shape = &value
@@ -581,7 +512,7 @@ func (a *AGraphicData) UnmarshalXML(d *xml.Decoder, start xml.StartElement) erro
 				var value WordprocessingCanvas
 				value.file = a.file
 				err = d.DecodeElement(&value, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 				a.Canvas = &value
@@ -589,7 +520,7 @@ func (a *AGraphicData) UnmarshalXML(d *xml.Decoder, start xml.StartElement) erro
 				var value WordprocessingGroup
 				value.file = a.file
 				err = d.DecodeElement(&value, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 				a.Group = &value
@@ -630,21 +561,21 @@ func (p *Picture) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) error {
 			case "nvPicPr":
 				var value PICNonVisualPicProperties
 				err = d.DecodeElement(&value, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 				p.NonVisualPicProperties = &value
 			case "blipFill":
 				var value PICBlipFill
 				err = d.DecodeElement(&value, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 				p.BlipFill = &value
 			case "spPr":
 				var value PICSpPr
 				err = d.DecodeElement(&value, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 				p.SpPr = &value
@@ -692,7 +623,7 @@ func (p *PICNonVisualPicProperties) UnmarshalXML(d *xml.Decoder, _ xml.StartElem
 				p.NonVisualDrawingProperties.Name = getAtt(tt.Attr, "name")
 			case "cNvPicPr":
 				err = d.DecodeElement(&p.CNvPicPr, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 			default:
@@ -779,12 +710,12 @@ func (p *PICBlipFill) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) error {
 			switch tt.Name.Local {
 			case "blip":
 				err = d.DecodeElement(&p.Blip, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 			case "stretch":
 				err = d.DecodeElement(&p.Stretch, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 			default:
@@ -881,7 +812,7 @@ func (s *AStretch) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) error {
 			case "fillRect":
 				var value AFillRect
 				/*err = d.DecodeElement(&value, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err != nil && !ignorable(d, err) {
 					return err
 				}*/
 				s.FillRect = &value
@@ -929,13 +860,13 @@ func (p *PICSpPr) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) error {
 			switch tt.Name.Local {
 			case "xfrm":
 				err = d.DecodeElement(&p.Xfrm, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 			case "prstGeom":
 				var value APrstGeom
 				err = d.DecodeElement(&value, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 				p.PrstGeom = &value
@@ -1253,26 +1184,26 @@ func (r *WPAnchor) UnmarshalXML(d *xml.Decoder, start xml.StartElement) (err err
 				r.PositionH = new(WPPositionH)
 				// r.PositionH.RelativeFrom = getAtt(tt.Attr, "relativeFrom")
 				err = d.DecodeElement(&r.PositionH, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 			case "positionV":
 				r.PositionV = new(WPPositionV)
 				// r.PositionV.RelativeFrom = getAtt(tt.Attr, "relativeFrom")
 				err = d.DecodeElement(&r.PositionV, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 			case "extent":
 				r.Extent = new(WPExtent)
 				err = d.DecodeElement(&r.Extent, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 			case "effectExtent":
 				r.EffectExtent = new(WPEffectExtent)
 				err = d.DecodeElement(&r.EffectExtent, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 			case "wrapNone":
@@ -1283,20 +1214,20 @@ func (r *WPAnchor) UnmarshalXML(d *xml.Decoder, start xml.StartElement) (err err
 			case "docPr":
 				r.DocPr = new(WPDocPr)
 				err = d.DecodeElement(r.DocPr, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 			case "cNvGraphicFramePr":
 				r.CNvGraphicFramePr = new(WPCNvGraphicFramePr)
 				err = d.DecodeElement(r.CNvGraphicFramePr, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 			case "graphic":
 				r.Graphic = new(AGraphic)
 				r.Graphic.file = r.file
 				err = d.DecodeElement(&r.Graphic, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 			default:
@@ -1371,64 +1302,6 @@ func (r *WPAnchor) String() string {
 	return "![anch?](unknown)"
 }
 
-func (r *WPAnchor) copymedia(to *Docx) *WPAnchor {
-	if r.Graphic.GraphicData.Pic != nil {
-		if r.Graphic.GraphicData.Pic.BlipFill != nil {
-			tgt, err := r.file.ReferTarget(r.Graphic.GraphicData.Pic.BlipFill.Blip.Embed)
-			if err != nil {
-				return nil
-			}
-			format := tgt[strings.LastIndex(tgt, ".")+1:]
-			idn := int(atomic.AddUintptr(&to.docID, 1))
-			id := int(to.IncreaseID("图片"))
-			ids := strconv.Itoa(id)
-			m := r.file.Media(tgt[6:])
-			if m == nil {
-				return nil
-			}
-			rid := to.addImage(format, m.Data)
-			anch := *r
-			grph := *r.Graphic
-			anch.Graphic = &grph
-			grphdata := *r.Graphic.GraphicData
-			grph.GraphicData = &grphdata
-			pic := *r.Graphic.GraphicData.Pic
-			grphdata.Pic = &pic
-			grphdata.file = to
-			grph.file = to
-			anch.file = to
-
-			anch.DocPr = &WPDocPr{
-				ID:   idn,
-				Name: "图片 " + ids,
-			}
-			pic.NonVisualPicProperties = &PICNonVisualPicProperties{
-				NonVisualDrawingProperties: NonVisualProperties{
-					ID:   id,
-					Name: "图片 " + ids,
-				},
-				CNvPicPr: r.Graphic.GraphicData.Pic.NonVisualPicProperties.CNvPicPr,
-			}
-			pic.BlipFill = &PICBlipFill{
-				Blip: ABlip{
-					Embed:  rid,
-					Cstate: r.Graphic.GraphicData.Pic.BlipFill.Blip.Cstate,
-				},
-				Stretch: r.Graphic.GraphicData.Pic.BlipFill.Stretch,
-			}
-			return &anch
-		}
-		return nil
-	}
-	if r.Graphic.GraphicData.Shape != nil { // shape has no media
-		return r
-	}
-	if r.Graphic.GraphicData.Canvas != nil { //TODO: copy canvas media
-		return r
-	}
-	return nil
-}
-
 // WPSimplePos represents the position of an object in a Word document.
 type WPSimplePos struct {
 	XMLName xml.Name `xml:"wp:simplePos,omitempty"`
@@ -1464,7 +1337,7 @@ func (r *WPPositionH) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error
 			switch tt.Name.Local {
 			case "posOffset":
 				err = d.DecodeElement(&r.PosOffset, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 			default:
@@ -1507,7 +1380,7 @@ func (r *WPPositionV) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error
 			switch tt.Name.Local {
 			case "posOffset":
 				err = d.DecodeElement(&r.PosOffset, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 			default:
@@ -23,7 +23,6 @@ package docx
 import (
 	"encoding/xml"
 	"io"
-	"strings"
 )
 
 // NOTE:
@@ -61,14 +60,22 @@ type SizeCs struct {
 	Val     string   `xml:"w:val,attr"`
 }
 
-// Bold ...
+// Bold is on unless Val is "0", "false" or "off"
 type Bold struct {
 	XMLName xml.Name `xml:"w:b,omitempty"`
+	Val     string   `xml:"w:val,attr,omitempty"`
 }
 
-// Italic ...
+// Italic is on unless Val is "0", "false" or "off"
 type Italic struct {
 	XMLName xml.Name `xml:"w:i,omitempty"`
+	Val     string   `xml:"w:val,attr,omitempty"`
+}
+
+// Caps shows the lowercase letters as capitals
+type Caps struct {
+	XMLName xml.Name `xml:"w:caps,omitempty"`
+	Val     string   `xml:"w:val,attr,omitempty"`
 }
 
 // Underline ...
@@ -215,7 +222,7 @@ func (w *ShapeProperties) UnmarshalXML(d *xml.Decoder, start xml.StartElement) e
 			switch tt.Name.Local {
 			case "xfrm":
 				err = d.DecodeElement(&w.Xfrm, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 			case "prstGeom":
@@ -223,14 +230,14 @@ func (w *ShapeProperties) UnmarshalXML(d *xml.Decoder, start xml.StartElement) e
 			case "solidFill":
 				var value ASolidFill
 				err = d.DecodeElement(&value, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 				w.SolidFill = &value
 			case "blipFill":
 				var value ABlipFill
 				err = d.DecodeElement(&value, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 				w.BlipFill = &value
@@ -239,7 +246,7 @@ func (w *ShapeProperties) UnmarshalXML(d *xml.Decoder, start xml.StartElement) e
 			case "ln":
 				var ln ALine
 				err = d.DecodeElement(&ln, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 				w.Line = &ln
@@ -289,6 +296,8 @@ type Spacing struct {
 
 	BeforeLines int    `xml:"w:beforeLines,attr,omitempty"`
 	Before      int    `xml:"w:before,attr,omitempty"`
+	AfterLines  int    `xml:"w:afterLines,attr,omitempty"`
+	After       int    `xml:"w:after,attr,omitempty"`
 	Line        int    `xml:"w:line,attr,omitempty"`
 	LineRule    string `xml:"w:lineRule,attr,omitempty"`
 }
@@ -312,6 +321,16 @@ func (s *Spacing) UnmarshalXML(d *xml.Decoder, start xml.StartElement) (err erro
 			if err != nil {
 				return
 			}
+		case "afterLines":
+			s.AfterLines, err = GetInt(attr.Value)
+			if err != nil {
+				return
+			}
+		case "after":
+			s.After, err = GetInt(attr.Value)
+			if err != nil {
+				return
+			}
 		case "line":
 			s.Line, err = GetInt(attr.Value)
 			if err != nil {
@@ -464,14 +483,14 @@ func (n *NumPr) UnmarshalXML(d *xml.Decoder, start xml.StartElement) (err error)
 			case "ilvl":
 				var ilvl Ilvl
 				err = d.DecodeElement(&ilvl, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 				n.Ilvl = &ilvl
 			case "numId":
 				var numID NumID
 				err = d.DecodeElement(&numID, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 				n.NumID = &numID
@@ -507,27 +526,27 @@ func (n *NumID) UnmarshalXML(d *xml.Decoder, start xml.StartElement) (err error)
 }
 
 // UnmarshalXML Ilvl
-// func (i *Ilvl) UnmarshalXML(d *xml.Decoder, start xml.StartElement) (err error) {
-// 	for _, attr := range start.Attr {
-// 		switch attr.Name.Local {
-// 		case "val":
-// 			if attr.Value == "" {
-// 				i.Val = 0
-// 			} else {
-// 				i.Val, err = GetInt(attr.Value)
-// 				if err != nil {
-// 					return
-// 				}
-// 			}
-// 		default:
-// 			// ignore other attributes
-// 		}
-// 	}
+func (i *Ilvl) UnmarshalXML(d *xml.Decoder, start xml.StartElement) (err error) {
+	for _, attr := range start.Attr {
+		switch attr.Name.Local {
+		case "val":
+			if attr.Value == "" {
+				i.Val = 0
+			} else {
+				i.Val, err = GetInt(attr.Value)
+				if err != nil {
+					return
+				}
+			}
+		default:
+			// ignore other attributes
+		}
+	}
 
-// 	// Consume the end element
-// 	_, err = d.Token()
-// 	return
-// }
+	// Consume the end element
+	_, err = d.Token()
+	return
+}
 
 // // 0: false, 1: true
 // // 段落が次の段落と同じページに表示されるようにするかどうかを指定します。
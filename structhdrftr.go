@@ -0,0 +1,221 @@
+/*
+   Copyright (c) 2020 gingfrederik
+   Copyright (c) 2021 Gonzalo Fernandez-Victorio
+   Copyright (c) 2021 Basement Crowd Ltd (https://www.basementcrowd.com)
+   Copyright (c) 2023 Fumiama Minamoto (源文雨)
+
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU Affero General Public License as published
+   by the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU Affero General Public License for more details.
+
+   You should have received a copy of the GNU Affero General Public License
+   along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package docx
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+)
+
+//nolint:revive,stylecheck
+const (
+	// HDRFTR_DEFAULT is used on every page unless overridden
+	HDRFTR_DEFAULT = "default"
+	// HDRFTR_FIRST is used on the first page of the section
+	HDRFTR_FIRST = "first"
+	// HDRFTR_EVEN is used on even pages, needs evenAndOddHeaders in settings.xml
+	HDRFTR_EVEN = "even"
+)
+
+// Header <w:hdr> is word/headerN.xml
+type Header struct {
+	XMLName xml.Name `xml:"w:hdr"`
+	HeaderFooter
+}
+
+// Footer <w:ftr> is word/footerN.xml
+type Footer struct {
+	XMLName xml.Name `xml:"w:ftr"`
+	HeaderFooter
+}
+
+// HeaderFooter is the content of a header or footer part
+type HeaderFooter struct {
+	XMLW   string `xml:"xmlns:w,attr"`             // cannot be unmarshalled in
+	XMLW14 string `xml:"xmlns:w14,attr,omitempty"` // cannot be unmarshalled in
+	XMLR   string `xml:"xmlns:r,attr,omitempty"`   // cannot be unmarshalled in
+	XMLWP  string `xml:"xmlns:wp,attr,omitempty"`  // cannot be unmarshalled in
+	XMLWPS string `xml:"xmlns:wps,attr,omitempty"` // cannot be unmarshalled in
+	XMLWPC string `xml:"xmlns:wpc,attr,omitempty"` // cannot be unmarshalled in
+	XMLWPG string `xml:"xmlns:wpg,attr,omitempty"` // cannot be unmarshalled in
+
+	// Attrs are the extra namespaces declared in source, only in preserve mode
+	Attrs []xml.Attr `xml:",any,attr"`
+
+	Items []interface{}
+
+	file *Docx
+	// name is the path in zip like word/header1.xml
+	name string
+	// id is the rId in document.xml.rels
+	id string
+	// rels are the relationships read from its own .rels,
+	// already renumbered into document rId space
+	rels []Relationship
+}
+
+func (hf *HeaderFooter) setNamespaces() {
+	hf.XMLW = XMLNS_W
+	hf.XMLW14 = XMLNS_W14
+	hf.XMLR = XMLNS_R
+	hf.XMLWP = XMLNS_WP
+	hf.XMLWPS = XMLNS_WPS
+	hf.XMLWPC = XMLNS_WPC
+	hf.XMLWPG = XMLNS_WPG
+}
+
+// UnmarshalXML ...
+func (hf *HeaderFooter) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	if ctx := contextOf(d); ctx.preserve() {
+		hf.Attrs = ctx.rootAttrs(start.Attr, XMLNS_W, XMLNS_W14, XMLNS_R, XMLNS_WP, XMLNS_WPS, XMLNS_WPC, XMLNS_WPG)
+		for _, a := range start.Attr {
+			if a.Name.Space == XMLNS_MC && a.Name.Local == "Ignorable" {
+				hf.Attrs = append(hf.Attrs, xml.Attr{Name: xml.Name{Local: "mc:Ignorable"}, Value: a.Value})
+			}
+		}
+	}
+	b := Body{file: hf.file}
+	err := b.UnmarshalXML(d, start)
+	if err != nil && !ignorable(d, err) {
+		return err
+	}
+	hf.Items = b.Items
+	return nil
+}
+
+// relRemapper rewrites the r:* attributes of a sub part
+// from its own rId space into the document one
+type relRemapper struct {
+	d   *xml.Decoder
+	ids map[string]string
+}
+
+// Token implements xml.TokenReader
+func (r *relRemapper) Token() (xml.Token, error) {
+	t, err := r.d.Token()
+	if err != nil {
+		return t, err
+	}
+	if se, ok := t.(xml.StartElement); ok {
+		for i, a := range se.Attr {
+			if a.Name.Space != XMLNS_R {
+				continue
+			}
+			if id, ok := r.ids[a.Value]; ok {
+				se.Attr[i].Value = id
+			}
+		}
+		return se, nil
+	}
+	return t, nil
+}
+
+// mergeRelations moves the relationships of a sub part into document.xml.rels
+// and returns the old to new id map and the moved relationships
+//
+//	this func is not thread-safe
+func (f *Docx) mergeRelations(rels *Relationships) (map[string]string, []Relationship) {
+	if rels == nil {
+		return nil, nil
+	}
+	ids := make(map[string]string, len(rels.Relationship))
+	moved := make([]Relationship, 0, len(rels.Relationship))
+	for _, r := range rels.Relationship {
+		found := false
+		for _, dr := range f.docRelation.Relationship {
+			if dr.Type == r.Type && dr.Target == r.Target && dr.TargetMode == r.TargetMode {
+				ids[r.ID] = dr.ID
+				moved = append(moved, dr)
+				found = true
+				break
+			}
+		}
+		if found {
+			continue
+		}
+		id := f.newRelationID()
+		ids[r.ID] = id
+		r.ID = id
+		f.docRelation.Relationship = append(f.docRelation.Relationship, r)
+		moved = append(moved, r)
+	}
+	return ids, moved
+}
+
+// subRelations collects the relationships of document rId space in ids,
+// that are the ones a sub part refers to
+func (f *Docx) subRelations(ids map[string]struct{}) *Relationships {
+	rels := &Relationships{Xmlns: XMLNS_REL}
+	for _, r := range f.docRelation.Relationship {
+		if _, ok := ids[r.ID]; ok {
+			rels.Relationship = append(rels.Relationship, r)
+		}
+	}
+	return rels
+}
+
+// docRelations leaves out the relationships that document.xml does not
+// refer to in ids but a sub part may: the ones moved from sub parts and
+// the images and hyperlinks
+func (f *Docx) docRelations(ids, moved map[string]struct{}) *Relationships {
+	rels := &Relationships{Xmlns: f.docRelation.Xmlns}
+	for _, r := range f.docRelation.Relationship {
+		if _, ok := ids[r.ID]; !ok {
+			if _, ok := moved[r.ID]; ok || r.Type == REL_IMAGE || r.Type == REL_HYPERLINK {
+				continue
+			}
+		}
+		rels.Relationship = append(rels.Relationship, r)
+	}
+	return rels
+}
+
+// referredRelations returns the values of the r:* attributes in an xml part
+func referredRelations(part []byte) (map[string]struct{}, error) {
+	ids := make(map[string]struct{}, 16)
+	d := xml.NewDecoder(bytes.NewReader(part))
+	for {
+		t, err := d.Token()
+		if err == io.EOF {
+			return ids, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		se, ok := t.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		for _, a := range se.Attr {
+			if a.Name.Space == XMLNS_R || a.Name.Space == "r" {
+				ids[a.Value] = struct{}{}
+			}
+		}
+	}
+}
+
+// relsNameOf returns word/_rels/header1.xml.rels for word/header1.xml
+func relsNameOf(name string) string {
+	i := strings.LastIndex(name, "/")
+	return name[:i+1] + "_rels/" + name[i+1:] + ".rels"
+}
@@ -0,0 +1,190 @@
+/*
+   Copyright (c) 2020 gingfrederik
+   Copyright (c) 2021 Gonzalo Fernandez-Victorio
+   Copyright (c) 2021 Basement Crowd Ltd (https://www.basementcrowd.com)
+   Copyright (c) 2023 Fumiama Minamoto (源文雨)
+
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU Affero General Public License as published
+   by the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU Affero General Public License for more details.
+
+   You should have received a copy of the GNU Affero General Public License
+   along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package docx
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestHeaderFooter(t *testing.T) {
+	w := NewA4()
+	w.AddParagraph().AddText("body")
+	h := w.AddHeader(HDRFTR_DEFAULT)
+	_, err := h.AddParagraph().AddInlineDrawingFrom("testdata/fumiama.JPG")
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.AddParagraph().AddText("letterhead")
+	if w.AddHeader(HDRFTR_DEFAULT) != h {
+		t.Fatal("header added twice")
+	}
+	p := w.AddFooter(HDRFTR_DEFAULT).AddParagraph()
+	p.AddText("page ")
+	p.AddField("PAGE", "1")
+	w.AddFooter(HDRFTR_FIRST).AddParagraph().AddText("first")
+	w.AddParagraph().AddText("end")
+	ids := make(map[string]bool, len(w.docRelation.Relationship))
+	for _, r := range w.docRelation.Relationship {
+		if ids[r.ID] {
+			t.Fatal("duplicate", r.ID)
+		}
+		ids[r.ID] = true
+	}
+
+	buf := bytes.NewBuffer(make([]byte, 0, 1024*1024))
+	_, err = w.WriteTo(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"word/header1.xml", "word/footer1.xml", "word/footer2.xml", "word/_rels/header1.xml.rels"} {
+		if _, err = zr.Open(name); err != nil {
+			t.Fatal(name, err)
+		}
+	}
+	ctf, err := zr.Open("[Content_Types].xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ct, err := io.ReadAll(ctf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(ct, []byte(`/word/header1.xml`)) || !bytes.Contains(ct, []byte(CONTENT_TYPE_FOOTER)) {
+		t.Fatal("content types not registered:", string(ct))
+	}
+
+	doc, err := Parse(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if items := doc.Document.Body.Items; len(items) != 3 {
+		t.Fatal("expected 3 body items, got", len(items))
+	} else if _, ok := items[2].(*SectPr); !ok {
+		t.Fatal("sectPr is not the last item")
+	}
+	if len(doc.Headers()) != 1 || len(doc.Footers()) != 2 {
+		t.Fatal("headers/footers not loaded")
+	}
+	if s := doc.Header(HDRFTR_DEFAULT).String(); !strings.Contains(s, "letterhead") {
+		t.Fatal("unexpected header:", s)
+	}
+	if s := doc.Footer(HDRFTR_DEFAULT).String(); s != "page 1" {
+		t.Fatal("unexpected footer:", s)
+	}
+	if s := doc.Footer(HDRFTR_FIRST).String(); s != "first" {
+		t.Fatal("unexpected first footer:", s)
+	}
+	if doc.Header(HDRFTR_EVEN) != nil {
+		t.Fatal("unexpected even header")
+	}
+	// the image in header must still be resolvable after remapping
+	var embed string
+	for _, c := range doc.Header(HDRFTR_DEFAULT).Items[0].(*Paragraph).Children {
+		if r, ok := c.(*Run); ok {
+			for _, rc := range r.Children {
+				if d, ok := rc.(*Drawing); ok && d.Inline != nil {
+					embed = d.Inline.Graphic.GraphicData.Pic.BlipFill.Blip.Embed
+				}
+			}
+		}
+	}
+	target, err := doc.ReferTarget(embed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.Media(target[len("media/"):]) == nil {
+		t.Fatal("media not found:", target)
+	}
+
+	// write the parsed file again and the parts are kept as is
+	buf = bytes.NewBuffer(make([]byte, 0, 1024*1024))
+	_, err = doc.WriteTo(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err = Parse(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Headers()) != 1 || len(doc.Footers()) != 2 {
+		t.Fatal("headers/footers not kept")
+	}
+	if s := doc.Footer(HDRFTR_DEFAULT).String(); s != "page 1" {
+		t.Fatal("unexpected footer:", s)
+	}
+}
+
+func TestHeaderFooterRelations(t *testing.T) {
+	w := NewA4()
+	w.AddParagraph().AddLink("site", "https://example.com")
+	_, err := w.AddHeader(HDRFTR_DEFAULT).AddParagraph().AddInlineDrawingFrom("testdata/fumiama.JPG")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.AddFooter(HDRFTR_DEFAULT).AddParagraph().AddText("plain")
+	for i := 0; i < 2; i++ {
+		buf := bytes.NewBuffer(make([]byte, 0, 1024*1024))
+		_, err = w.WriteTo(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		read := func(name string) []byte {
+			f, err := zr.Open(name)
+			if err != nil {
+				t.Fatal(name, err)
+			}
+			defer f.Close()
+			data, err := io.ReadAll(f)
+			if err != nil {
+				t.Fatal(name, err)
+			}
+			return data
+		}
+		rels := read("word/_rels/document.xml.rels")
+		if !bytes.Contains(rels, []byte("https://example.com")) || bytes.Contains(rels, []byte("media/")) {
+			t.Fatal("unexpected document rels", string(rels))
+		}
+		rels = read("word/_rels/header1.xml.rels")
+		if bytes.Count(rels, []byte("<Relationship ")) != 1 || !bytes.Contains(rels, []byte("media/")) {
+			t.Fatal("unexpected header rels", string(rels))
+		}
+		if _, err = zr.Open("word/_rels/footer1.xml.rels"); err == nil {
+			t.Fatal("unexpected footer rels")
+		}
+		// the parsed file has moved the header rels into the document ones
+		w, err = Parse(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
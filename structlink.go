@@ -23,7 +23,6 @@ package docx
 import (
 	"encoding/xml"
 	"io"
-	"strings"
 )
 
 // Hyperlink element contains links
@@ -33,6 +32,14 @@ type Hyperlink struct {
 	Anchor  string   `xml:"w:anchor,attr,omitempty"`
 	History string   `xml:"w:history,attr,omitempty"`
 	Runs    *[]*Run  `xml:"w:r,omitempty"`
+
+	preserved *preserved
+}
+
+// MarshalXML writes the source back in preserve mode if it is not modified
+func (r *Hyperlink) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type plain Hyperlink
+	return marshalPreserved(e, start, (*plain)(r), r.preserved)
 }
 
 // UnmarshalXML ...
@@ -62,7 +69,7 @@ func (r *Hyperlink) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
 			if tt.Name.Local == "r" {
 				run := Run{}
 				err = d.DecodeElement(&run, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 				if r.Runs == nil {
@@ -0,0 +1,191 @@
+/*
+   Copyright (c) 2020 gingfrederik
+   Copyright (c) 2021 Gonzalo Fernandez-Victorio
+   Copyright (c) 2021 Basement Crowd Ltd (https://www.basementcrowd.com)
+   Copyright (c) 2023 Fumiama Minamoto (源文雨)
+
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU Affero General Public License as published
+   by the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU Affero General Public License for more details.
+
+   You should have received a copy of the GNU Affero General Public License
+   along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package docx
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+)
+
+//nolint:revive,stylecheck
+const (
+	// NOTE_SEPARATOR is the type of the note holding the separator line
+	NOTE_SEPARATOR = "separator"
+	// NOTE_CONTINUATION_SEPARATOR is the type of the note holding the
+	// separator line of notes continued from the previous page
+	NOTE_CONTINUATION_SEPARATOR = "continuationSeparator"
+)
+
+// Notes <w:footnotes> or <w:endnotes> is word/footnotes.xml or word/endnotes.xml
+type Notes struct {
+	XMLName xml.Name
+	XMLW    string `xml:"xmlns:w,attr"`             // cannot be unmarshalled in
+	XMLW14  string `xml:"xmlns:w14,attr,omitempty"` // cannot be unmarshalled in
+	XMLR    string `xml:"xmlns:r,attr,omitempty"`   // cannot be unmarshalled in
+	XMLWP   string `xml:"xmlns:wp,attr,omitempty"`  // cannot be unmarshalled in
+	XMLWPS  string `xml:"xmlns:wps,attr,omitempty"` // cannot be unmarshalled in
+	XMLWPC  string `xml:"xmlns:wpc,attr,omitempty"` // cannot be unmarshalled in
+	XMLWPG  string `xml:"xmlns:wpg,attr,omitempty"` // cannot be unmarshalled in
+
+	// Attrs are the extra namespaces declared in source, only in preserve mode
+	Attrs []xml.Attr `xml:",any,attr"`
+
+	Notes []*Note
+
+	file *Docx
+	// name is the path in zip like word/footnotes.xml
+	name string
+	// rels are the relationships read from its own .rels,
+	// already renumbered into document rId space
+	rels []Relationship
+}
+
+// Note <w:footnote> or <w:endnote> is the body of a note
+type Note struct {
+	XMLName xml.Name
+	Type    string `xml:"w:type,attr,omitempty"`
+	ID      int    `xml:"w:id,attr"`
+
+	Items []interface{}
+
+	file *Docx
+}
+
+// FootnoteReference <w:footnoteReference> is the mark of a footnote in text
+type FootnoteReference struct {
+	XMLName xml.Name `xml:"w:footnoteReference"`
+	ID      int      `xml:"w:id,attr"`
+}
+
+// EndnoteReference <w:endnoteReference> is the mark of an endnote in text
+type EndnoteReference struct {
+	XMLName xml.Name `xml:"w:endnoteReference"`
+	ID      int      `xml:"w:id,attr"`
+}
+
+// FootnoteRef <w:footnoteRef> is the mark at the beginning of a footnote
+type FootnoteRef struct {
+	XMLName xml.Name `xml:"w:footnoteRef"`
+}
+
+// EndnoteRef <w:endnoteRef> is the mark at the beginning of an endnote
+type EndnoteRef struct {
+	XMLName xml.Name `xml:"w:endnoteRef"`
+}
+
+// Separator <w:separator> is the line between text and notes
+type Separator struct {
+	XMLName xml.Name `xml:"w:separator"`
+}
+
+// ContinuationSeparator <w:continuationSeparator> is the
+// line between text and notes continued from the previous page
+type ContinuationSeparator struct {
+	XMLName xml.Name `xml:"w:continuationSeparator"`
+}
+
+func newNotes(f *Docx, local string) *Notes {
+	n := &Notes{
+		XMLName: xml.Name{Local: "w:" + local},
+		file:    f,
+	}
+	n.XMLW = XMLNS_W
+	n.XMLW14 = XMLNS_W14
+	n.XMLR = XMLNS_R
+	n.XMLWP = XMLNS_WP
+	n.XMLWPS = XMLNS_WPS
+	n.XMLWPC = XMLNS_WPC
+	n.XMLWPG = XMLNS_WPG
+	return n
+}
+
+// noteLocal returns footnote for footnotes
+func (n *Notes) noteLocal() string {
+	if n.XMLName.Local == "w:endnotes" {
+		return "endnote"
+	}
+	return "footnote"
+}
+
+// UnmarshalXML ...
+func (n *Notes) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	if ctx := contextOf(d); ctx.preserve() {
+		n.Attrs = ctx.rootAttrs(start.Attr, XMLNS_W, XMLNS_W14, XMLNS_R, XMLNS_WP, XMLNS_WPS, XMLNS_WPC, XMLNS_WPG)
+		for _, a := range start.Attr {
+			if a.Name.Space == XMLNS_MC && a.Name.Local == "Ignorable" {
+				n.Attrs = append(n.Attrs, xml.Attr{Name: xml.Name{Local: "mc:Ignorable"}, Value: a.Value})
+			}
+		}
+	}
+	for {
+		t, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		tt, ok := t.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if tt.Name.Local != "footnote" && tt.Name.Local != "endnote" {
+			logln(d, "UnmarshalXML Notes unsupported, skip:", tt.Name.Local)
+			err = d.Skip()
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		note := &Note{file: n.file}
+		err = d.DecodeElement(note, &tt)
+		if err != nil && !ignorable(d, err) {
+			return err
+		}
+		n.Notes = append(n.Notes, note)
+	}
+	return nil
+}
+
+// UnmarshalXML ...
+func (n *Note) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	n.XMLName = xml.Name{Local: "w:" + start.Name.Local}
+	for _, a := range start.Attr {
+		switch a.Name.Local {
+		case "type":
+			n.Type = a.Value
+		case "id":
+			id, err := strconv.Atoi(a.Value)
+			if err != nil {
+				return err
+			}
+			n.ID = id
+		}
+	}
+	b := Body{file: n.file}
+	err := b.UnmarshalXML(d, start)
+	if err != nil && !ignorable(d, err) {
+		return err
+	}
+	n.Items = b.Items
+	return nil
+}
@@ -0,0 +1,80 @@
+/*
+   Copyright (c) 2020 gingfrederik
+   Copyright (c) 2021 Gonzalo Fernandez-Victorio
+   Copyright (c) 2021 Basement Crowd Ltd (https://www.basementcrowd.com)
+   Copyright (c) 2023 Fumiama Minamoto (源文雨)
+
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU Affero General Public License as published
+   by the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU Affero General Public License for more details.
+
+   You should have received a copy of the GNU Affero General Public License
+   along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package docx
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestNotes(t *testing.T) {
+	w := NewA4()
+	p := w.AddParagraph()
+	p.AddText("See")
+	fn := p.AddFootnote("Civil Code, art. 1.")
+	fn.AddParagraph().AddText("Second paragraph.")
+	p.AddText(" and")
+	p.AddEndnote("The end.")
+	if p.String() != "See[^1] and[^e1]" {
+		t.Fatal("unexpected text", p.String())
+	}
+	if w.AddParagraph().AddFootnote("next").ID != 2 {
+		t.Fatal("unexpected id")
+	}
+
+	buf := bytes.NewBuffer(make([]byte, 0, 1024*1024))
+	_, err := w.WriteTo(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := Parse(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Footnotes()) != 2 || len(doc.footnotes.Notes) != 4 || len(doc.Endnotes()) != 1 {
+		t.Fatal("unexpected notes", len(doc.Footnotes()), len(doc.Endnotes()))
+	}
+	if doc.Footnote(1).String() != "Civil Code, art. 1.\nSecond paragraph." {
+		t.Fatal("unexpected footnote", doc.Footnote(1).String())
+	}
+	if doc.Endnote(1).String() != "The end." {
+		t.Fatal("unexpected endnote", doc.Endnote(1).String())
+	}
+	if s := doc.Document.Body.Items[0].(*Paragraph).String(); s != "See[^1] and[^e1]" {
+		t.Fatal("unexpected text", s)
+	}
+	ct := string(readZipFile(t, buf.Bytes(), "[Content_Types].xml"))
+	if !bytes.Contains([]byte(ct), []byte(CONTENT_TYPE_FOOTNOTES)) || !bytes.Contains([]byte(ct), []byte(CONTENT_TYPE_ENDNOTES)) {
+		t.Fatal("no content types", ct)
+	}
+
+	// round-trip keeps notes as they are
+	buf2 := bytes.NewBuffer(make([]byte, 0, 1024*1024))
+	_, err = doc.WriteTo(buf2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"word/footnotes.xml", "word/endnotes.xml"} {
+		if !bytes.Equal(readZipFile(t, buf.Bytes(), name), readZipFile(t, buf2.Bytes(), name)) {
+			t.Fatal(name, "changed")
+		}
+	}
+}
@@ -20,7 +20,6 @@ package docx
 import (
 	"encoding/xml"
 	"io"
-	"log"
 )
 
 type CommonAttrVal struct {
@@ -37,87 +36,87 @@ type Numbering struct {
 	XMLW    string   `xml:"xmlns:w,attr"`
 
 	// この XML 指定は正しく働いた
-	AbstractNums *[]AbstractNum `xml:"w:abstractNum",omitempty`
-	Nums         *[]*Num        `xml:"w:num",omitempty`
+	AbstractNums *[]AbstractNum `xml:"w:abstractNum,omitempty"`
+	Nums         *[]*Num        `xml:"w:num,omitempty"`
 }
 
 type AbstractNum struct {
-	XMLName       xml.Name `xml:"w:abstractNum",omitempty`
+	XMLName       xml.Name `xml:"w:abstractNum,omitempty"`
 	AbstractNumID string   `xml:"w:abstractNumId,attr,omitempty"`
 
 	// この XML Marshal は正しく働いた
-	Lvl *[]*Lvl `xml:"w:lvl",omitempty"`
+	Lvl *[]*Lvl `xml:"w:lvl,omitempty"`
 
-	NSID           *NSID           `xml:"w:nsid",omitempty`
-	MultiLevelType *MultiLevelType `xml:"w:multiLevelType",omitempty`
-	Tmpl           *Tmpl           `xml:"w:tmpl",omitempty`
+	NSID           *NSID           `xml:"w:nsid,omitempty"`
+	MultiLevelType *MultiLevelType `xml:"w:multiLevelType,omitempty"`
+	Tmpl           *Tmpl           `xml:"w:tmpl,omitempty"`
 }
 
 type Num struct {
-	XMLName xml.Name `xml:"w:num",omitempty`
+	XMLName xml.Name `xml:"w:num,omitempty"`
 	NumID   string   `xml:"w:numId,attr,omitempty"`
 
 	// ここはフィールド名だけだと正しく処理されない
-	AbstractNumID *AbstractNumID `xml:"w:abstractNumId",omitempty`
+	AbstractNumID *AbstractNumID `xml:"w:abstractNumId,omitempty"`
 }
 
 type AbstractNumID struct {
-	XMLName xml.Name `xml:"w:abstractNumId",omitempty`
+	XMLName xml.Name `xml:"w:abstractNumId,omitempty"`
 	// CommonAttrVal *CommonAttrVal
 	*CommonAttrVal
 }
 
 type NSID struct {
-	XMLName xml.Name `xml:"w:nsid",omitempty`
+	XMLName xml.Name `xml:"w:nsid,omitempty"`
 	// CommonAttrVal *CommonAttrVal
 	*CommonAttrVal
 }
 
 type MultiLevelType struct {
-	XMLName xml.Name `xml:"w:multiLevelType",omitempty`
+	XMLName xml.Name `xml:"w:multiLevelType,omitempty"`
 	// CommonAttrVal *CommonAttrVal
 	*CommonAttrVal
 }
 
 type Tmpl struct {
-	XMLName xml.Name `xml:"w:tmpl",omitempty`
+	XMLName xml.Name `xml:"w:tmpl,omitempty"`
 	// CommonAttrVal *CommonAttrVal
 	*CommonAttrVal
 }
 
 type Lvl struct {
-	XMLName   xml.Name `xml:"w:lvl",omitempty`
-	ILvl      int      `xml:"w:ilvl,attr,omitempty"`
+	XMLName   xml.Name `xml:"w:lvl,omitempty"`
+	ILvl      int      `xml:"w:ilvl,attr"`
 	Tplc      string   `xml:"w:tplc,attr,omitempty"`
 	Tentative string   `xml:"w:tentative,attr,omitempty"`
 
-	Start   *Start                 `xml:"w:start",omitempty`
-	NumFmt  *NumFmt                `xml:"w:numFmt",omitempty`
-	LvlText *LvlText               `xml:"w:lvlText",omitempty`
-	LvlJc   *LvlJc                 `xml:"w:lvlJc",omitempty`
-	Ppr     *[]ParagraphProperties `xml:"w:pPr",omitempty"`
+	Start   *Start                 `xml:"w:start,omitempty"`
+	NumFmt  *NumFmt                `xml:"w:numFmt,omitempty"`
+	LvlText *LvlText               `xml:"w:lvlText,omitempty"`
+	LvlJc   *LvlJc                 `xml:"w:lvlJc,omitempty"`
+	Ppr     *[]ParagraphProperties `xml:"w:pPr,omitempty"`
 }
 
 type Start struct {
-	XMLName xml.Name `xml:"w:start",omitempty`
+	XMLName xml.Name `xml:"w:start,omitempty"`
 	// CommonAttrVal *CommonAttrVal
 	*CommonAttrVal
 }
 
 type NumFmt struct {
-	XMLName xml.Name `xml:"w:numFmt",omitempty`
+	XMLName xml.Name `xml:"w:numFmt,omitempty"`
 	// CommonAttrVal *CommonAttrVal
 	*CommonAttrVal
 }
 
 type LvlText struct {
-	XMLName xml.Name `xml:"w:lvlText",omitempty`
+	XMLName xml.Name `xml:"w:lvlText,omitempty"`
 	// CommonAttrVal *CommonAttrVal
 	*CommonAttrVal
 }
 
 type LvlJc struct {
-	XMLName xml.Name `xml:"w:lvlJc",omitempty`
+	XMLName xml.Name `xml:"w:lvlJc,omitempty"`
 	// CommonAttrVal *CommonAttrVal
 	*CommonAttrVal
 }
@@ -340,7 +339,7 @@ func commonSetAttrVal(a *CommonAttrVal, d *xml.Decoder, start xml.StartElement)
 		switch attr.Name.Local {
 		case "val":
 			if a.Val != "" {
-				log.Printf("commonSetAttrVal: %s is already set", attr.Name.Local)
+				logln(d, "commonSetAttrVal:", attr.Name.Local, "is already set")
 			}
 			a.Val = attr.Value
 		default:
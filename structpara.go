@@ -23,8 +23,6 @@ package docx
 import (
 	"encoding/xml"
 	"io"
-	"log"
-	"reflect"
 	"strconv"
 	"strings"
 )
@@ -54,6 +52,16 @@ type ParagraphProperties struct {
 	PBDR            *PBDR
 
 	RunProperties *RunProperties
+
+	Change *ParagraphPropertiesChange
+
+	preserved *preserved
+}
+
+// MarshalXML writes the source back in preserve mode if it is not modified
+func (p *ParagraphProperties) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type plain ParagraphProperties
+	return marshalPreserved(e, start, (*plain)(p), p.preserved)
 }
 
 // UnmarshalXML ...
@@ -64,7 +72,7 @@ func (p *ParagraphProperties) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) e
 			break
 		}
 		if err != nil {
-			log.Println("UnmarshalXML ParagraphProperties error:", err)
+			logln(d, "UnmarshalXML ParagraphProperties error:", err)
 			return err
 		}
 		if tt, ok := t.(xml.StartElement); ok {
@@ -72,21 +80,21 @@ func (p *ParagraphProperties) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) e
 			case "tabs":
 				var value Tabs
 				err = d.DecodeElement(&value, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 				p.Tabs = &value
 			case "spacing":
 				var value Spacing
 				err = d.DecodeElement(&value, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 				p.Spacing = &value
 			case "ind":
 				var value Ind
 				err = d.DecodeElement(&value, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 				p.Ind = &value
@@ -95,7 +103,7 @@ func (p *ParagraphProperties) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) e
 			case "shd":
 				var value Shade
 				err = d.DecodeElement(&value, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 				p.Shade = &value
@@ -113,10 +121,17 @@ func (p *ParagraphProperties) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) e
 			case "rPr":
 				var value RunProperties
 				err = d.DecodeElement(&value, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 				p.RunProperties = &value
+			case "pPrChange":
+				var value ParagraphPropertiesChange
+				err = d.DecodeElement(&value, &tt)
+				if err != nil && !ignorable(d, err) {
+					return err
+				}
+				p.Change = &value
 			case "pStyle":
 				p.Style = &Style{Val: getAtt(tt.Attr, "val")}
 			case "textAlignment":
@@ -168,7 +183,7 @@ func (p *ParagraphProperties) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) e
 			case "numPr":
 				var value NumPr
 				err = d.DecodeElement(&value, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 				p.NumPr = &value
@@ -202,7 +217,7 @@ func (p *ParagraphProperties) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) e
 			case "sectPr":
 				var value SectPr
 				err = d.DecodeElement(&value, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 				p.SectPr = &value
@@ -217,13 +232,13 @@ func (p *ParagraphProperties) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) e
 			case "pBdr":
 				var value PBDR
 				err = d.DecodeElement(&value, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 				p.PBDR = &value
 			default:
 				// 取り損ねた値を log に表示
-				log.Println("UnmarshalXML ParagraphProperties unsupported, skip:", tt.Name.Local)
+				logln(d, "UnmarshalXML ParagraphProperties unsupported, skip:", tt.Name.Local)
 
 				err = d.Skip() // skip unsupported tags
 				if err != nil {
@@ -252,12 +267,9 @@ type Paragraph struct {
 	RsidP        string `xml:"w:rsidP,attr,omitempty"`
 	TextId       string `xml:"w14:textId,attr,omitempty"`
 
-	Hyperlink     *[]*Hyperlink     `xml:"w:hyperlink,omitempty"`     // 0 or more
 	BookmarkStart *[]*BookmarkStart `xml:"w:bookmarkStart,omitempty"` // 0 or more
 	BookmarkEnd   *[]*BookmarkEnd   `xml:"w:bookmarkEnd,omitempty"`   // 0 or more
 
-	StructuredDocumentTag *[]*StructuredDocumentTag `xml:"w:sdt,omitempty"` // 0 or more
-
 	KeepLines       *KeepLines       `xml:"w:keepLines,omitempty"`
 	WidowControl    *WidowControl    `xml:"w:widowControl,omitempty"`
 	PageBreakBefore *PageBreakBefore `xml:"w:pageBreakBefore,omitempty"`
@@ -266,12 +278,18 @@ type Paragraph struct {
 	Spacing         *Spacing         `xml:"w:spacing,omitempty"`
 	Shd             *Shade           `xml:"w:shd,omitempty"`
 
+	// Attrs are the attributes not modelled above, only in preserve mode
+	Attrs []xml.Attr `xml:",any,attr"`
+
+	// Properties holds the parsed <w:pPr>, which is not kept in Children
 	Properties *ParagraphProperties
 	Children   []interface{}
 
 	file *Docx
 }
 
+// String returns the plain text of paragraph, where footnote and
+// endnote references are written as [^n] and [^en]
 func (p *Paragraph) String() string {
 	// rPr は Children の中には、含まれず、p.Properties に含まれる
 	// 並びとしては、連動しているので、Children に他と同様に含めた方が
@@ -289,6 +307,10 @@ func (p *Paragraph) String() string {
 	// NUmID の Val は int と定義。structnumbering 側もいずれ合わせる。
 
 	sb := strings.Builder{}
+	// NumPr の値があるかどうか。
+	if p.Properties != nil && p.Properties.NumPr != nil {
+		sb.WriteString(getNumberedString(p, p.Properties.NumPr))
+	}
 	for _, c := range p.Children {
 		switch o := c.(type) {
 		case *Hyperlink:
@@ -308,6 +330,10 @@ func (p *Paragraph) String() string {
 				}
 				sb.WriteByte(')')
 			}
+		case *Insertion:
+			sb.WriteString((&Paragraph{Children: o.Children, file: p.file}).String())
+		case *MoveTo:
+			sb.WriteString((&Paragraph{Children: o.Children, file: p.file}).String())
 		case *Run:
 			for _, c := range o.Children {
 				switch x := c.(type) {
@@ -317,6 +343,14 @@ func (p *Paragraph) String() string {
 					sb.WriteByte('\t')
 				case *BarterRabbet:
 					sb.WriteByte('\n')
+				case *FootnoteReference:
+					sb.WriteString("[^")
+					sb.WriteString(strconv.Itoa(x.ID))
+					sb.WriteByte(']')
+				case *EndnoteReference:
+					sb.WriteString("[^e")
+					sb.WriteString(strconv.Itoa(x.ID))
+					sb.WriteByte(']')
 				case *Drawing:
 					if x.Inline != nil {
 						sb.WriteString(x.Inline.String())
@@ -328,7 +362,7 @@ func (p *Paragraph) String() string {
 					}
 				}
 			}
-		// pPr
+		// pPr put into Children by hand
 		case *ParagraphProperties:
 			// NumPr と KeepNext の値があるかどうか。
 			if o.NumPr != nil {
@@ -348,222 +382,6 @@ func (p *Paragraph) String() string {
 	return sb.String()
 }
 
-// Numbering ...
-// 一旦変数に格納すれば済むもの。
-// これとは別に、「値の入力⇒定義に基づいた変換」という関数自体を id と結び付けて格納しておく map を用意する。
-
-type abstractNumFunc func(args *[]int) string
-
-var numbering *Numbering
-var abstractNums *[]AbstractNum
-var nums *[]*Num
-
-var numIDToAbstractNumIDMap = make(map[int]int)
-
-var abstractNumIDToAbstractNumMap = make(map[int]*AbstractNum)
-
-// key is NumPr.NumId, value is func
-var abstractNumToFuncMap = make(map[int]map[int]*abstractNumFunc)
-
-// abstractNumId キーごとに、また ilvl 階層ごとに、カウント値を格納する。
-var lvlCountListMap = make(map[int]*[]int)
-
-// 本編では連続出現 ID が同じ限りはカウントで、
-// そうでない場合はリセットされる。
-// 間に連続判定関数須。
-var numIDCountMap = make(map[int]int)
-var previousNumID int = -1
-
-// リセットは要らないかもしれない。
-func countNumID(numID int) int {
-	numIDCountMap[numID]++
-	// if numID == previousNumID {
-	// 	numIDCountMap[numID]++
-	// } else {
-	// 	// zero start
-	// 	numIDCountMap[numID] = 0
-	// }
-	// previousNumID = numID
-	return numIDCountMap[numID] - 1
-}
-
-func getNumberedString(p *Paragraph, numPr *NumPr) string {
-	// func getNumberedString(p *Paragraph, ilvl, numId int) string {
-	if numbering == nil {
-		numbering = &p.file.Numbering
-		abstractNums = numbering.AbstractNums
-		nums = numbering.Nums
-		generateNumIDToAbstractNumIDMap()
-		generateAbstractNumIDToAbstractNumMap()
-		generateAbstractNumToFuncMap()
-	}
-
-	// NumPr から NumID を取得
-	numID := numPr.NumID.Val
-	numIDCount := countNumID(numID)
-	// log.Println("numIDCount:", numIDCount)
-
-	// 対象 AbstructNumID を取得
-	abstractNumID := numIDToAbstractNumIDMap[numID]
-	// abstractNum := abstractNumIDToAbstractNumMap[abstractNumID]
-	iLvl := numPr.Ilvl.Val
-	// log.Println("iLvl:", iLvl)
-
-	f := abstractNumToFuncMap[abstractNumID][iLvl]
-	// log.Println("f:", *f)
-
-	iLvlCountList := getILvlCountList(abstractNumID, iLvl, numIDCount == 0)
-	// if iLvlCountList == nil {
-	// 	log.Println("iLvlCountList is nil")
-	// } else {
-	// 	log.Println("iLvlCountList:", *iLvlCountList)
-	// }
-
-	var s string
-	if f != nil {
-		s = (*f)(iLvlCountList)
-	} else {
-		log.Println("abstractNumID:", abstractNumID, "iLvl:", iLvl, "f is nil")
-		s = "＠"
-	}
-
-	// log.Println("s:", s)
-
-	return s
-}
-
-func generateNumIDToAbstractNumIDMap() {
-	for _, num := range *nums {
-		numID, err := GetInt(num.NumID)
-		if err != nil {
-			continue
-		}
-		abstractNumID, err := GetInt(num.AbstractNumID.Val)
-		if err != nil {
-			continue
-		}
-		numIDToAbstractNumIDMap[numID] = abstractNumID
-	}
-}
-
-func generateAbstractNumIDToAbstractNumMap() {
-	for _, abstractNum := range *abstractNums {
-		abstractNumID, err := GetInt(abstractNum.AbstractNumID)
-		if err != nil {
-			continue
-		}
-
-		// ここでは被りなし
-		// if abstractNumIDToAbstractNumMap[abstractNumID] != nil {
-		// 	log.Println("abstractNumIDToAbstractNumMap[abstractNumID] is not nil")
-		// }
-
-		// log.Println("abstractNumID:", abstractNumID, "abstractNum:", abstractNum)
-
-		abstractNumIDToAbstractNumMap[abstractNumID] = &abstractNum
-	}
-}
-
-func generateAbstractNumToFuncMap() {
-	numOfFunc := 0
-	for _, abstractNum := range *abstractNums {
-		abstractNumID, err := GetInt(abstractNum.AbstractNumID)
-		if err != nil {
-			continue
-		}
-		iLvlKeys := make([]int, 0, len(*abstractNum.Lvl))
-		for _, lvl := range *abstractNum.Lvl {
-			iLvlKeys = append(iLvlKeys, lvl.ILvl)
-		}
-		// log.Println("iLvlKeys:", iLvlKeys)
-
-		abstractNumToFuncMap[abstractNumID] = make(map[int]*abstractNumFunc)
-		for _, iLvl := range iLvlKeys {
-			// log.Println("iLvl:", iLvl)
-
-			// ★関数実行の以外の場では、正確な値が出ている。
-			lvlText := (*abstractNum.Lvl)[iLvl].LvlText.Val
-			// log.Println("lvlText:", lvlText)
-
-			// f := getAbstractNumFunc(abstractNumID, iLvl)
-			abstractNumToFuncMap[abstractNumID][iLvl] = getAbstractNumFunc(abstractNumID, iLvl, lvlText)
-			// abstractNumToFuncMap[abstractNumID][iLvl] = getAbstractNumFunc(abstractNumID, iLvl)
-			// abstractNumToFuncMap[abstractNumID][iLvl] = &f
-
-			// log.Println("abstractNumID:", abstractNumID, "iLvl:", iLvl, "f:", f)
-
-			numOfFunc++
-		}
-	}
-
-	// log.Println("numOfFunc:", numOfFunc)
-}
-
-func getILvlCountList(abstractNumID, iLvl int, isRestart bool) *[]int {
-	countList := lvlCountListMap[abstractNumID]
-	abstractNum := abstractNumIDToAbstractNumMap[abstractNumID]
-	// log.Println("abstractNum:", abstractNum)
-	start, err := GetInt((*abstractNum.Lvl)[iLvl].Start.Val)
-	if err != nil {
-		// log.Println("GetInt error:", err)
-		start = 0
-	}
-
-	if countList == nil || isRestart {
-		countList = &[]int{start}
-		lvlCountListMap[abstractNumID] = countList
-	} else {
-		if len(*countList) == iLvl {
-			// if len(*countList) < iLvl {
-			*countList = append(*countList, start)
-		} else {
-			(*countList)[iLvl]++
-		}
-	}
-	// log.Println("here.")
-	return countList
-}
-
-func getAbstractNumFunc(abstractNumID int,
-	iLvl int, lvlText string) *abstractNumFunc {
-	// log.Println("abstractNumID:", abstractNumID, "iLvl:", iLvl)
-	abstractNum := abstractNumIDToAbstractNumMap[abstractNumID]
-	numFmt := (*abstractNum.Lvl)[iLvl].NumFmt.Val
-	// lvlText := (*abstractNum.Lvl)[iLvl].LvlText.Val
-	// log.Println("lvlText:", lvlText)
-
-	f := func(iLvlCountList *[]int) string {
-		list := *iLvlCountList
-		var lvlTextReplaced string
-		// list から順に値を取り出す。
-		for _, val := range list {
-			n := GetFormatNumber(val, numFmt)
-
-			// lvlText から %i を探し、n に置き換える。
-			// "%i" がなければ何もしない。
-			// iStr := "%" + string(i+1)
-
-			// int to string
-			iStr := strconv.Itoa(iLvl + 1)
-			// log.Println("iStr:", iStr)
-
-			// log.Println("lvlText(before):", lvlText)
-
-			lvlTextReplaced = strings.Replace(lvlText, "%"+iStr, n, -1)
-			// log.Println(
-			// "val:", val,
-			// "n:", n,
-			// "lvlTextReplaced:", lvlTextReplaced)
-		}
-
-		return lvlTextReplaced
-		// return lvlText
-	}
-
-	// cast?
-	return (*abstractNumFunc)(&f)
-}
-
 func GetFormatNumber(val int, formatType string) string {
 	switch formatType {
 	case "bullet":
@@ -659,6 +477,9 @@ func (p *Paragraph) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
 			p.TextId = attr.Value
 		default:
 			// ignore other attributes
+			if ctx := contextOf(d); ctx.preserve() {
+				p.Attrs = append(p.Attrs, ctx.attr(attr))
+			}
 		}
 	}
 	children := make([]interface{}, 0, 64)
@@ -694,20 +515,15 @@ func (p *Paragraph) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
 			case "hyperlink":
 				// log.Println("hyperlink")
 				var value Hyperlink
-				err = d.DecodeElement(&value, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				value.preserved, err = decodePreserved(d, &tt, &value)
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
-				if p.Hyperlink == nil {
-					p.Hyperlink = &[]*Hyperlink{&value}
-				} else {
-					*p.Hyperlink = append(*p.Hyperlink, &value)
-				}
 				elem = &value
 			case "bookmarkStart":
 				var value BookmarkStart
 				err = d.DecodeElement(&value, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 
@@ -723,7 +539,7 @@ func (p *Paragraph) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
 			case "bookmarkEnd":
 				var value BookmarkEnd
 				err = d.DecodeElement(&value, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 
@@ -736,48 +552,60 @@ func (p *Paragraph) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
 				// }
 
 				elem = &value
+			case "commentRangeStart", "commentRangeEnd":
+				elem, err = parseCommentRange(d, tt)
+				if err != nil {
+					return err
+				}
+			case "ins", "del", "moveFrom", "moveTo",
+				"moveFromRangeStart", "moveFromRangeEnd", "moveToRangeStart", "moveToRangeEnd":
+				elem, err = parseRevision(d, tt, p.file)
+				if err != nil {
+					return err
+				}
 			case "sdt":
 				var value StructuredDocumentTag
-				err = d.DecodeElement(&value, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				value.preserved, err = decodePreserved(d, &tt, &value)
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 
-				if p.StructuredDocumentTag == nil {
-					p.StructuredDocumentTag = &[]*StructuredDocumentTag{&value}
-				} else {
-					*p.StructuredDocumentTag = append(*p.StructuredDocumentTag, &value)
-				}
-
 				elem = &value
 				// log.Println("sdt added in paragraph")
 			case "r":
 				var value Run
 				value.file = p.file
 				err = d.DecodeElement(&value, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 				elem = &value
 			case "rPr":
 				var value RunProperties
-				err = d.DecodeElement(&value, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				value.preserved, err = decodePreserved(d, &tt, &value)
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 				elem = &value
 			case "pPr":
 				var value ParagraphProperties
-				err = d.DecodeElement(&value, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				value.preserved, err = decodePreserved(d, &tt, &value)
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
-				// p.Properties = &value
-
-				// 重複するのでひとまず省く
-				elem = &value
+				// pPr は常に先頭にあり、Properties として出力される。
+				// Children にも入れると重複する。
+				p.Properties = &value
+				continue
 			default:
-				log.Println("UnmarshalXML Paragraph unsupported, skip:", tt.Name.Local)
+				if contextOf(d).preserve() {
+					elem, err = decodeRaw(d, tt)
+					if err != nil {
+						return err
+					}
+					break
+				}
+				logln(d, "UnmarshalXML Paragraph unsupported, skip:", tt.Name.Local)
 				err = d.Skip() // skip unsupported tags
 				if err != nil {
 					return err
@@ -795,178 +623,67 @@ func (p *Paragraph) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
 //
 // names: *docx.Hyperlink *docx.Run *docx.RunProperties
 func (p *Paragraph) KeepElements(name ...string) {
-	items := make([]interface{}, 0, len(p.Children))
-	namemap := make(map[string]struct{}, len(name)*2)
-	for _, n := range name {
-		namemap[n] = struct{}{}
-	}
-	for _, item := range p.Children {
-		_, ok := namemap[reflect.ValueOf(item).Type().String()]
-		if ok {
-			items = append(items, item)
+	keepElements(p, name)
+}
+
+// drawingRules match the drawings dropped by Body.DropDrawingOf
+var drawingRules = map[string]func(gd *AGraphicData) bool{
+	"Canvas": func(gd *AGraphicData) bool {
+		return gd != nil && gd.Canvas != nil
+	},
+	"Shape": func(gd *AGraphicData) bool {
+		return gd != nil && gd.Shape != nil
+	},
+	"Group": func(gd *AGraphicData) bool {
+		return gd != nil && gd.Group != nil
+	},
+	"ShapeAndCanvas": func(gd *AGraphicData) bool {
+		return gd != nil && (gd.Shape != nil || gd.Canvas != nil)
+	},
+	"ShapeAndCanvasAndGroup": func(gd *AGraphicData) bool {
+		return gd != nil && (gd.Shape != nil || gd.Canvas != nil || gd.Group != nil)
+	},
+	"NilPicture": func(gd *AGraphicData) bool {
+		return gd == nil || gd.Pic == nil
+	},
+}
+
+// dropDrawings removes the drawings in node matched by rule
+func dropDrawings(node Node, rule func(gd *AGraphicData) bool) {
+	Walk(node, func(n Node, _ WalkPath) WalkAction {
+		if d, ok := n.(*Drawing); ok && rule(d.graphicData()) {
+			return WalkRemove
 		}
-	}
-	p.Children = items
+		return WalkContinue
+	})
 }
 
 // DropCanvas drops all canvases in paragraph
 func (p *Paragraph) DropCanvas() {
-	for _, pc := range p.Children {
-		if r, ok := pc.(*Run); ok {
-			nrc := make([]interface{}, 0, len(r.Children))
-			for _, rc := range r.Children {
-				if d, ok := rc.(*Drawing); ok {
-					if d.Inline != nil && d.Inline.Graphic != nil && d.Inline.Graphic.GraphicData != nil {
-						if d.Inline.Graphic.GraphicData.Canvas != nil {
-							continue
-						}
-					}
-					if d.Anchor != nil && d.Anchor.Graphic != nil && d.Anchor.Graphic.GraphicData != nil {
-						if d.Anchor.Graphic.GraphicData.Canvas != nil {
-							continue
-						}
-					}
-				}
-				nrc = append(nrc, rc)
-			}
-			r.Children = nrc
-		}
-	}
+	dropDrawings(p, drawingRules["Canvas"])
 }
 
 // DropShape drops all shapes in paragraph
 func (p *Paragraph) DropShape() {
-	for _, pc := range p.Children {
-		if r, ok := pc.(*Run); ok {
-			nrc := make([]interface{}, 0, len(r.Children))
-			for _, rc := range r.Children {
-				if d, ok := rc.(*Drawing); ok {
-					if d.Inline != nil && d.Inline.Graphic != nil && d.Inline.Graphic.GraphicData != nil {
-						if d.Inline.Graphic.GraphicData.Shape != nil {
-							continue
-						}
-					}
-					if d.Anchor != nil && d.Anchor.Graphic != nil && d.Anchor.Graphic.GraphicData != nil {
-						if d.Anchor.Graphic.GraphicData.Shape != nil {
-							continue
-						}
-					}
-				}
-				nrc = append(nrc, rc)
-			}
-			r.Children = nrc
-		}
-	}
+	dropDrawings(p, drawingRules["Shape"])
 }
 
 // DropGroup drops all groups in paragraph
 func (p *Paragraph) DropGroup() {
-	for _, pc := range p.Children {
-		if r, ok := pc.(*Run); ok {
-			nrc := make([]interface{}, 0, len(r.Children))
-			for _, rc := range r.Children {
-				if d, ok := rc.(*Drawing); ok {
-					if d.Inline != nil && d.Inline.Graphic != nil && d.Inline.Graphic.GraphicData != nil {
-						if d.Inline.Graphic.GraphicData.Group != nil {
-							continue
-						}
-					}
-					if d.Anchor != nil && d.Anchor.Graphic != nil && d.Anchor.Graphic.GraphicData != nil {
-						if d.Anchor.Graphic.GraphicData.Group != nil {
-							continue
-						}
-					}
-				}
-				nrc = append(nrc, rc)
-			}
-			r.Children = nrc
-		}
-	}
+	dropDrawings(p, drawingRules["Group"])
 }
 
 // DropShapeAndCanvas drops all shapes and canvases in paragraph
 func (p *Paragraph) DropShapeAndCanvas() {
-	for _, pc := range p.Children {
-		if r, ok := pc.(*Run); ok {
-			nrc := make([]interface{}, 0, len(r.Children))
-			for _, rc := range r.Children {
-				if d, ok := rc.(*Drawing); ok {
-					if d.Inline != nil && d.Inline.Graphic != nil && d.Inline.Graphic.GraphicData != nil {
-						if d.Inline.Graphic.GraphicData.Shape != nil || d.Inline.Graphic.GraphicData.Canvas != nil {
-							continue
-						}
-					}
-					if d.Anchor != nil && d.Anchor.Graphic != nil && d.Anchor.Graphic.GraphicData != nil {
-						if d.Anchor.Graphic.GraphicData.Shape != nil || d.Anchor.Graphic.GraphicData.Canvas != nil {
-							continue
-						}
-					}
-				}
-				nrc = append(nrc, rc)
-			}
-			r.Children = nrc
-		}
-	}
+	dropDrawings(p, drawingRules["ShapeAndCanvas"])
 }
 
 // DropShapeAndCanvasAndGroup drops all shapes, canvases and groups in paragraph
 func (p *Paragraph) DropShapeAndCanvasAndGroup() {
-	for _, pc := range p.Children {
-		if r, ok := pc.(*Run); ok {
-			nrc := make([]interface{}, 0, len(r.Children))
-			for _, rc := range r.Children {
-				if d, ok := rc.(*Drawing); ok {
-					if d.Inline != nil && d.Inline.Graphic != nil && d.Inline.Graphic.GraphicData != nil {
-						if d.Inline.Graphic.GraphicData.Shape != nil || d.Inline.Graphic.GraphicData.Canvas != nil || d.Inline.Graphic.GraphicData.Group != nil {
-							continue
-						}
-					}
-					if d.Anchor != nil && d.Anchor.Graphic != nil && d.Anchor.Graphic.GraphicData != nil {
-						if d.Anchor.Graphic.GraphicData.Shape != nil || d.Anchor.Graphic.GraphicData.Canvas != nil || d.Anchor.Graphic.GraphicData.Group != nil {
-							continue
-						}
-					}
-				}
-				nrc = append(nrc, rc)
-			}
-			r.Children = nrc
-		}
-	}
+	dropDrawings(p, drawingRules["ShapeAndCanvasAndGroup"])
 }
 
 // DropNilPicture drops all drawings with nil picture in paragraph
 func (p *Paragraph) DropNilPicture() {
-	for _, pc := range p.Children {
-		if r, ok := pc.(*Run); ok {
-			nrc := make([]interface{}, 0, len(r.Children))
-			for _, rc := range r.Children {
-				if d, ok := rc.(*Drawing); ok {
-					if d.Inline == nil && d.Anchor == nil {
-						continue
-					}
-					if (d.Inline != nil && d.Inline.Graphic == nil) || (d.Anchor != nil && d.Anchor.Graphic == nil) {
-						continue
-					}
-					if d.Inline != nil && d.Inline.Graphic != nil && d.Inline.Graphic.GraphicData == nil {
-						continue
-					}
-					if d.Anchor != nil && d.Anchor.Graphic != nil && d.Anchor.Graphic.GraphicData == nil {
-						continue
-					}
-					if d.Inline != nil && d.Inline.Graphic != nil && d.Inline.Graphic.GraphicData != nil {
-						if d.Inline.Graphic.GraphicData.Pic == nil {
-							continue
-						}
-					}
-					if d.Anchor != nil && d.Anchor.Graphic != nil && d.Anchor.Graphic.GraphicData != nil {
-						if d.Anchor.Graphic.GraphicData.Pic == nil {
-							continue
-						}
-					}
-				}
-				nrc = append(nrc, rc)
-			}
-			r.Children = nrc
-		}
-	}
+	dropDrawings(p, drawingRules["NilPicture"])
 }
@@ -0,0 +1,443 @@
+/*
+   Copyright (c) 2020 gingfrederik
+   Copyright (c) 2021 Gonzalo Fernandez-Victorio
+   Copyright (c) 2021 Basement Crowd Ltd (https://www.basementcrowd.com)
+   Copyright (c) 2023 Fumiama Minamoto (源文雨)
+
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU Affero General Public License as published
+   by the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU Affero General Public License for more details.
+
+   You should have received a copy of the GNU Affero General Public License
+   along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package docx
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"sync"
+)
+
+// xmlURL is the namespace bound to prefix xml
+const xmlURL = "http://www.w3.org/XML/1998/namespace"
+
+// knownNamespaces are the prefixes that this package writes by itself
+var knownNamespaces = []struct{ prefix, url string }{
+	{"w", XMLNS_W},
+	{"w10", XMLNS_W10},
+	{"w14", XMLNS_W14},
+	{"w15", XMLNS_W15},
+	{"r", XMLNS_R},
+	{"wp", XMLNS_WP},
+	{"wps", XMLNS_WPS},
+	{"wpc", XMLNS_WPC},
+	{"wpg", XMLNS_WPG},
+	{"mc", XMLNS_MC},
+	{"wp14", XMLNS_WP14},
+	{"o", XMLNS_O},
+	{"v", XMLNS_V},
+}
+
+func knownPrefix(url string) (string, bool) {
+	for _, ns := range knownNamespaces {
+		if ns.url == url {
+			return ns.prefix, true
+		}
+	}
+	return "", false
+}
+
+// RawXML is an element that is not modelled by this package.
+// It is only produced in preserve mode and written back as is.
+//
+// Names are kept with their prefixes, like w:proofErr.
+type RawXML struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Inner   []byte     `xml:",innerxml"`
+}
+
+// decodeContext is what a decoder knows besides the tokens
+type decodeContext struct {
+	opt ParseOptions
+	// ns maps namespace url to the prefix declared in the root element
+	ns map[string]string
+}
+
+// decodeContexts binds *xml.Decoder to its *decodeContext.
+// All nested UnmarshalXML share the same decoder, so they
+// can look up the options without any extra parameter.
+var decodeContexts sync.Map
+
+// bindContext attaches ctx to d until the returned func is called
+func bindContext(d *xml.Decoder, ctx *decodeContext) (release func()) {
+	decodeContexts.Store(d, ctx)
+	return func() {
+		decodeContexts.Delete(d)
+	}
+}
+
+// contextOf returns the context of d, or nil if there is none
+func contextOf(d *xml.Decoder) *decodeContext {
+	ctx, ok := decodeContexts.Load(d)
+	if !ok {
+		return nil
+	}
+	return ctx.(*decodeContext)
+}
+
+// preserve tells whether unknown elements and attributes should be kept
+func (ctx *decodeContext) preserve() bool {
+	return ctx != nil && ctx.opt.Preserve
+}
+
+// rootAttrs records the namespaces declared on the root element and
+// returns the declarations to be written back besides explicit ones
+func (ctx *decodeContext) rootAttrs(attrs []xml.Attr, explicit ...string) []xml.Attr {
+	if ctx.ns == nil {
+		ctx.ns = make(map[string]string, len(attrs))
+	}
+	extra := make([]xml.Attr, 0, len(attrs))
+	for _, a := range attrs {
+		if a.Name.Space != "xmlns" {
+			continue
+		}
+		ctx.ns[a.Value] = a.Name.Local
+		if _, ok := knownPrefix(a.Value); ok {
+			continue
+		}
+		extra = append(extra, xml.Attr{Name: xml.Name{Local: "xmlns:" + a.Name.Local}, Value: a.Value})
+	}
+nextknown:
+	for _, ns := range knownNamespaces {
+		for _, e := range explicit {
+			if e == ns.url {
+				continue nextknown
+			}
+		}
+		extra = append(extra, xml.Attr{Name: xml.Name{Local: "xmlns:" + ns.prefix}, Value: ns.url})
+	}
+	return extra
+}
+
+// prefixOf finds the prefix to be written for url
+func (ctx *decodeContext) prefixOf(url string, scopes []map[string]string) (string, bool) {
+	for i := len(scopes) - 1; i >= 0; i-- {
+		if p, ok := scopes[i][url]; ok {
+			return p, true
+		}
+	}
+	if p, ok := knownPrefix(url); ok {
+		return p, true
+	}
+	if ctx != nil {
+		if p, ok := ctx.ns[url]; ok {
+			return p, true
+		}
+	}
+	return "", false
+}
+
+// name turns a decoded name back into its prefixed form
+func (ctx *decodeContext) name(n xml.Name, scopes []map[string]string) xml.Name {
+	switch {
+	case n.Space == "":
+		return n
+	case n.Space == "xmlns":
+		return xml.Name{Local: "xmlns:" + n.Local}
+	case n.Space == xmlURL:
+		return xml.Name{Local: "xml:" + n.Local}
+	}
+	p, ok := ctx.prefixOf(n.Space, scopes)
+	if !ok && !strings.ContainsAny(n.Space, ":/") {
+		// undeclared prefix is left as is by the decoder
+		p = n.Space
+	}
+	if p == "" {
+		return xml.Name{Local: n.Local}
+	}
+	return xml.Name{Local: p + ":" + n.Local}
+}
+
+// attr turns a decoded attribute back into its prefixed form
+func (ctx *decodeContext) attr(a xml.Attr) xml.Attr {
+	return xml.Attr{Name: ctx.name(a.Name, nil), Value: a.Value}
+}
+
+// pushScope records the namespaces declared on an element
+func pushScope(scopes []map[string]string, attrs []xml.Attr) []map[string]string {
+	var m map[string]string
+	for _, a := range attrs {
+		switch {
+		case a.Name.Space == "xmlns":
+			if m == nil {
+				m = make(map[string]string, 4)
+			}
+			m[a.Value] = a.Name.Local
+		case a.Name.Space == "" && a.Name.Local == "xmlns":
+			if m == nil {
+				m = make(map[string]string, 4)
+			}
+			m[a.Value] = ""
+		}
+	}
+	return append(scopes, m)
+}
+
+// captureElement reads the whole element started by start
+func captureElement(d *xml.Decoder, start xml.StartElement) ([]xml.Token, error) {
+	toks := make([]xml.Token, 1, 16)
+	toks[0] = start.Copy()
+	depth := 1
+	for depth > 0 {
+		t, err := d.Token()
+		if err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+		switch t.(type) {
+		case xml.StartElement:
+			depth++
+		case xml.EndElement:
+			depth--
+		}
+		toks = append(toks, xml.CopyToken(t))
+	}
+	return toks, nil
+}
+
+// tokenReplayer reads captured tokens again
+type tokenReplayer struct {
+	toks []xml.Token
+}
+
+// Token implements xml.TokenReader
+func (r *tokenReplayer) Token() (xml.Token, error) {
+	if len(r.toks) == 0 {
+		return nil, io.EOF
+	}
+	t := r.toks[0]
+	r.toks = r.toks[1:]
+	return t, nil
+}
+
+// replay returns a decoder of toks sharing ctx, and its release func
+func (ctx *decodeContext) replay(toks []xml.Token) (*xml.Decoder, func()) {
+	rd := xml.NewTokenDecoder(&tokenReplayer{toks: toks})
+	return rd, bindContext(rd, ctx)
+}
+
+// raw serializes captured tokens into a RawXML
+func (ctx *decodeContext) raw(toks []xml.Token) *RawXML {
+	start := toks[0].(xml.StartElement)
+	scopes := pushScope(make([]map[string]string, 0, 8), start.Attr)
+	r := &RawXML{
+		XMLName: ctx.name(start.Name, scopes),
+		Attrs:   make([]xml.Attr, len(start.Attr)),
+	}
+	for i, a := range start.Attr {
+		r.Attrs[i] = xml.Attr{Name: ctx.name(a.Name, scopes), Value: a.Value}
+	}
+	var buf bytes.Buffer
+	inner := toks[1 : len(toks)-1]
+	for i, t := range inner {
+		switch tt := t.(type) {
+		case xml.StartElement:
+			scopes = pushScope(scopes, tt.Attr)
+			buf.WriteByte('<')
+			buf.WriteString(ctx.name(tt.Name, scopes).Local)
+			for _, a := range tt.Attr {
+				buf.WriteByte(' ')
+				buf.WriteString(ctx.name(a.Name, scopes).Local)
+				buf.WriteString(`="`)
+				escapeAttr(&buf, a.Value)
+				buf.WriteByte('"')
+			}
+			if i+1 < len(inner) {
+				if _, ok := inner[i+1].(xml.EndElement); ok {
+					buf.WriteString("/>")
+					continue
+				}
+			}
+			buf.WriteByte('>')
+		case xml.EndElement:
+			if i > 0 {
+				if _, ok := inner[i-1].(xml.StartElement); ok {
+					scopes = scopes[:len(scopes)-1]
+					continue
+				}
+			}
+			buf.WriteString("</")
+			buf.WriteString(ctx.name(tt.Name, scopes).Local)
+			buf.WriteByte('>')
+			scopes = scopes[:len(scopes)-1]
+		case xml.CharData:
+			escapeText(&buf, tt)
+		case xml.Comment:
+			buf.WriteString("<!--")
+			buf.Write(tt)
+			buf.WriteString("-->")
+		case xml.ProcInst:
+			buf.WriteString("<?")
+			buf.WriteString(tt.Target)
+			if len(tt.Inst) > 0 {
+				buf.WriteByte(' ')
+				buf.Write(tt.Inst)
+			}
+			buf.WriteString("?>")
+		case xml.Directive:
+			buf.WriteString("<!")
+			buf.Write(tt)
+			buf.WriteByte('>')
+		}
+	}
+	r.Inner = buf.Bytes()
+	return r
+}
+
+func escapeText(buf *bytes.Buffer, s []byte) {
+	for _, c := range s {
+		switch c {
+		case '&':
+			buf.WriteString("&amp;")
+		case '<':
+			buf.WriteString("&lt;")
+		case '>':
+			buf.WriteString("&gt;")
+		default:
+			buf.WriteByte(c)
+		}
+	}
+}
+
+func escapeAttr(buf *bytes.Buffer, s string) {
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '&':
+			buf.WriteString("&amp;")
+		case '<':
+			buf.WriteString("&lt;")
+		case '"':
+			buf.WriteString("&quot;")
+		case '\t':
+			buf.WriteString("&#x9;")
+		case '\n':
+			buf.WriteString("&#xA;")
+		case '\r':
+			buf.WriteString("&#xD;")
+		default:
+			buf.WriteByte(c)
+		}
+	}
+}
+
+// decodeRaw reads the element started by start as a RawXML
+func decodeRaw(d *xml.Decoder, start xml.StartElement) (*RawXML, error) {
+	toks, err := captureElement(d, start)
+	if err != nil {
+		return nil, err
+	}
+	return contextOf(d).raw(toks), nil
+}
+
+// preserved keeps a modelled element as it was in source.
+// The source is written back instead of the model as long as
+// the model marshals into the same bytes as right after parsing.
+type preserved struct {
+	snapshot []byte
+	origin   *RawXML
+}
+
+// decodePreserved decodes the element started by start into v
+// and, in preserve mode, returns its source for marshalPreserved
+func decodePreserved(d *xml.Decoder, start *xml.StartElement, v interface{}) (*preserved, error) {
+	ctx := contextOf(d)
+	if !ctx.preserve() {
+		return nil, d.DecodeElement(v, start)
+	}
+	toks, err := captureElement(d, *start)
+	if err != nil {
+		return nil, err
+	}
+	rd, release := ctx.replay(toks)
+	err = rd.Decode(v)
+	release()
+	if err != nil && !ignorable(d, err) {
+		return nil, err
+	}
+	snapshot, err := xml.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return &preserved{snapshot: snapshot, origin: ctx.raw(toks)}, nil
+}
+
+// marshalPreserved writes p.origin if plain is not modified, or plain itself.
+//
+// start is ignored because encoding/xml names a Marshaler by its field
+// rather than its XMLName, so plain is named by its own XMLName tag.
+func marshalPreserved(e *xml.Encoder, _ xml.StartElement, plain interface{}, p *preserved) error {
+	if p != nil {
+		b, err := xml.Marshal(plain)
+		if err == nil && bytes.Equal(b, p.snapshot) {
+			return e.Encode(p.origin)
+		}
+	}
+	return e.Encode(plain)
+}
+
+// rawSlot is a RawXML placed before the idx-th typed child
+// of a container like Table and WTableRow
+type rawSlot struct {
+	idx  int
+	node *RawXML
+}
+
+// encodeSlots writes the raw nodes that are placed before the idx-th child
+func encodeSlots(e *xml.Encoder, slots []rawSlot, idx int, last bool) error {
+	for _, s := range slots {
+		if s.idx == idx || (last && s.idx > idx) {
+			err := e.Encode(s.node)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// encodeWithSlots writes start, the children with the raw nodes
+// placed before them, and the end of start
+func encodeWithSlots(e *xml.Encoder, start xml.StartElement, children []interface{}, slots []rawSlot) error {
+	err := e.EncodeToken(start)
+	if err != nil {
+		return err
+	}
+	for i, c := range children {
+		err = encodeSlots(e, slots, i, false)
+		if err != nil {
+			return err
+		}
+		err = e.Encode(c)
+		if err != nil {
+			return err
+		}
+	}
+	err = encodeSlots(e, slots, len(children), true)
+	if err != nil {
+		return err
+	}
+	return e.EncodeToken(start.End())
+}
@@ -0,0 +1,198 @@
+/*
+   Copyright (c) 2020 gingfrederik
+   Copyright (c) 2021 Gonzalo Fernandez-Victorio
+   Copyright (c) 2021 Basement Crowd Ltd (https://www.basementcrowd.com)
+   Copyright (c) 2023 Fumiama Minamoto (源文雨)
+
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU Affero General Public License as published
+   by the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU Affero General Public License for more details.
+
+   You should have received a copy of the GNU Affero General Public License
+   along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package docx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"sort"
+	"strings"
+	"testing"
+)
+
+const preserveDocument = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:wpc="http://schemas.microsoft.com/office/word/2010/wordprocessingCanvas" xmlns:mc="http://schemas.openxmlformats.org/markup-compatibility/2006" xmlns:o="urn:schemas-microsoft-com:office:office" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships" xmlns:m="http://schemas.openxmlformats.org/officeDocument/2006/math" xmlns:v="urn:schemas-microsoft-com:vml" xmlns:wp14="http://schemas.microsoft.com/office/word/2010/wordprocessingDrawing" xmlns:wp="http://schemas.openxmlformats.org/drawingml/2006/wordprocessingDrawing" xmlns:w10="urn:schemas-microsoft-com:office:word" xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" xmlns:w14="http://schemas.microsoft.com/office/word/2010/wordml" xmlns:w15="http://schemas.microsoft.com/office/word/2012/wordml" xmlns:w16se="http://schemas.microsoft.com/office/word/2015/wordml/symex" xmlns:wpg="http://schemas.microsoft.com/office/word/2010/wordprocessingGroup" xmlns:wps="http://schemas.microsoft.com/office/word/2010/wordprocessingShape" mc:Ignorable="w14 w15 w16se wp14"><w:background w:color="FFFF00"/><w:body><w:p w14:paraId="77CA082D" w:rsidDel="00D66E3F" w16se:unknown="1"><w:pPr><w:pStyle w:val="Heading1"/><w:outlineLvl w:val="0"/><w:rPr><w:lang w:val="en-US" w:eastAsia="zh-CN"/><w:emboss/></w:rPr></w:pPr><w:bookmarkStart w:id="0" w:name="_GoBack"/><w:proofErr w:type="spellStart"/><w:r w:rsidDel="00D66E3F"><w:rPr><w:b/><w:outline/></w:rPr><w:t xml:space="preserve">keep </w:t><w:sym w:font="Wingdings" w:char="F0E0"/></w:r><w:proofErr w:type="spellEnd"/><w:ins w:id="1" w:author="A &amp; B" w:date="2023-01-01T00:00:00Z"><w:r><w:t>inserted</w:t></w:r></w:ins><w:del w:id="2" w:author="A"><w:r><w:delText>deleted</w:delText></w:r></w:del><m:oMath><m:r><m:t>x&lt;2</m:t></m:r></m:oMath><w:bookmarkEnd w:id="0"/></w:p><w:tbl><w:tblPr><w:tblW w:w="0" w:type="auto"/><w:tblCellMar><w:left w:w="10" w:type="dxa"/></w:tblCellMar></w:tblPr><w:tblGrid><w:gridCol w:w="100"/></w:tblGrid><w:bookmarkStart w:id="3" w:name="row"/><w:tr w:rsidR="00D66E3F" w14:paraId="1CA8A9B3"><w:trPr><w:cantSplit/></w:trPr><w:tc><w:tcPr><w:tcW w:w="100" w:type="dxa"/><w:noWrap/></w:tcPr><w:tbl><w:tr><w:tc><w:p><w:r><w:t>nested</w:t></w:r></w:p></w:tc></w:tr></w:tbl><w:p><w:r><w:t>cell</w:t></w:r></w:p></w:tc></w:tr><w:bookmarkEnd w:id="3"/></w:tbl><w:p><w:r><mc:AlternateContent><mc:Choice Requires="wps"><w:drawing><wp:inline distT="0" distB="0" distL="0" distR="0"><wp:extent cx="1" cy="1"/><wp:docPr id="1" name="shape"/><a:graphic xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main"><a:graphicData uri="http://schemas.microsoft.com/office/word/2010/wordprocessingShape"><wps:wsp><wps:bodyPr/></wps:wsp></a:graphicData></a:graphic></wp:inline></w:drawing></mc:Choice><mc:Fallback><w:pict><v:rect style="width:1pt;height:1pt"/></w:pict></mc:Fallback></mc:AlternateContent></w:r><w:customXml w:element="x"><w:r><w:t>custom</w:t></w:r></w:customXml></w:p><w:sectPr><w:pgSz w:w="11906" w:h="16838"/><w:pgNumType w:start="3"/></w:sectPr></w:body></w:document>`
+
+// canonicalBody flattens the body into comparable lines
+func canonicalBody(t *testing.T, data []byte) []string {
+	return canonicalXML(t, data, "background", "body")
+}
+
+// canonicalXML flattens the elements named roots into comparable lines
+func canonicalXML(t *testing.T, data []byte, roots ...string) []string {
+	isroot := func(name string) bool {
+		for _, r := range roots {
+			if r == name {
+				return true
+			}
+		}
+		return false
+	}
+	d := xml.NewDecoder(bytes.NewReader(data))
+	lines := make([]string, 0, 256)
+	inbody := false
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		switch tt := tok.(type) {
+		case xml.StartElement:
+			if isroot(tt.Name.Local) {
+				inbody = true
+			}
+			if !inbody {
+				continue
+			}
+			attrs := make([]string, 0, len(tt.Attr))
+			for _, a := range tt.Attr {
+				if a.Name.Space == "xmlns" {
+					continue
+				}
+				attrs = append(attrs, a.Name.Space+" "+a.Name.Local+"="+a.Value)
+			}
+			sort.Strings(attrs)
+			lines = append(lines, "<"+tt.Name.Space+" "+tt.Name.Local+" "+strings.Join(attrs, " "))
+		case xml.EndElement:
+			if inbody {
+				lines = append(lines, ">"+tt.Name.Local)
+			}
+			if isroot(tt.Name.Local) {
+				inbody = false
+			}
+		case xml.CharData:
+			if inbody && len(bytes.TrimSpace(tt)) > 0 {
+				lines = append(lines, string(tt))
+			}
+		}
+	}
+	return lines
+}
+
+func readZipFile(t *testing.T, data []byte, name string) []byte {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := zr.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	b, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// replaceDocument returns a new A4 docx whose document.xml is doc
+func replaceDocument(t *testing.T, doc string) []byte {
+	buf := bytes.NewBuffer(make([]byte, 0, 65536))
+	_, err := NewA4().WriteTo(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := bytes.NewBuffer(make([]byte, 0, 65536))
+	zw := zip.NewWriter(src)
+	for _, f := range zr.File {
+		w, err := zw.Create(f.Name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if f.Name == "word/document.xml" {
+			_, err = io.WriteString(w, doc)
+		} else {
+			var r io.ReadCloser
+			r, err = f.Open()
+			if err == nil {
+				_, err = io.Copy(w, r)
+			}
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = zw.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return src.Bytes()
+}
+
+func TestPreserveRoundTrip(t *testing.T) {
+	src := replaceDocument(t, preserveDocument)
+	doc, err := ParseWithOptions(bytes.NewReader(src), int64(len(src)), ParseOptions{Preserve: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := bytes.NewBuffer(make([]byte, 0, 65536))
+	_, err = doc.WriteTo(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := readZipFile(t, out.Bytes(), "word/document.xml")
+	exp := canonicalBody(t, []byte(preserveDocument))
+	act := canonicalBody(t, got)
+	if strings.Join(exp, "\n") != strings.Join(act, "\n") {
+		t.Fatal("not preserved:\n", string(got))
+	}
+	if !bytes.Contains(got, []byte(`mc:Ignorable="w14 w15 w16se wp14"`)) || !bytes.Contains(got, []byte(`xmlns:w16se=`)) {
+		t.Fatal("root namespaces not preserved:\n", string(got))
+	}
+
+	// modified properties fall back to the model
+	p := doc.Document.Body.Items[0].(*Paragraph)
+	p.Justification("center")
+	out.Reset()
+	_, err = doc.WriteTo(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = readZipFile(t, out.Bytes(), "word/document.xml")
+	if !bytes.Contains(got, []byte(`<w:jc w:val="center"`)) || !bytes.Contains(got, []byte(`<w:proofErr w:type="spellStart"`)) {
+		t.Fatal("modification lost:\n", string(got))
+	}
+
+	// without preserve mode, unknown elements are dropped as before
+	doc, err = Parse(bytes.NewReader(src), int64(len(src)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	_, err = doc.WriteTo(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = readZipFile(t, out.Bytes(), "word/document.xml")
+	if bytes.Contains(got, []byte(`proofErr`)) {
+		t.Fatal("unexpected proofErr:\n", string(got))
+	}
+}
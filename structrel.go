@@ -25,6 +25,14 @@ const (
 	XMLNS_REL     = `http://schemas.openxmlformats.org/package/2006/relationships`
 	REL_HYPERLINK = `http://schemas.openxmlformats.org/officeDocument/2006/relationships/hyperlink`
 	REL_IMAGE     = `http://schemas.openxmlformats.org/officeDocument/2006/relationships/image`
+	REL_HEADER    = `http://schemas.openxmlformats.org/officeDocument/2006/relationships/header`
+	REL_FOOTER    = `http://schemas.openxmlformats.org/officeDocument/2006/relationships/footer`
+	REL_STYLES    = `http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles`
+	REL_FOOTNOTES = `http://schemas.openxmlformats.org/officeDocument/2006/relationships/footnotes`
+	REL_ENDNOTES  = `http://schemas.openxmlformats.org/officeDocument/2006/relationships/endnotes`
+	REL_COMMENTS  = `http://schemas.openxmlformats.org/officeDocument/2006/relationships/comments`
+
+	REL_COMMENTS_EXTENDED = `http://schemas.microsoft.com/office/2011/relationships/commentsExtended`
 
 	REL_TARGETMODE = "External"
 )
@@ -0,0 +1,302 @@
+/*
+   Copyright (c) 2020 gingfrederik
+   Copyright (c) 2021 Gonzalo Fernandez-Victorio
+   Copyright (c) 2021 Basement Crowd Ltd (https://www.basementcrowd.com)
+   Copyright (c) 2023 Fumiama Minamoto (源文雨)
+
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU Affero General Public License as published
+   by the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU Affero General Public License for more details.
+
+   You should have received a copy of the GNU Affero General Public License
+   along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package docx
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+)
+
+// Revision is the common attributes of a tracked change
+type Revision struct {
+	ID     int    `xml:"w:id,attr"`
+	Author string `xml:"w:author,attr,omitempty"`
+	Date   string `xml:"w:date,attr,omitempty"`
+}
+
+// parse reads w:id, w:author and w:date
+func (rv *Revision) parse(attrs []xml.Attr) error {
+	for _, a := range attrs {
+		switch a.Name.Local {
+		case "id":
+			id, err := strconv.Atoi(a.Value)
+			if err != nil {
+				return err
+			}
+			rv.ID = id
+		case "author":
+			rv.Author = a.Value
+		case "date":
+			rv.Date = a.Value
+		}
+	}
+	return nil
+}
+
+// Insertion <w:ins> holds the runs inserted with change tracking
+type Insertion struct {
+	XMLName xml.Name `xml:"w:ins"`
+	Revision
+
+	Children []interface{}
+}
+
+// Deletion <w:del> holds the runs deleted with change tracking
+type Deletion struct {
+	XMLName xml.Name `xml:"w:del"`
+	Revision
+
+	Children []interface{}
+}
+
+// MoveFrom <w:moveFrom> holds the runs moved away with change tracking
+type MoveFrom struct {
+	XMLName xml.Name `xml:"w:moveFrom"`
+	Revision
+
+	Children []interface{}
+}
+
+// MoveTo <w:moveTo> holds the runs moved here with change tracking
+type MoveTo struct {
+	XMLName xml.Name `xml:"w:moveTo"`
+	Revision
+
+	Children []interface{}
+}
+
+// MoveRangeStart <w:moveFromRangeStart> or <w:moveToRangeStart>
+// marks the beginning of a move
+type MoveRangeStart struct {
+	XMLName xml.Name
+	Revision
+	Name string `xml:"w:name,attr,omitempty"`
+}
+
+// MoveRangeEnd <w:moveFromRangeEnd> or <w:moveToRangeEnd>
+// marks the end of a move
+type MoveRangeEnd struct {
+	XMLName xml.Name
+	ID      int `xml:"w:id,attr"`
+}
+
+// InsertedMark <w:ins> in the rPr of pPr marks the paragraph mark as inserted
+type InsertedMark struct {
+	XMLName xml.Name `xml:"w:ins"`
+	Revision
+}
+
+// DeletedMark <w:del> in the rPr of pPr marks the paragraph mark as deleted
+type DeletedMark struct {
+	XMLName xml.Name `xml:"w:del"`
+	Revision
+}
+
+// RunPropertiesChange <w:rPrChange> holds the run properties before the change
+type RunPropertiesChange struct {
+	XMLName xml.Name `xml:"w:rPrChange"`
+	Revision
+
+	RunProperties *RunProperties
+}
+
+// ParagraphPropertiesChange <w:pPrChange> holds the paragraph properties before the change
+type ParagraphPropertiesChange struct {
+	XMLName xml.Name `xml:"w:pPrChange"`
+	Revision
+
+	ParagraphProperties *ParagraphProperties
+}
+
+// DeletedText <w:delText> is the text of a deleted run
+type DeletedText struct {
+	XMLName xml.Name `xml:"w:delText,omitempty"`
+
+	XMLSpace string `xml:"xml:space,attr,omitempty"`
+
+	Text string `xml:",chardata"`
+}
+
+// UnmarshalXML ...
+func (t *DeletedText) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	return (*Text)(t).UnmarshalXML(d, start)
+}
+
+// UnmarshalXML ...
+func (c *RunPropertiesChange) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	err := c.Revision.parse(start.Attr)
+	if err != nil {
+		return err
+	}
+	for {
+		t, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		tt, ok := t.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if tt.Name.Local != "rPr" {
+			err = d.Skip()
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		var value RunProperties
+		err = d.DecodeElement(&value, &tt)
+		if err != nil && !ignorable(d, err) {
+			return err
+		}
+		c.RunProperties = &value
+	}
+	return nil
+}
+
+// UnmarshalXML ...
+func (c *ParagraphPropertiesChange) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	err := c.Revision.parse(start.Attr)
+	if err != nil {
+		return err
+	}
+	for {
+		t, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		tt, ok := t.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if tt.Name.Local != "pPr" {
+			err = d.Skip()
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		var value ParagraphProperties
+		err = d.DecodeElement(&value, &tt)
+		if err != nil && !ignorable(d, err) {
+			return err
+		}
+		c.ParagraphProperties = &value
+	}
+	return nil
+}
+
+// parseRevision reads a tracked change of runs in paragraph,
+// that is, w:ins, w:del, w:moveFrom, w:moveTo and the move ranges
+func parseRevision(d *xml.Decoder, tt xml.StartElement, file *Docx) (interface{}, error) {
+	var rv Revision
+	err := rv.parse(tt.Attr)
+	if err != nil {
+		return nil, err
+	}
+	switch tt.Name.Local {
+	case "moveFromRangeStart", "moveToRangeStart":
+		return &MoveRangeStart{
+			XMLName:  xml.Name{Local: "w:" + tt.Name.Local},
+			Revision: rv,
+			Name:     getAtt(tt.Attr, "name"),
+		}, d.Skip()
+	case "moveFromRangeEnd", "moveToRangeEnd":
+		return &MoveRangeEnd{
+			XMLName: xml.Name{Local: "w:" + tt.Name.Local},
+			ID:      rv.ID,
+		}, d.Skip()
+	}
+	children, err := parseRevisionContent(d, file)
+	if err != nil {
+		return nil, err
+	}
+	switch tt.Name.Local {
+	case "ins":
+		return &Insertion{Revision: rv, Children: children}, nil
+	case "del":
+		return &Deletion{Revision: rv, Children: children}, nil
+	case "moveFrom":
+		return &MoveFrom{Revision: rv, Children: children}, nil
+	default:
+		return &MoveTo{Revision: rv, Children: children}, nil
+	}
+}
+
+// parseRevisionContent reads the children of w:ins, w:del, w:moveFrom and w:moveTo
+func parseRevisionContent(d *xml.Decoder, file *Docx) ([]interface{}, error) {
+	children := make([]interface{}, 0, 4)
+	for {
+		t, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := t.(xml.EndElement); ok {
+			// children are consumed as a whole, so this is the end of parent
+			break
+		}
+		tt, ok := t.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		var elem interface{}
+		switch tt.Name.Local {
+		case "r":
+			value := &Run{file: file}
+			err = d.DecodeElement(value, &tt)
+			if err != nil && !ignorable(d, err) {
+				return nil, err
+			}
+			elem = value
+		case "commentRangeStart", "commentRangeEnd":
+			elem, err = parseCommentRange(d, tt)
+			if err != nil {
+				return nil, err
+			}
+		default:
+			if contextOf(d).preserve() {
+				elem, err = decodeRaw(d, tt)
+				if err != nil {
+					return nil, err
+				}
+				break
+			}
+			logln(d, "UnmarshalXML revision unsupported, skip:", tt.Name.Local)
+			err = d.Skip()
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		children = append(children, elem)
+	}
+	return children, nil
+}
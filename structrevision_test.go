@@ -0,0 +1,174 @@
+/*
+   Copyright (c) 2020 gingfrederik
+   Copyright (c) 2021 Gonzalo Fernandez-Victorio
+   Copyright (c) 2021 Basement Crowd Ltd (https://www.basementcrowd.com)
+   Copyright (c) 2023 Fumiama Minamoto (源文雨)
+
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU Affero General Public License as published
+   by the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU Affero General Public License for more details.
+
+   You should have received a copy of the GNU Affero General Public License
+   along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package docx
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+const revisionsDocument = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>
+<w:p><w:pPr><w:jc w:val="center"/><w:pPrChange w:id="1" w:author="Alice"><w:pPr><w:jc w:val="left"/></w:pPr></w:pPrChange></w:pPr>
+<w:r><w:t xml:space="preserve">The </w:t></w:r>
+<w:del w:id="2" w:author="Alice"><w:r><w:delText>seller</w:delText></w:r></w:del>
+<w:ins w:id="3" w:author="Alice"><w:r><w:t>buyer</w:t></w:r></w:ins>
+<w:r><w:rPr><w:b/><w:rPrChange w:id="4" w:author="Bob"><w:rPr/></w:rPrChange></w:rPr><w:t xml:space="preserve"> shall pay</w:t></w:r>
+<w:ins w:id="5" w:author="Bob"><w:r><w:t xml:space="preserve"> promptly</w:t></w:r></w:ins>
+<w:r><w:t>.</w:t></w:r></w:p>
+<w:p><w:pPr><w:rPr><w:del w:id="6" w:author="Bob"/></w:rPr></w:pPr>
+<w:moveFromRangeStart w:id="7" w:author="Alice" w:name="move1"/>
+<w:moveFrom w:id="8" w:author="Alice"><w:r><w:t>Moved.</w:t></w:r></w:moveFrom>
+<w:moveFromRangeEnd w:id="7"/></w:p>
+<w:p><w:r><w:t>Last</w:t></w:r>
+<w:moveToRangeStart w:id="9" w:author="Alice" w:name="move1"/>
+<w:moveTo w:id="10" w:author="Alice"><w:r><w:t xml:space="preserve"> Moved.</w:t></w:r></w:moveTo>
+<w:moveToRangeEnd w:id="9"/></w:p>
+</w:body></w:document>`
+
+func TestRevisions(t *testing.T) {
+	data := replaceDocument(t, revisionsDocument)
+	parse := func() *Docx {
+		doc, err := Parse(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return doc
+	}
+	text := func(doc *Docx) string {
+		lines := make([]string, 0, 4)
+		for _, it := range doc.Document.Body.Items {
+			if p, ok := it.(*Paragraph); ok {
+				lines = append(lines, p.String())
+			}
+		}
+		return strings.Join(lines, "\n")
+	}
+
+	doc := parse()
+	if len(doc.Revisions()) != 10 {
+		t.Fatal("unexpected revisions", len(doc.Revisions()))
+	}
+	if s := text(doc); s != "The buyer shall pay promptly.\n\nLast Moved." {
+		t.Fatal("unexpected text", s)
+	}
+
+	doc.AcceptRevisions(RevisionsBy("Alice"))
+	if s := text(doc); s != "The buyer shall pay promptly.\n\nLast Moved." {
+		t.Fatal("unexpected text", s)
+	}
+	if len(doc.Revisions()) != 3 {
+		t.Fatal("unexpected revisions", len(doc.Revisions()))
+	}
+	doc.RejectRevisions(RevisionsBy("Bob"))
+	if s := text(doc); s != "The buyer shall pay.\n\nLast Moved." {
+		t.Fatal("unexpected text", s)
+	}
+	p := doc.Document.Body.Items[0].(*Paragraph)
+	if p.Properties.Change != nil || p.Properties.Justification.Val != "center" {
+		t.Fatal("paragraph properties change not accepted")
+	}
+	if r := p.Children[2].(*Run); r.RunProperties.Bold != nil {
+		t.Fatal("run properties change not rejected")
+	}
+
+	doc = parse()
+	doc.AcceptAllRevisions()
+	if s := text(doc); s != "The buyer shall pay promptly.\nLast Moved." {
+		t.Fatal("unexpected text", s)
+	}
+	buf := bytes.NewBuffer(make([]byte, 0, 65536))
+	_, err := doc.WriteTo(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tag := range []string{"<w:ins", "<w:del", "<w:move", "Change"} {
+		if bytes.Contains(readZipFile(t, buf.Bytes(), "word/document.xml"), []byte(tag)) {
+			t.Fatal(tag, "remains")
+		}
+	}
+
+	doc = parse()
+	doc.RejectAllRevisions()
+	if s := text(doc); s != "The seller shall pay.\nMoved.\nLast" {
+		t.Fatal("unexpected text", s)
+	}
+
+	// tracked edits survive a round-trip
+	w := NewA4()
+	p = w.AddParagraph()
+	old := p.AddText("30 days")
+	p.TrackReplace(old, "Bob", "60 days")
+	p.AddTrackedText("Bob", " net")
+	old.TrackProperties("Bob")
+	old.Bold()
+	buf.Reset()
+	_, err = w.WriteTo(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err = Parse(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	revs := doc.Revisions()
+	if len(revs) != 4 || revs[0].Author != "Bob" || revs[0].ID == revs[1].ID {
+		t.Fatal("unexpected revisions", revs)
+	}
+	if s := text(doc); s != "60 days net" {
+		t.Fatal("unexpected text", s)
+	}
+	doc.RejectAllRevisions()
+	if s := text(doc); s != "30 days" {
+		t.Fatal("unexpected text", s)
+	}
+}
+
+func TestTrackReplaceProperties(t *testing.T) {
+	w := NewA4()
+	p := w.AddParagraph()
+	old := p.AddText("30 days").Color("FF0000")
+	old.RunProperties.Inserted = &InsertedMark{Revision: Revision{ID: 1, Author: "Alice"}}
+	_, ins := p.TrackReplace(old, "Bob", "60 days")
+	rp := ins.Children[0].(*Run).RunProperties
+	if rp.Inserted != nil || rp.Deleted != nil || rp.Change != nil {
+		t.Fatal("revision marks are copied", rp)
+	}
+	rp.Color.Val = "00FF00"
+	if old.RunProperties.Color.Val != "FF0000" {
+		t.Fatal("properties are shared with the replaced run")
+	}
+}
+
+func TestTrackParagraphProperties(t *testing.T) {
+	w := NewA4()
+	p := w.AddParagraph().Justification("center")
+	p.AddText("title")
+	c := p.TrackProperties("Bob")
+	p.Properties.Justification.Val = "right"
+	if c.ParagraphProperties.Justification.Val != "center" {
+		t.Fatal("properties are shared with the tracked change")
+	}
+	if c.ParagraphProperties.Change != nil || p.Properties.Change != c {
+		t.Fatal("unexpected change", c.ParagraphProperties.Change, p.Properties.Change)
+	}
+}
@@ -23,9 +23,7 @@ package docx
 import (
 	"encoding/xml"
 	"io"
-	"log"
-	"reflect"
-	"strings"
+	"strconv"
 )
 
 // Run is part of a paragraph that has its own style. It could be
@@ -40,6 +38,8 @@ type Run struct {
 
 	InstrText string `xml:"w:instrText,omitempty"`
 
+	DelInstrText string `xml:"w:delInstrText,omitempty"`
+
 	Text *Text `xml:"w:t,omitempty"`
 
 	FldChar *FldChar `xml:"w:fldChar,omitempty"`
@@ -47,6 +47,9 @@ type Run struct {
 	RsidR   string `xml:"w:rsidR,attr,omitempty"`
 	RsidRPr string `xml:"w:rsidRPr,attr,omitempty"`
 
+	// Attrs are the attributes not modelled above, only in preserve mode
+	Attrs []xml.Attr `xml:",any,attr"`
+
 	Children []interface{}
 
 	file *Docx
@@ -64,6 +67,9 @@ func (r *Run) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
 			r.RsidRPr = attr.Value
 		default:
 			// ignore other attributes
+			if ctx := contextOf(d); ctx.preserve() {
+				r.Attrs = append(r.Attrs, ctx.attr(attr))
+			}
 		}
 	}
 	for {
@@ -93,8 +99,8 @@ func (r *Run) parse(d *xml.Decoder, tt xml.StartElement) (child interface{}, err
 	switch tt.Name.Local {
 	case "rPr":
 		var value RunProperties
-		err = d.DecodeElement(&value, &tt)
-		if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+		value.preserved, err = decodePreserved(d, &tt, &value)
+		if err != nil && !ignorable(d, err) {
 			return nil, err
 		}
 		r.RunProperties = &value
@@ -102,7 +108,7 @@ func (r *Run) parse(d *xml.Decoder, tt xml.StartElement) (child interface{}, err
 	case "instrText":
 		var value string
 		err = d.DecodeElement(&value, &tt)
-		if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+		if err != nil && !ignorable(d, err) {
 			return nil, err
 		}
 		r.InstrText = value
@@ -110,26 +116,66 @@ func (r *Run) parse(d *xml.Decoder, tt xml.StartElement) (child interface{}, err
 	case "fldChar":
 		var value FldChar
 		err = d.DecodeElement(&value, &tt)
-		if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+		if err != nil && !ignorable(d, err) {
 			return nil, err
 		}
 		r.FldChar = &value
 		return nil, nil
+	case "delInstrText":
+		var value string
+		err = d.DecodeElement(&value, &tt)
+		if err != nil && !ignorable(d, err) {
+			return nil, err
+		}
+		r.DelInstrText = value
+		return nil, nil
+	case "delText":
+		var value DeletedText
+		err = d.DecodeElement(&value, &tt)
+		if err != nil && !ignorable(d, err) {
+			return nil, err
+		}
+		child = &value
 	case "t":
 		var value Text
 		err = d.DecodeElement(&value, &tt)
-		if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+		if err != nil && !ignorable(d, err) {
 			return nil, err
 		}
 		child = &value
 	case "drawing":
 		var value Drawing
 		value.file = r.file
-		err = d.DecodeElement(&value, &tt)
-		if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+		value.preserved, err = decodePreserved(d, &tt, &value)
+		if err != nil && !ignorable(d, err) {
 			return nil, err
 		}
 		child = &value
+	case "footnoteReference", "endnoteReference":
+		id, err := strconv.Atoi(getAtt(tt.Attr, "id"))
+		if err != nil {
+			return nil, err
+		}
+		if tt.Name.Local == "footnoteReference" {
+			return &FootnoteReference{ID: id}, nil
+		}
+		return &EndnoteReference{ID: id}, nil
+	case "commentReference":
+		id, err := strconv.Atoi(getAtt(tt.Attr, "id"))
+		if err != nil {
+			return nil, err
+		}
+		return &CommentReference{ID: id}, nil
+	case "annotationRef":
+		child = &AnnotationRef{}
+	case "footnoteRef":
+		child = &FootnoteRef{}
+	case "endnoteRef":
+		child = &EndnoteRef{}
+	case "separator":
+		child = &Separator{}
+	case "continuationSeparator":
+		child = &ContinuationSeparator{}
 	case "tab":
 		child = &Tab{}
 	case "br":
@@ -137,10 +183,13 @@ func (r *Run) parse(d *xml.Decoder, tt xml.StartElement) (child interface{}, err
 			Type: getAtt(tt.Attr, "type"),
 		}
 	case "AlternateContent":
+		if ctx := contextOf(d); ctx.preserve() {
+			return r.parsePreservedAlternateContent(ctx, d, tt)
+		}
 		/*var value AlternateContent
 		value.file = r.file
 		err = d.DecodeElement(&value, &tt)
-		if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+		if err != nil && !ignorable(d, err) {
 			return nil, err
 		}
 		if value.Choice == nil {
@@ -188,36 +237,65 @@ func (r *Run) parse(d *xml.Decoder, tt xml.StartElement) (child interface{}, err
 			}
 		}
 	default:
+		if contextOf(d).preserve() {
+			return decodeRaw(d, tt)
+		}
 		err = d.Skip() // skip unsupported tags
 	}
 	return
 }
 
+// parsePreservedAlternateContent parses mc:AlternateContent as usual, but keeps
+// the whole element to be written back if the chosen drawing is not modified
+func (r *Run) parsePreservedAlternateContent(ctx *decodeContext, d *xml.Decoder, tt xml.StartElement) (interface{}, error) {
+	toks, err := captureElement(d, tt)
+	if err != nil {
+		return nil, err
+	}
+	origin := ctx.raw(toks)
+	rd, release := ctx.replay(toks)
+	defer release()
+	_, err = rd.Token() // AlternateContent itself
+	if err != nil {
+		return nil, err
+	}
+	// the same as what parse does without preserve mode
+	nopreserve := *ctx
+	nopreserve.opt.Preserve = false
+	defer bindContext(rd, &nopreserve)()
+	child, err := r.parse(rd, xml.StartElement{Name: xml.Name{Local: "AlternateContent"}})
+	if err != nil && !ignorable(d, err) {
+		return nil, err
+	}
+	drawing, ok := child.(*Drawing)
+	if !ok {
+		return origin, nil
+	}
+	snapshot, err := xml.Marshal(drawing)
+	if err != nil {
+		return nil, err
+	}
+	drawing.preserved = &preserved{snapshot: snapshot, origin: origin}
+	return drawing, nil
+}
+
 // KeepElements keep named elems amd removes others
 //
 // names: *docx.Text *docx.Drawing *docx.Tab *docx.BarterRabbet
 func (r *Run) KeepElements(name ...string) {
-	items := make([]interface{}, 0, len(r.Children))
-	namemap := make(map[string]struct{}, len(name)*2)
-	for _, n := range name {
-		namemap[n] = struct{}{}
-	}
-	for _, item := range r.Children {
-		_, ok := namemap[reflect.ValueOf(item).Type().String()]
-		if ok {
-			items = append(items, item)
-		}
-	}
-	r.Children = items
+	keepElements(r, name)
 }
 
 // RunProperties encapsulates visual properties of a run
 type RunProperties struct {
 	XMLName   xml.Name `xml:"w:rPr,omitempty"`
+	Inserted  *InsertedMark
+	Deleted   *DeletedMark
 	Fonts     *RunFonts
 	Bold      *Bold
 	ICs       *struct{} `xml:"w:iCs,omitempty"`
 	Italic    *Italic
+	Caps      *Caps
 	Highlight *Highlight
 	Color     *Color
 	Size      *Size
@@ -233,6 +311,16 @@ type RunProperties struct {
 	NoProof   *NoProof
 	WebHidden *WebHidden
 	Lang      *Lang
+
+	Change *RunPropertiesChange
+
+	preserved *preserved
+}
+
+// MarshalXML writes the source back in preserve mode if it is not modified
+func (r *RunProperties) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type plain RunProperties
+	return marshalPreserved(e, start, (*plain)(r), r.preserved)
 }
 
 // UnmarshalXML ...
@@ -248,19 +336,42 @@ func (r *RunProperties) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) error {
 
 		if tt, ok := t.(xml.StartElement); ok {
 			switch tt.Name.Local {
+			case "ins":
+				var value InsertedMark
+				err = value.Revision.parse(tt.Attr)
+				if err != nil {
+					return err
+				}
+				r.Inserted = &value
+			case "del":
+				var value DeletedMark
+				err = value.Revision.parse(tt.Attr)
+				if err != nil {
+					return err
+				}
+				r.Deleted = &value
+			case "rPrChange":
+				var value RunPropertiesChange
+				err = d.DecodeElement(&value, &tt)
+				if err != nil && !ignorable(d, err) {
+					return err
+				}
+				r.Change = &value
 			case "rFonts":
 				var value RunFonts
 				err = d.DecodeElement(&value, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 				r.Fonts = &value
 			case "b":
-				r.Bold = &Bold{}
+				r.Bold = &Bold{Val: getAtt(tt.Attr, "val")}
 			case "iCs":
 				r.ICs = &struct{}{}
 			case "i":
-				r.Italic = &Italic{}
+				r.Italic = &Italic{Val: getAtt(tt.Attr, "val")}
+			case "caps":
+				r.Caps = &Caps{Val: getAtt(tt.Attr, "val")}
 			case "u":
 				var value Underline
 				value.Val = getAtt(tt.Attr, "val")
@@ -280,7 +391,7 @@ func (r *RunProperties) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) error {
 			case "spacing":
 				var value Spacing
 				err = d.DecodeElement(&value, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 				r.Spacing = &value
@@ -299,7 +410,7 @@ func (r *RunProperties) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) error {
 			case "shd":
 				var value Shade
 				err = d.DecodeElement(&value, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 				r.Shade = &value
@@ -346,7 +457,7 @@ func (r *RunProperties) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) error {
 			default:
 				err = d.Skip() // skip unsupported tags
 				if err != nil {
-					log.Println("RunProperties: ", err)
+					logln(d, "RunProperties: ", err)
 					return err
 				}
 				continue
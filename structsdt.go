@@ -23,7 +23,6 @@ package docx
 import (
 	"encoding/xml"
 	"io"
-	"log"
 )
 
 type StructuredDocumentTag struct {
@@ -32,6 +31,8 @@ type StructuredDocumentTag struct {
 	SdtPr      *StructuredDocumentTagProperties    `xml:"w:sdtPr,omitempty"`
 	SdtEndPr   *StructuredDocumentTagEndProperties `xml:"w:sdtEndPr,omitempty"`
 	SdtContent *StructuredDocumentTagContent       `xml:"w:sdtContent,omitempty"`
+
+	preserved *preserved
 }
 
 type StructuredDocumentTagProperties struct {
@@ -77,7 +78,7 @@ func (s *StructuredDocumentTagEndProperties) UnmarshalXML(d *xml.Decoder, _ xml.
 			break
 		}
 		if err != nil {
-			log.Println("error", err)
+			logln(d, "error", err)
 			return err
 		}
 
@@ -111,6 +112,12 @@ type StructuredDocumentTagContent struct {
 	Tables *[]*Table `xml:"w:tbl,omitempty"`
 }
 
+// MarshalXML writes the source back in preserve mode if it is not modified
+func (sdt *StructuredDocumentTag) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type plain StructuredDocumentTag
+	return marshalPreserved(e, start, (*plain)(sdt), sdt.preserved)
+}
+
 // UnmarshalXML unmarshals
 func (sdt *StructuredDocumentTag) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
 	for {
@@ -316,7 +323,7 @@ func (i *TagID) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
 			break
 		}
 		if err != nil {
-			log.Println("error", err)
+			logln(d, "error", err)
 			return err
 		}
 	}
@@ -338,7 +345,7 @@ func (dpg *DocumentPartGallery) UnmarshalXML(d *xml.Decoder, start xml.StartElem
 			break
 		}
 		if err != nil {
-			log.Println("error", err)
+			logln(d, "error", err)
 			return err
 		}
 	}
@@ -360,7 +367,7 @@ func (dpu *DocumentPartUnique) UnmarshalXML(d *xml.Decoder, start xml.StartEleme
 			break
 		}
 		if err != nil {
-			log.Println("error", err)
+			logln(d, "error", err)
 			return err
 		}
 	}
@@ -19,12 +19,22 @@ type SectPr struct {
 	RsidR           string             `xml:"w:rsidR,attr,omitempty"`
 	RsidRPr         string             `xml:"w:rsidRPr,attr,omitempty"`
 	RsidSect        string             `xml:"w:rsidSect,attr,omitempty"`
+	HeaderReference *[]HeaderReference `xml:"w:headerReference,omitempty"`
 	FooterReference *[]FooterReference `xml:"w:footerReference,omitempty"`
 	// FooterReference *FooterReference `xml:"w:footerReference,omitempty"`
 	PgSz    *PgSz    `xml:"w:pgSz,omitempty"`
 	PgMar   *PgMar   `xml:"w:pgMar,omitempty"`
 	Cols    *Cols    `xml:"w:cols,omitempty"`
+	TitlePg *TitlePg `xml:"w:titlePg,omitempty"`
 	DocGrid *DocGrid `xml:"w:docGrid,omitempty"`
+
+	preserved *preserved
+}
+
+type HeaderReference struct {
+	XMLName xml.Name `xml:"w:headerReference"`
+	Type    string   `xml:"w:type,attr,omitempty"`
+	Id      string   `xml:"r:id,attr,omitempty"`
 }
 
 type FooterReference struct {
@@ -33,6 +43,12 @@ type FooterReference struct {
 	Id      string   `xml:"r:id,attr,omitempty"`
 }
 
+// TitlePg enables the separate first page header and footer
+type TitlePg struct {
+	XMLName xml.Name `xml:"w:titlePg"`
+	Val     string   `xml:"w:val,attr,omitempty"`
+}
+
 type PgSz struct {
 	XMLName xml.Name `xml:"w:pgSz"`
 	W       string   `xml:"w:w,attr,omitempty"`
@@ -64,6 +80,12 @@ type DocGrid struct {
 
 // Unmarshals ...
 
+// MarshalXML writes the source back in preserve mode if it is not modified
+func (s *SectPr) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type plain SectPr
+	return marshalPreserved(e, start, (*plain)(s), s.preserved)
+}
+
 func (s *SectPr) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
 	for _, attr := range start.Attr {
 		switch attr.Name.Local {
@@ -83,6 +105,16 @@ func (s *SectPr) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
 		switch se := t.(type) {
 		case xml.StartElement:
 			switch se.Name.Local {
+			// headerReference allows for multiple entries
+			case "headerReference":
+				var v HeaderReference
+				if err := d.DecodeElement(&v, &se); err != nil {
+					return err
+				}
+				if s.HeaderReference == nil {
+					s.HeaderReference = &[]HeaderReference{}
+				}
+				*s.HeaderReference = append(*s.HeaderReference, v)
 			// footerReference allows for multiple entries
 			case "footerReference":
 				var v FooterReference
@@ -115,6 +147,11 @@ func (s *SectPr) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
 					return err
 				}
 				s.Cols = &v
+			case "titlePg":
+				s.TitlePg = &TitlePg{Val: getAtt(se.Attr, "val")}
+				if err := d.Skip(); err != nil {
+					return err
+				}
 			case "docGrid":
 				var v DocGrid
 				if err := d.DecodeElement(&v, &se); err != nil {
@@ -126,6 +163,29 @@ func (s *SectPr) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
 	}
 }
 
+func (h *HeaderReference) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	for _, attr := range start.Attr {
+		switch attr.Name.Local {
+		case "type":
+			h.Type = attr.Value
+		case "id":
+			h.Id = attr.Value
+		}
+	}
+
+	for {
+		_, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (f *FooterReference) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
 	for _, attr := range start.Attr {
 		switch attr.Name.Local {
@@ -0,0 +1,654 @@
+/*
+   Copyright (c) 2020 gingfrederik
+   Copyright (c) 2021 Gonzalo Fernandez-Victorio
+   Copyright (c) 2021 Basement Crowd Ltd (https://www.basementcrowd.com)
+   Copyright (c) 2023 Fumiama Minamoto (源文雨)
+
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU Affero General Public License as published
+   by the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU Affero General Public License for more details.
+
+   You should have received a copy of the GNU Affero General Public License
+   along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package docx
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+)
+
+//nolint:revive,stylecheck
+const (
+	// STYLE_PARAGRAPH is the type of a paragraph style
+	STYLE_PARAGRAPH = "paragraph"
+	// STYLE_CHARACTER is the type of a character (run) style
+	STYLE_CHARACTER = "character"
+	// STYLE_TABLE is the type of a table style
+	STYLE_TABLE = "table"
+	// STYLE_NUMBERING is the type of a numbering style
+	STYLE_NUMBERING = "numbering"
+)
+
+//nolint:revive,stylecheck
+const (
+	// TBLSTYLE_WHOLE_TABLE is the formatting of the whole table
+	TBLSTYLE_WHOLE_TABLE = "wholeTable"
+	// TBLSTYLE_FIRST_ROW is the formatting of the header row
+	TBLSTYLE_FIRST_ROW = "firstRow"
+	// TBLSTYLE_LAST_ROW is the formatting of the last row
+	TBLSTYLE_LAST_ROW = "lastRow"
+	// TBLSTYLE_FIRST_COL is the formatting of the first column
+	TBLSTYLE_FIRST_COL = "firstCol"
+	// TBLSTYLE_LAST_COL is the formatting of the last column
+	TBLSTYLE_LAST_COL = "lastCol"
+	// TBLSTYLE_BAND1_HORZ is the formatting of the odd banded rows
+	TBLSTYLE_BAND1_HORZ = "band1Horz"
+	// TBLSTYLE_BAND2_HORZ is the formatting of the even banded rows
+	TBLSTYLE_BAND2_HORZ = "band2Horz"
+	// TBLSTYLE_BAND1_VERT is the formatting of the odd banded columns
+	TBLSTYLE_BAND1_VERT = "band1Vert"
+	// TBLSTYLE_BAND2_VERT is the formatting of the even banded columns
+	TBLSTYLE_BAND2_VERT = "band2Vert"
+	// TBLSTYLE_NE_CELL is the formatting of the top right cell
+	TBLSTYLE_NE_CELL = "neCell"
+	// TBLSTYLE_NW_CELL is the formatting of the top left cell
+	TBLSTYLE_NW_CELL = "nwCell"
+	// TBLSTYLE_SE_CELL is the formatting of the bottom right cell
+	TBLSTYLE_SE_CELL = "seCell"
+	// TBLSTYLE_SW_CELL is the formatting of the bottom left cell
+	TBLSTYLE_SW_CELL = "swCell"
+)
+
+// Styles <w:styles> is word/styles.xml
+type Styles struct {
+	XMLName     xml.Name `xml:"w:styles"`
+	XMLW        string   `xml:"xmlns:w,attr"`                // cannot be unmarshalled in
+	XMLR        string   `xml:"xmlns:r,attr,omitempty"`      // cannot be unmarshalled in
+	XMLMC       string   `xml:"xmlns:mc,attr,omitempty"`     // cannot be unmarshalled in
+	XMLW14      string   `xml:"xmlns:w14,attr,omitempty"`    // cannot be unmarshalled in
+	XMLW15      string   `xml:"xmlns:w15,attr,omitempty"`    // cannot be unmarshalled in
+	MCIgnorable string   `xml:"mc:Ignorable,attr,omitempty"` // cannot be unmarshalled in
+
+	// Attrs are the extra namespaces declared in source
+	Attrs []xml.Attr `xml:",any,attr"`
+
+	DocDefaults  *DocDefaults
+	LatentStyles *RawXML `xml:"w:latentStyles,omitempty"`
+	Styles       []*StyleDefinition
+
+	// raws are the unknown children
+	raws []rawSlot
+
+	file *Docx
+	// name is the path in zip like word/styles.xml
+	name string
+}
+
+// DocDefaults <w:docDefaults> are the properties every style is based on
+type DocDefaults struct {
+	XMLName    xml.Name    `xml:"w:docDefaults"`
+	RPrDefault *RPrDefault `xml:"w:rPrDefault,omitempty"`
+	PPrDefault *PPrDefault `xml:"w:pPrDefault,omitempty"`
+}
+
+// RPrDefault <w:rPrDefault>
+type RPrDefault struct {
+	XMLName       xml.Name `xml:"w:rPrDefault"`
+	RunProperties *RunProperties
+}
+
+// PPrDefault <w:pPrDefault>
+type PPrDefault struct {
+	XMLName             xml.Name `xml:"w:pPrDefault"`
+	ParagraphProperties *ParagraphProperties
+}
+
+// StyleDefinition <w:style> defines the style that
+// Style, RunStyle and WTableStyle refer to by StyleID
+type StyleDefinition struct {
+	XMLName     xml.Name `xml:"w:style"`
+	Type        string   `xml:"w:type,attr,omitempty"`
+	Default     string   `xml:"w:default,attr,omitempty"`
+	CustomStyle string   `xml:"w:customStyle,attr,omitempty"`
+	StyleID     string   `xml:"w:styleId,attr,omitempty"`
+
+	// Attrs are the attributes not modelled above
+	Attrs []xml.Attr `xml:",any,attr"`
+
+	Name           *StyleName
+	BasedOn        *BasedOn
+	Next           *NextStyle
+	Link           *LinkedStyle
+	UIPriority     *UIPriority
+	SemiHidden     *SemiHidden
+	UnhideWhenUsed *UnhideWhenUsed
+	QFormat        *QFormat
+
+	ParagraphProperties  *ParagraphProperties
+	RunProperties        *RunProperties
+	TableProperties      *WTableProperties
+	TableRowProperties   *WTableRowProperties
+	TableCellProperties  *WTableCellProperties
+	TableStyleProperties []*TableStyleProperties
+
+	// raws are the unknown children like w:rsid
+	raws []rawSlot
+}
+
+// StyleName <w:name> is the name shown in the UI
+type StyleName struct {
+	XMLName xml.Name `xml:"w:name"`
+	Val     string   `xml:"w:val,attr"`
+}
+
+// BasedOn <w:basedOn> is the StyleID of the parent style
+type BasedOn struct {
+	XMLName xml.Name `xml:"w:basedOn"`
+	Val     string   `xml:"w:val,attr"`
+}
+
+// NextStyle <w:next> is the StyleID of the paragraph after this one
+type NextStyle struct {
+	XMLName xml.Name `xml:"w:next"`
+	Val     string   `xml:"w:val,attr"`
+}
+
+// LinkedStyle <w:link> is the StyleID of the paired paragraph/character style
+type LinkedStyle struct {
+	XMLName xml.Name `xml:"w:link"`
+	Val     string   `xml:"w:val,attr"`
+}
+
+// UIPriority <w:uiPriority> sorts the styles in the UI
+type UIPriority struct {
+	XMLName xml.Name `xml:"w:uiPriority"`
+	Val     int      `xml:"w:val,attr"`
+}
+
+// SemiHidden <w:semiHidden> hides the style from the main UI
+type SemiHidden struct {
+	XMLName xml.Name `xml:"w:semiHidden"`
+	Val     string   `xml:"w:val,attr,omitempty"`
+}
+
+// UnhideWhenUsed <w:unhideWhenUsed> shows the semi hidden style once used
+type UnhideWhenUsed struct {
+	XMLName xml.Name `xml:"w:unhideWhenUsed"`
+	Val     string   `xml:"w:val,attr,omitempty"`
+}
+
+// QFormat <w:qFormat> shows the style in the style gallery
+type QFormat struct {
+	XMLName xml.Name `xml:"w:qFormat"`
+	Val     string   `xml:"w:val,attr,omitempty"`
+}
+
+// TableStyleProperties <w:tblStylePr> is the conditional formatting
+// of a table style, applied to the part named by Type like firstRow
+type TableStyleProperties struct {
+	XMLName             xml.Name `xml:"w:tblStylePr"`
+	Type                string   `xml:"w:type,attr"`
+	ParagraphProperties *ParagraphProperties
+	RunProperties       *RunProperties
+	TableProperties     *WTableProperties
+	TableRowProperties  *WTableRowProperties
+	TableCellProperties *WTableCellProperties
+}
+
+// isOn tells whether an ST_OnOff value is on, empty means on
+func isOn(val string) bool {
+	switch val {
+	case "0", "false", "off":
+		return false
+	}
+	return true
+}
+
+func (s *Styles) setNamespaces() {
+	s.XMLW = XMLNS_W
+	s.XMLR = XMLNS_R
+	s.XMLMC = XMLNS_MC
+	s.XMLW14 = XMLNS_W14
+	s.XMLW15 = XMLNS_W15
+	s.MCIgnorable = "w14 w15"
+}
+
+// MarshalXML writes the unknown children back at their position
+func (s *Styles) MarshalXML(e *xml.Encoder, _ xml.StartElement) error {
+	type plain Styles
+	if len(s.raws) == 0 {
+		return e.Encode((*plain)(s))
+	}
+	start := xml.StartElement{Name: xml.Name{Local: "w:styles"}}
+	for _, a := range []struct{ name, val string }{
+		{"xmlns:w", s.XMLW}, {"xmlns:r", s.XMLR}, {"xmlns:mc", s.XMLMC},
+		{"xmlns:w14", s.XMLW14}, {"xmlns:w15", s.XMLW15}, {"mc:Ignorable", s.MCIgnorable},
+	} {
+		if a.val != "" {
+			start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: a.name}, Value: a.val})
+		}
+	}
+	start.Attr = append(start.Attr, s.Attrs...)
+	children := make([]interface{}, 0, 2+len(s.Styles))
+	if s.DocDefaults != nil {
+		children = append(children, s.DocDefaults)
+	}
+	if s.LatentStyles != nil {
+		children = append(children, s.LatentStyles)
+	}
+	for _, st := range s.Styles {
+		children = append(children, st)
+	}
+	return encodeWithSlots(e, start, children, s.raws)
+}
+
+// UnmarshalXML ...
+func (s *Styles) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	ctx := contextOf(d)
+	s.Attrs = ctx.rootAttrs(start.Attr, XMLNS_W, XMLNS_R, XMLNS_MC, XMLNS_W14, XMLNS_W15)
+	for _, a := range start.Attr {
+		if a.Name.Space == XMLNS_MC && a.Name.Local == "Ignorable" {
+			s.MCIgnorable = a.Value
+		}
+	}
+	n := 0
+	for {
+		t, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		tt, ok := t.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch tt.Name.Local {
+		case "docDefaults":
+			var value DocDefaults
+			err = d.DecodeElement(&value, &tt)
+			if err != nil && !ignorable(d, err) {
+				return err
+			}
+			s.DocDefaults = &value
+		case "latentStyles":
+			s.LatentStyles, err = decodeRaw(d, tt)
+			if err != nil {
+				return err
+			}
+		case "style":
+			var value StyleDefinition
+			err = d.DecodeElement(&value, &tt)
+			if err != nil && !ignorable(d, err) {
+				return err
+			}
+			s.Styles = append(s.Styles, &value)
+		default:
+			value, err := decodeRaw(d, tt)
+			if err != nil {
+				return err
+			}
+			s.raws = append(s.raws, rawSlot{idx: n, node: value})
+			continue
+		}
+		n++
+	}
+	return nil
+}
+
+// UnmarshalXML ...
+func (dd *DocDefaults) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) error {
+	for {
+		t, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		tt, ok := t.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch tt.Name.Local {
+		case "rPrDefault":
+			dd.RPrDefault = &RPrDefault{}
+			err = d.DecodeElement(dd.RPrDefault, &tt)
+		case "pPrDefault":
+			dd.PPrDefault = &PPrDefault{}
+			err = d.DecodeElement(dd.PPrDefault, &tt)
+		default:
+			logln(d, "UnmarshalXML DocDefaults unsupported, skip:", tt.Name.Local)
+			err = d.Skip()
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UnmarshalXML ...
+func (r *RPrDefault) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) error {
+	for {
+		t, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		tt, ok := t.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if tt.Name.Local != "rPr" {
+			err = d.Skip()
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		var value RunProperties
+		value.preserved, err = decodePreserved(d, &tt, &value)
+		if err != nil && !ignorable(d, err) {
+			return err
+		}
+		r.RunProperties = &value
+	}
+	return nil
+}
+
+// UnmarshalXML ...
+func (p *PPrDefault) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) error {
+	for {
+		t, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		tt, ok := t.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if tt.Name.Local != "pPr" {
+			err = d.Skip()
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		var value ParagraphProperties
+		value.preserved, err = decodePreserved(d, &tt, &value)
+		if err != nil && !ignorable(d, err) {
+			return err
+		}
+		p.ParagraphProperties = &value
+	}
+	return nil
+}
+
+// children are the typed children in schema order
+func (s *StyleDefinition) children() []interface{} {
+	children := make([]interface{}, 0, 16)
+	if s.Name != nil {
+		children = append(children, s.Name)
+	}
+	if s.BasedOn != nil {
+		children = append(children, s.BasedOn)
+	}
+	if s.Next != nil {
+		children = append(children, s.Next)
+	}
+	if s.Link != nil {
+		children = append(children, s.Link)
+	}
+	if s.UIPriority != nil {
+		children = append(children, s.UIPriority)
+	}
+	if s.SemiHidden != nil {
+		children = append(children, s.SemiHidden)
+	}
+	if s.UnhideWhenUsed != nil {
+		children = append(children, s.UnhideWhenUsed)
+	}
+	if s.QFormat != nil {
+		children = append(children, s.QFormat)
+	}
+	if s.ParagraphProperties != nil {
+		children = append(children, s.ParagraphProperties)
+	}
+	if s.RunProperties != nil {
+		children = append(children, s.RunProperties)
+	}
+	if s.TableProperties != nil {
+		children = append(children, s.TableProperties)
+	}
+	if s.TableRowProperties != nil {
+		children = append(children, s.TableRowProperties)
+	}
+	if s.TableCellProperties != nil {
+		children = append(children, s.TableCellProperties)
+	}
+	for _, c := range s.TableStyleProperties {
+		children = append(children, c)
+	}
+	return children
+}
+
+// MarshalXML writes the unknown children back at their position
+func (s *StyleDefinition) MarshalXML(e *xml.Encoder, _ xml.StartElement) error {
+	type plain StyleDefinition
+	if len(s.raws) == 0 {
+		return e.Encode((*plain)(s))
+	}
+	start := xml.StartElement{Name: xml.Name{Local: "w:style"}}
+	for _, a := range []struct{ name, val string }{
+		{"w:type", s.Type}, {"w:default", s.Default}, {"w:customStyle", s.CustomStyle}, {"w:styleId", s.StyleID},
+	} {
+		if a.val != "" {
+			start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: a.name}, Value: a.val})
+		}
+	}
+	start.Attr = append(start.Attr, s.Attrs...)
+	return encodeWithSlots(e, start, s.children(), s.raws)
+}
+
+// UnmarshalXML ...
+func (s *StyleDefinition) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	ctx := contextOf(d)
+	for _, a := range start.Attr {
+		switch {
+		case a.Name.Space == XMLNS_W && a.Name.Local == "type":
+			s.Type = a.Value
+		case a.Name.Space == XMLNS_W && a.Name.Local == "default":
+			s.Default = a.Value
+		case a.Name.Space == XMLNS_W && a.Name.Local == "customStyle":
+			s.CustomStyle = a.Value
+		case a.Name.Space == XMLNS_W && a.Name.Local == "styleId":
+			s.StyleID = a.Value
+		default:
+			s.Attrs = append(s.Attrs, ctx.attr(a))
+		}
+	}
+	n := 0
+	for {
+		t, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		tt, ok := t.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		val := getAtt(tt.Attr, "val")
+		switch tt.Name.Local {
+		case "name":
+			s.Name = &StyleName{Val: val}
+		case "basedOn":
+			s.BasedOn = &BasedOn{Val: val}
+		case "next":
+			s.Next = &NextStyle{Val: val}
+		case "link":
+			s.Link = &LinkedStyle{Val: val}
+		case "uiPriority":
+			var v int
+			v, err = strconv.Atoi(val)
+			if err != nil {
+				return err
+			}
+			s.UIPriority = &UIPriority{Val: v}
+		case "semiHidden":
+			s.SemiHidden = &SemiHidden{Val: val}
+		case "unhideWhenUsed":
+			s.UnhideWhenUsed = &UnhideWhenUsed{Val: val}
+		case "qFormat":
+			s.QFormat = &QFormat{Val: val}
+		case "pPr":
+			var value ParagraphProperties
+			value.preserved, err = decodePreserved(d, &tt, &value)
+			if err != nil && !ignorable(d, err) {
+				return err
+			}
+			s.ParagraphProperties = &value
+			n++
+			continue
+		case "rPr":
+			var value RunProperties
+			value.preserved, err = decodePreserved(d, &tt, &value)
+			if err != nil && !ignorable(d, err) {
+				return err
+			}
+			s.RunProperties = &value
+			n++
+			continue
+		case "tblPr":
+			var value WTableProperties
+			value.preserved, err = decodePreserved(d, &tt, &value)
+			if err != nil && !ignorable(d, err) {
+				return err
+			}
+			s.TableProperties = &value
+			n++
+			continue
+		case "trPr":
+			var value WTableRowProperties
+			value.preserved, err = decodePreserved(d, &tt, &value)
+			if err != nil && !ignorable(d, err) {
+				return err
+			}
+			s.TableRowProperties = &value
+			n++
+			continue
+		case "tcPr":
+			var value WTableCellProperties
+			value.preserved, err = decodePreserved(d, &tt, &value)
+			if err != nil && !ignorable(d, err) {
+				return err
+			}
+			s.TableCellProperties = &value
+			n++
+			continue
+		case "tblStylePr":
+			var value TableStyleProperties
+			err = d.DecodeElement(&value, &tt)
+			if err != nil && !ignorable(d, err) {
+				return err
+			}
+			s.TableStyleProperties = append(s.TableStyleProperties, &value)
+			n++
+			continue
+		default:
+			value, err := decodeRaw(d, tt)
+			if err != nil {
+				return err
+			}
+			s.raws = append(s.raws, rawSlot{idx: n, node: value})
+			continue
+		}
+		// simple children modelled by their val
+		err = d.Skip()
+		if err != nil {
+			return err
+		}
+		n++
+	}
+	return nil
+}
+
+// UnmarshalXML ...
+func (p *TableStyleProperties) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	p.Type = getAtt(start.Attr, "type")
+	for {
+		t, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		tt, ok := t.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch tt.Name.Local {
+		case "pPr":
+			var value ParagraphProperties
+			value.preserved, err = decodePreserved(d, &tt, &value)
+			p.ParagraphProperties = &value
+		case "rPr":
+			var value RunProperties
+			value.preserved, err = decodePreserved(d, &tt, &value)
+			p.RunProperties = &value
+		case "tblPr":
+			var value WTableProperties
+			value.preserved, err = decodePreserved(d, &tt, &value)
+			p.TableProperties = &value
+		case "trPr":
+			var value WTableRowProperties
+			value.preserved, err = decodePreserved(d, &tt, &value)
+			p.TableRowProperties = &value
+		case "tcPr":
+			var value WTableCellProperties
+			value.preserved, err = decodePreserved(d, &tt, &value)
+			p.TableCellProperties = &value
+		default:
+			logln(d, "UnmarshalXML TableStyleProperties unsupported, skip:", tt.Name.Local)
+			err = d.Skip()
+		}
+		if err != nil && !ignorable(d, err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeStyles reads word/styles.xml with all unknown elements kept
+func decodeStyles(r io.Reader, opt *ParseOptions) (*Styles, error) {
+	s := &Styles{}
+	s.setNamespaces()
+	d := xml.NewDecoder(r)
+	o := *opt
+	o.Preserve = true
+	defer bindContext(d, &decodeContext{opt: o})()
+	err := d.Decode(s)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
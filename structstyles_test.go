@@ -0,0 +1,90 @@
+/*
+   Copyright (c) 2020 gingfrederik
+   Copyright (c) 2021 Gonzalo Fernandez-Victorio
+   Copyright (c) 2021 Basement Crowd Ltd (https://www.basementcrowd.com)
+   Copyright (c) 2023 Fumiama Minamoto (源文雨)
+
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU Affero General Public License as published
+   by the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU Affero General Public License for more details.
+
+   You should have received a copy of the GNU Affero General Public License
+   along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package docx
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func TestStyles(t *testing.T) {
+	w := NewA4()
+	s := w.Styles()
+	def := s.Default(STYLE_PARAGRAPH)
+	if def == nil || def.StyleID != "a" {
+		t.Fatal("no default paragraph style")
+	}
+	if s.LatentStyles == nil || s.DocDefaults.RPrDefault.RunProperties.Size.Val != "21" {
+		t.Fatal("template styles not loaded")
+	}
+
+	// untouched styles are written back as in template
+	tmpl, err := TemplateXMLFS.ReadFile("xml/a4/word/styles.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := xml.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(canonicalXML(t, tmpl, "styles"), "\n") != strings.Join(canonicalXML(t, out, "styles"), "\n") {
+		t.Fatal("template styles changed:\n", string(out))
+	}
+
+	w.AddParagraphStyle("HouseTitle", "House Title").Bold().Size("36").Justification("center").SetNext(def.StyleID)
+	w.AddCharacterStyle("HouseEm", "House Emphasis").Italic().Color("C00000")
+	if w.AddParagraphStyle("HouseTitle", "Again") != w.Style("HouseTitle") {
+		t.Fatal("style added twice")
+	}
+	p := w.AddParagraph().Style("HouseTitle")
+	p.AddText("Contract")
+	p.AddText(" No. 1").Style("HouseEm")
+
+	buf := bytes.NewBuffer(make([]byte, 0, 1024*1024))
+	_, err = w.WriteTo(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := Parse(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	title := doc.Style("HouseTitle")
+	if title == nil || title.Type != STYLE_PARAGRAPH || title.Name.Val != "House Title" ||
+		title.BasedOn.Val != "a" || title.Next.Val != "a" ||
+		title.RunProperties.Bold == nil || title.RunProperties.Size.Val != "36" ||
+		title.ParagraphProperties.Justification.Val != "center" {
+		t.Fatal("unexpected style", title)
+	}
+	em := doc.Style("HouseEm")
+	if em == nil || em.Type != STYLE_CHARACTER || em.BasedOn.Val != "a0" || em.RunProperties.Color.Val != "C00000" {
+		t.Fatal("unexpected style", em)
+	}
+	para := doc.Document.Body.Items[0].(*Paragraph)
+	if para.Properties.Style.Val != "HouseTitle" {
+		t.Fatal("unexpected paragraph style")
+	}
+	if len(doc.tmpfslst) != len(A4TemplateFilesList)-1 {
+		t.Fatal("styles.xml is still a template file")
+	}
+}
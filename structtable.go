@@ -34,32 +34,85 @@ type Table struct {
 	TableRows       []*WTableRow
 
 	file *Docx
+	// raws are the unknown elements kept in preserve mode
+	raws []rawSlot
 }
 
 func (t *Table) String() string {
-	if len(t.TableRows) == 0 || len(t.TableRows[0].TableCells) == 0 {
+	cols := t.columns()
+	if len(t.TableRows) == 0 || cols == 0 {
 		return ""
 	}
 	sb := strings.Builder{}
 	sb.WriteString("| ")
-	for i := 0; i < len(t.TableRows[0].TableCells); i++ {
+	for i := 0; i < cols; i++ {
 		sb.WriteString(" :----: |")
 	}
 	for _, r := range t.TableRows {
 		sb.WriteString("\n|")
+		n := 0
 		for _, c := range r.TableCells {
-			if len(c.Paragraphs) > 0 && len(c.Paragraphs[0].Children) > 0 {
+			// a merged cell is written in its first grid column and row only
+			if p := c.firstParagraph(); vMergeOf(c) != "continue" && p != nil && len(p.Children) > 0 {
 				sb.WriteByte(' ')
-				sb.WriteString(c.Paragraphs[0].String())
+				sb.WriteString(p.String())
 			} else {
 				sb.WriteString("       ")
 			}
 			sb.WriteString(" |")
+			span := gridSpanOf(c)
+			for i := 1; i < span; i++ {
+				sb.WriteString("        |")
+			}
+			n += span
+		}
+		for ; n < cols; n++ {
+			sb.WriteString("        |")
 		}
 	}
 	return sb.String()
 }
 
+// MarshalXML puts the unknown elements kept in preserve mode back between rows
+func (t *Table) MarshalXML(e *xml.Encoder, _ xml.StartElement) error {
+	type plain Table
+	if len(t.raws) == 0 {
+		return e.Encode((*plain)(t))
+	}
+	start := xml.StartElement{Name: xml.Name{Local: "w:tbl"}}
+	err := e.EncodeToken(start)
+	if err != nil {
+		return err
+	}
+	if t.TableProperties != nil {
+		err = e.Encode(t.TableProperties)
+		if err != nil {
+			return err
+		}
+	}
+	if t.TableGrid != nil {
+		err = e.Encode(t.TableGrid)
+		if err != nil {
+			return err
+		}
+	}
+	for i, row := range t.TableRows {
+		err = encodeSlots(e, t.raws, i, false)
+		if err != nil {
+			return err
+		}
+		err = e.Encode(row)
+		if err != nil {
+			return err
+		}
+	}
+	err = encodeSlots(e, t.raws, len(t.TableRows), true)
+	if err != nil {
+		return err
+	}
+	return e.EncodeToken(start.End())
+}
+
 // UnmarshalXML implements the xml.Unmarshaler interface.
 func (t *Table) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) error {
 	for {
@@ -76,23 +129,31 @@ func (t *Table) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) error {
 				var value WTableRow
 				value.file = t.file
 				err = d.DecodeElement(&value, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 				t.TableRows = append(t.TableRows, &value)
 			case "tblPr":
 				t.TableProperties = new(WTableProperties)
-				err = d.DecodeElement(t.TableProperties, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				t.TableProperties.preserved, err = decodePreserved(d, &tt, t.TableProperties)
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 			case "tblGrid":
 				t.TableGrid = new(WTableGrid)
 				err = d.DecodeElement(t.TableGrid, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 			default:
+				if contextOf(d).preserve() {
+					node, err := decodeRaw(d, tt)
+					if err != nil {
+						return err
+					}
+					t.raws = append(t.raws, rawSlot{idx: len(t.TableRows), node: node})
+					continue
+				}
 				err = d.Skip() // skip unsupported tags
 				if err != nil {
 					return err
@@ -113,6 +174,14 @@ type WTableProperties struct {
 	Justification *Justification `xml:"w:jc,omitempty"`
 	TableBorders  *WTableBorders `xml:"w:tblBorders"`
 	Look          *WTableLook
+
+	preserved *preserved
+}
+
+// MarshalXML writes the source back in preserve mode if it is not modified
+func (t *WTableProperties) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type plain WTableProperties
+	return marshalPreserved(e, start, (*plain)(t), t.preserved)
 }
 
 // UnmarshalXML implements the xml.Unmarshaler interface.
@@ -130,19 +199,19 @@ func (t *WTableProperties) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) erro
 			case "tblpPr":
 				t.Position = new(WTablePositioningProperties)
 				err = d.DecodeElement(t.Position, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 			case "tblStyle":
 				t.Style = new(WTableStyle)
 				err = d.DecodeElement(t.Style, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 			case "tblW":
 				t.Width = new(WTableWidth)
 				err = d.DecodeElement(t.Width, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 			case "jc":
@@ -161,13 +230,13 @@ func (t *WTableProperties) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) erro
 			case "tblLook":
 				t.Look = new(WTableLook)
 				err = d.DecodeElement(t.Look, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 			case "tblBorders":
 				t.TableBorders = new(WTableBorders)
 				err = d.DecodeElement(t.TableBorders, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 			default:
@@ -291,6 +360,22 @@ func (t *WTableWidth) UnmarshalXML(d *xml.Decoder, start xml.StartElement) (err
 	return err
 }
 
+//nolint:revive,stylecheck
+const (
+	// TBLLOOK_FIRST_ROW applies the firstRow formatting of the table style
+	TBLLOOK_FIRST_ROW = 0x0020
+	// TBLLOOK_LAST_ROW applies the lastRow formatting of the table style
+	TBLLOOK_LAST_ROW = 0x0040
+	// TBLLOOK_FIRST_COL applies the firstCol formatting of the table style
+	TBLLOOK_FIRST_COL = 0x0080
+	// TBLLOOK_LAST_COL applies the lastCol formatting of the table style
+	TBLLOOK_LAST_COL = 0x0100
+	// TBLLOOK_NO_HBAND turns off the banded rows of the table style
+	TBLLOOK_NO_HBAND = 0x0200
+	// TBLLOOK_NO_VBAND turns off the banded columns of the table style
+	TBLLOOK_NO_VBAND = 0x0400
+)
+
 // WTableLook represents the look of a table in a Word document.
 type WTableLook struct {
 	XMLName  xml.Name `xml:"w:tblLook,omitempty"`
@@ -355,7 +440,7 @@ func (t *WTableGrid) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) error {
 			case "gridCol":
 				var gc WGridCol
 				err := d.DecodeElement(&gc, &el)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 				t.GridCols = append(t.GridCols, &gc)
@@ -407,11 +492,55 @@ type WTableRow struct {
 	TableRowProperties *WTableRowProperties
 	TableCells         []*WTableCell
 
+	// Attrs are the attributes kept in preserve mode
+	Attrs []xml.Attr `xml:",any,attr"`
+
 	file *Docx
+	// raws are the unknown elements kept in preserve mode
+	raws []rawSlot
+}
+
+// MarshalXML puts the unknown elements kept in preserve mode back between cells
+func (w *WTableRow) MarshalXML(e *xml.Encoder, _ xml.StartElement) error {
+	type plain WTableRow
+	if len(w.raws) == 0 {
+		return e.Encode((*plain)(w))
+	}
+	start := xml.StartElement{Name: xml.Name{Local: "w:tr"}, Attr: w.Attrs}
+	err := e.EncodeToken(start)
+	if err != nil {
+		return err
+	}
+	if w.TableRowProperties != nil {
+		err = e.Encode(w.TableRowProperties)
+		if err != nil {
+			return err
+		}
+	}
+	for i, c := range w.TableCells {
+		err = encodeSlots(e, w.raws, i, false)
+		if err != nil {
+			return err
+		}
+		err = e.Encode(c)
+		if err != nil {
+			return err
+		}
+	}
+	err = encodeSlots(e, w.raws, len(w.TableCells), true)
+	if err != nil {
+		return err
+	}
+	return e.EncodeToken(start.End())
 }
 
 // UnmarshalXML ...
-func (w *WTableRow) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) error {
+func (w *WTableRow) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	if ctx := contextOf(d); ctx.preserve() {
+		for _, attr := range start.Attr {
+			w.Attrs = append(w.Attrs, ctx.attr(attr))
+		}
+	}
 	/*for _, attr := range start.Attr {
 		switch attr.Name.Local {
 		case "rsidR":
@@ -438,19 +567,27 @@ func (w *WTableRow) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) error {
 			switch tt.Name.Local {
 			case "trPr":
 				w.TableRowProperties = new(WTableRowProperties)
-				err = d.DecodeElement(w.TableRowProperties, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				w.TableRowProperties.preserved, err = decodePreserved(d, &tt, w.TableRowProperties)
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 			case "tc":
 				var value WTableCell
 				value.file = w.file
 				err = d.DecodeElement(&value, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 				w.TableCells = append(w.TableCells, &value)
 			default:
+				if contextOf(d).preserve() {
+					node, err := decodeRaw(d, tt)
+					if err != nil {
+						return err
+					}
+					w.raws = append(w.raws, rawSlot{idx: len(w.TableCells), node: node})
+					continue
+				}
 				err = d.Skip() // skip unsupported tags
 				if err != nil {
 					return err
@@ -464,9 +601,20 @@ func (w *WTableRow) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) error {
 
 // WTableRowProperties represents the properties of a row within a table.
 type WTableRowProperties struct {
-	XMLName        xml.Name `xml:"w:trPr,omitempty"`
-	TableRowHeight *WTableRowHeight
-	Justification  *Justification
+	XMLName          xml.Name `xml:"w:trPr,omitempty"`
+	CantSplit        *WCantSplit
+	TableRowHeight   *WTableRowHeight
+	TableHeader      *WTableHeader
+	TableCellSpacing *WTableCellSpacing
+	Justification    *Justification
+
+	preserved *preserved
+}
+
+// MarshalXML writes the source back in preserve mode if it is not modified
+func (t *WTableRowProperties) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type plain WTableRowProperties
+	return marshalPreserved(e, start, (*plain)(t), t.preserved)
 }
 
 // UnmarshalXML ...
@@ -513,6 +661,48 @@ func (t *WTableRowProperties) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) e
 				if err != nil {
 					return err
 				}
+			case "cantSplit":
+				t.CantSplit = new(WCantSplit)
+				for _, attr := range tt.Attr {
+					if attr.Name.Local == "val" {
+						t.CantSplit.Val = attr.Value
+						break
+					}
+				}
+				err = d.Skip()
+				if err != nil {
+					return err
+				}
+			case "tblHeader":
+				t.TableHeader = new(WTableHeader)
+				for _, attr := range tt.Attr {
+					if attr.Name.Local == "val" {
+						t.TableHeader.Val = attr.Value
+						break
+					}
+				}
+				err = d.Skip()
+				if err != nil {
+					return err
+				}
+			case "tblCellSpacing":
+				cs := new(WTableCellSpacing)
+				for _, attr := range tt.Attr {
+					switch attr.Name.Local {
+					case "w":
+						cs.W, err = GetInt64(attr.Value)
+						if err != nil {
+							return err
+						}
+					case "type":
+						cs.Type = attr.Value
+					}
+				}
+				t.TableCellSpacing = cs
+				err = d.Skip()
+				if err != nil {
+					return err
+				}
 			default:
 				err = d.Skip()
 				if err != nil {
@@ -531,15 +721,52 @@ type WTableRowHeight struct {
 	Val     int64    `xml:"w:val,attr"`
 }
 
+// WCantSplit keeps a row from breaking across pages
+type WCantSplit struct {
+	XMLName xml.Name `xml:"w:cantSplit,omitempty"`
+	Val     string   `xml:"w:val,attr,omitempty"`
+}
+
+// WTableHeader repeats a row at the top of each page the table is on.
+// Only the rows from the first one on may be header rows.
+type WTableHeader struct {
+	XMLName xml.Name `xml:"w:tblHeader,omitempty"`
+	Val     string   `xml:"w:val,attr,omitempty"`
+}
+
+// WTableCellSpacing is the spacing between the cells of a row
+type WTableCellSpacing struct {
+	XMLName xml.Name `xml:"w:tblCellSpacing,omitempty"`
+	W       int64    `xml:"w:w,attr"`
+	Type    string   `xml:"w:type,attr,omitempty"`
+}
+
 // WTableCell represents a cell within a table.
 type WTableCell struct {
 	XMLName             xml.Name `xml:"w:tc,omitempty"`
 	TableCellProperties *WTableCellProperties
-	Paragraphs          []*Paragraph `xml:"w:p,omitempty"`
+	// Items are the content of the cell like Body.Items:
+	// *Paragraph, *Table and *StructuredDocumentTag
+	Items []interface{}
 
 	file *Docx
 }
 
+// MarshalXML writes an empty paragraph after a trailing table,
+// as a cell must end with a paragraph
+func (c *WTableCell) MarshalXML(e *xml.Encoder, _ xml.StartElement) error {
+	type plain WTableCell
+	if len(c.Items) == 0 {
+		return e.Encode((*plain)(c))
+	}
+	if _, ok := c.Items[len(c.Items)-1].(*Table); !ok {
+		return e.Encode((*plain)(c))
+	}
+	cell := *c
+	cell.Items = append(c.Items[:len(c.Items):len(c.Items)], &Paragraph{file: c.file})
+	return e.Encode((*plain)(&cell))
+}
+
 // UnmarshalXML ...
 func (c *WTableCell) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) error {
 	for {
@@ -557,18 +784,47 @@ func (c *WTableCell) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) error {
 				var value Paragraph
 				value.file = c.file
 				err = d.DecodeElement(&value, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err != nil && !ignorable(d, err) {
+					return err
+				}
+				c.Items = append(c.Items, &value)
+			case "tbl":
+				var value Table
+				value.file = c.file
+				err = d.DecodeElement(&value, &tt)
+				if err != nil && !ignorable(d, err) {
+					return err
+				}
+				c.Items = append(c.Items, &value)
+			case "sdt":
+				var value StructuredDocumentTag
+				value.preserved, err = decodePreserved(d, &tt, &value)
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
-				c.Paragraphs = append(c.Paragraphs, &value)
+				c.Items = append(c.Items, &value)
 			case "tcPr":
 				var value WTableCellProperties
-				err = d.DecodeElement(&value, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				value.preserved, err = decodePreserved(d, &tt, &value)
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 				c.TableCellProperties = &value
+			case "commentRangeStart", "commentRangeEnd":
+				value, err := parseCommentRange(d, tt)
+				if err != nil {
+					return err
+				}
+				c.Items = append(c.Items, value)
 			default:
+				if contextOf(d).preserve() {
+					value, err := decodeRaw(d, tt)
+					if err != nil {
+						return err
+					}
+					c.Items = append(c.Items, value)
+					continue
+				}
 				err = d.Skip() // skip unsupported tags
 				if err != nil {
 					return err
@@ -584,11 +840,23 @@ func (c *WTableCell) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) error {
 type WTableCellProperties struct {
 	XMLName        xml.Name `xml:"w:tcPr,omitempty"`
 	TableCellWidth *WTableCellWidth
-	VMerge         *WvMerge
 	GridSpan       *WGridSpan
+	VMerge         *WvMerge
 	TableBorders   *WTableBorders `xml:"w:tcBorders"`
 	Shade          *Shade
+	NoWrap         *WNoWrap
+	Margins        *WTableCellMargins
+	TextDirection  *WTextDirection
+	FitText        *WFitText
 	VAlign         *WVerticalAlignment
+
+	preserved *preserved
+}
+
+// MarshalXML writes the source back in preserve mode if it is not modified
+func (r *WTableCellProperties) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type plain WTableCellProperties
+	return marshalPreserved(e, start, (*plain)(r), r.preserved)
 }
 
 // UnmarshalXML ...
@@ -633,16 +901,28 @@ func (r *WTableCellProperties) UnmarshalXML(d *xml.Decoder, _ xml.StartElement)
 			case "tcBorders":
 				r.TableBorders = new(WTableBorders)
 				err = d.DecodeElement(r.TableBorders, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 			case "shd":
 				var value Shade
 				err = d.DecodeElement(&value, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 				r.Shade = &value
+			case "noWrap":
+				r.NoWrap = &WNoWrap{Val: getAtt(tt.Attr, "val")}
+			case "tcMar":
+				r.Margins = new(WTableCellMargins)
+				err = d.DecodeElement(r.Margins, &tt)
+				if err != nil && !ignorable(d, err) {
+					return err
+				}
+			case "textDirection":
+				r.TextDirection = &WTextDirection{Val: getAtt(tt.Attr, "val")}
+			case "tcFitText":
+				r.FitText = &WFitText{Val: getAtt(tt.Attr, "val")}
 			default:
 				err = d.Skip() // skip unsupported tags
 				if err != nil {
@@ -719,42 +999,42 @@ func (w *WTableBorders) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) error {
 			case "top":
 				value := new(WTableBorder)
 				err = d.DecodeElement(value, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 				w.Top = value
 			case "left":
 				value := new(WTableBorder)
 				err = d.DecodeElement(value, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 				w.Left = value
 			case "bottom":
 				value := new(WTableBorder)
 				err = d.DecodeElement(value, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 				w.Bottom = value
 			case "right":
 				value := new(WTableBorder)
 				err = d.DecodeElement(value, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 				w.Right = value
 			case "insideH":
 				value := new(WTableBorder)
 				err = d.DecodeElement(value, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 				w.InsideH = value
 			case "insideV":
 				value := new(WTableBorder)
 				err = d.DecodeElement(value, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 				w.InsideV = value
@@ -823,3 +1103,87 @@ type WVerticalAlignment struct {
 	XMLName xml.Name `xml:"w:vAlign,omitempty"`
 	Val     string   `xml:"w:val,attr"`
 }
+
+//nolint:revive,stylecheck
+const (
+	// TEXTDIR_LR_TB is the horizontal text from left to right
+	TEXTDIR_LR_TB = "lrTb"
+	// TEXTDIR_TB_RL is the vertical text from top to bottom, lines from right
+	// to left, with East Asian characters upright and others rotated
+	TEXTDIR_TB_RL = "tbRl"
+	// TEXTDIR_BT_LR is the text rotated to run from bottom to top,
+	// lines from left to right
+	TEXTDIR_BT_LR = "btLr"
+)
+
+// WTextDirection is the direction of the text in a cell like TEXTDIR_TB_RL
+type WTextDirection struct {
+	XMLName xml.Name `xml:"w:textDirection,omitempty"`
+	Val     string   `xml:"w:val,attr"`
+}
+
+// WNoWrap keeps the text of a cell on one line when the table is laid out
+type WNoWrap struct {
+	XMLName xml.Name `xml:"w:noWrap,omitempty"`
+	Val     string   `xml:"w:val,attr,omitempty"`
+}
+
+// WFitText shrinks or stretches the text of a cell to fit its width
+type WFitText struct {
+	XMLName xml.Name `xml:"w:tcFitText,omitempty"`
+	Val     string   `xml:"w:val,attr,omitempty"`
+}
+
+// WTableCellMargins are the margins between the border and the content of a cell
+type WTableCellMargins struct {
+	XMLName xml.Name          `xml:"w:tcMar,omitempty"`
+	Top     *WTableCellMargin `xml:"w:top,omitempty"`
+	Left    *WTableCellMargin `xml:"w:left,omitempty"`
+	Bottom  *WTableCellMargin `xml:"w:bottom,omitempty"`
+	Right   *WTableCellMargin `xml:"w:right,omitempty"`
+}
+
+// UnmarshalXML ...
+func (m *WTableCellMargins) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) error {
+	for {
+		t, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		if tt, ok := t.(xml.StartElement); ok {
+			value := new(WTableCellMargin)
+			if v := getAtt(tt.Attr, "w"); v != "" {
+				value.W, err = GetInt64(v)
+				if err != nil {
+					return err
+				}
+			}
+			value.Type = getAtt(tt.Attr, "type")
+			switch tt.Name.Local {
+			case "top":
+				m.Top = value
+			case "left", "start":
+				m.Left = value
+			case "bottom":
+				m.Bottom = value
+			case "right", "end":
+				m.Right = value
+			}
+			err = d.Skip()
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// WTableCellMargin is one side of WTableCellMargins
+type WTableCellMargin struct {
+	W    int64  `xml:"w:w,attr"`
+	Type string `xml:"w:type,attr"`
+}
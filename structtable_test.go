@@ -21,6 +21,7 @@
 package docx
 
 import (
+	"bytes"
 	"encoding/xml"
 	"hash/crc64"
 	"io"
@@ -101,3 +102,40 @@ func TestTableStructure(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestNestedTable(t *testing.T) {
+	w := NewA4()
+	tbl := w.AddTable(1, 2)
+	cell := tbl.TableRows[0].TableCells[0]
+	cell.AddParagraph().AddText("outer {name}")
+	inner := cell.AddTable(2, 1)
+	inner.TableRows[1].TableCells[0].AddParagraph().AddText("inner {name}")
+	tbl.TableRows[0].TableCells[1].AddParagraph().AddText("right")
+	if w.ReplaceAll(map[string]string{"{name}": "cell"}) != 2 {
+		t.Fatal("expected replacing in the nested table")
+	}
+
+	for _, preserve := range []bool{false, true} {
+		var buf bytes.Buffer
+		_, err := w.WriteTo(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		doc, err := ParseWithOptions(bytes.NewReader(buf.Bytes()), int64(buf.Len()), ParseOptions{Preserve: preserve})
+		if err != nil {
+			t.Fatal(err)
+		}
+		c := doc.Document.Body.Items[0].(*Table).TableRows[0].TableCells[0]
+		// a paragraph is written after the trailing table
+		if len(c.Items) != 3 {
+			t.Fatal("unexpected items in cell", len(c.Items))
+		}
+		nested, ok := c.Items[1].(*Table)
+		if !ok || nested.file != doc || nested.TableRows[1].TableCells[0].file != doc {
+			t.Fatal("nested table not parsed")
+		}
+		if s := PlainText(c); s != "outer cell\ninner cell\n" {
+			t.Fatalf("unexpected text %q", s)
+		}
+	}
+}
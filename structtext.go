@@ -24,7 +24,6 @@ import (
 	"encoding/xml"
 	"io"
 	"reflect"
-	"strings"
 )
 
 // Tabs ...
@@ -48,7 +47,7 @@ func (tb *Tabs) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) error {
 			if tt.Name.Local == "tab" {
 				var value Tab
 				err := d.DecodeElement(&value, &tt)
-				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err != nil && !ignorable(d, err) {
 					return err
 				}
 				tb.Tabs = append(tb.Tabs, &value)
@@ -35,43 +35,110 @@ import (
 //  1. Document
 //  2. Relationships
 //  3. Media
+//  4. Headers, Footers, Footnotes, Endnotes and Comments
+//  5. Styles
 //
 // Then it stores all other files into tmpfslist for packing.
-func unpack(zipReader *zip.Reader) (docx *Docx, err error) {
+func unpack(zipReader *zip.Reader, opt *ParseOptions) (docx *Docx, err error) {
 	docx = new(Docx)
 	docx.mediaNameIdx = make(map[string]int, 64)
 	docx.slowIDs = make(map[string]uintptr, 64)
 	docx.tmplfs = zipReader
 	docx.tmpfslst = make([]string, 0, 64)
+	docx.logger = opt.Logger
+	byName := make(map[string]*zip.File, len(zipReader.File))
+	for _, f := range zipReader.File {
+		byName[f.Name] = f
+	}
+	// relations must be known before parsing headers and footers
+	if f, ok := byName["word/_rels/document.xml.rels"]; ok {
+		err = docx.parseDocRelation(f)
+		if err != nil {
+			return nil, newPartError(f.Name, err)
+		}
+	}
+	hdrftrs := make(map[string]Relationship, 8)
+	stylesName, extName := "", ""
+	for _, r := range docx.docRelation.Relationship {
+		switch r.Type {
+		case REL_HEADER, REL_FOOTER, REL_FOOTNOTES, REL_ENDNOTES, REL_COMMENTS, REL_STYLES, REL_COMMENTS_EXTENDED:
+		default:
+			continue
+		}
+		name := r.Target
+		if strings.HasPrefix(name, "/") {
+			name = name[1:]
+		} else {
+			name = "word/" + name
+		}
+		if _, ok := byName[name]; !ok {
+			continue
+		}
+		if r.Type == REL_STYLES {
+			stylesName = name
+			continue
+		}
+		if r.Type == REL_COMMENTS_EXTENDED {
+			extName = name
+			continue
+		}
+		hdrftrs[name] = r
+	}
 	for _, f := range zipReader.File {
 		if f.Name == "word/_rels/document.xml.rels" {
-			err = docx.parseDocRelation(f)
-			if err != nil {
-				return
-			}
 			continue
 		}
 		if f.Name == "word/document.xml" {
-			err = docx.parseDocument(f)
+			err = docx.parseDocument(f, opt)
 			if err != nil {
-				return
+				return nil, newPartError(f.Name, err)
 			}
 			continue
 		}
 		if f.Name == "word/numbering.xml" {
 			err = docx.parseNumbering(f)
 			if err != nil {
-				return
+				return nil, newPartError(f.Name, err)
+			}
+			continue
+		}
+		if f.Name == stylesName {
+			err = docx.parseStyles(f, opt)
+			if err != nil {
+				return nil, newPartError(f.Name, err)
 			}
 			continue
 		}
 		if strings.HasPrefix(f.Name, MEDIA_FOLDER) {
 			err = docx.parseMedia(f)
 			if err != nil {
-				return
+				return nil, newPartError(f.Name, err)
 			}
 			continue
 		}
+		if r, ok := hdrftrs[f.Name]; ok {
+			switch r.Type {
+			case REL_FOOTNOTES, REL_ENDNOTES:
+				err = docx.parseNotes(f, byName[relsNameOf(f.Name)], r, opt)
+			case REL_COMMENTS:
+				err = docx.parseComments(f, byName[relsNameOf(f.Name)], byName[extName], opt)
+			default:
+				err = docx.parseHeaderFooter(f, byName[relsNameOf(f.Name)], r, opt)
+			}
+			if err != nil {
+				return nil, newPartError(f.Name, err)
+			}
+			continue
+		}
+		if f.Name == extName && hasComments(hdrftrs) {
+			// rebuilt from Comment.Parent and Comment.Done
+			continue
+		}
+		if strings.HasSuffix(f.Name, ".xml.rels") {
+			if _, ok := hdrftrs[strings.Replace(f.Name[:len(f.Name)-5], "_rels/", "", 1)]; ok {
+				continue
+			}
+		}
 		// fill remaining files into tmpfslst
 		docx.tmpfslst = append(docx.tmpfslst, f.Name)
 	}
@@ -81,7 +148,7 @@ func unpack(zipReader *zip.Reader) (docx *Docx, err error) {
 }
 
 // parseDocument processes one of the relevant files, the one with the actual document
-func (f *Docx) parseDocument(file *zip.File) error {
+func (f *Docx) parseDocument(file *zip.File, opt *ParseOptions) error {
 	zf, err := file.Open()
 	if err != nil {
 		return err
@@ -100,11 +167,23 @@ func (f *Docx) parseDocument(file *zip.File) error {
 	// f.Document.XMLWP14 = XMLNS_WP14
 	f.Document.XMLName.Space = XMLNS_W
 	f.Document.XMLName.Local = "document"
+	if opt.Preserve {
+		// RawXML may refer to any of them
+		f.Document.XMLW10 = XMLNS_W10
+		f.Document.XMLW14 = XMLNS_W14
+		f.Document.XMLW15 = XMLNS_W15
+		f.Document.XMLMC = XMLNS_MC
+		f.Document.XMLWP14 = XMLNS_WP14
+		f.Document.XMLO = XMLNS_O
+		f.Document.XMLV = XMLNS_V
+	}
 
 	f.Document.Body.file = f
 	//TODO: find last docID
 	f.docID = 100000
-	err = xml.NewDecoder(zf).Decode(&f.Document)
+	d := xml.NewDecoder(zf)
+	defer bindContext(d, &decodeContext{opt: *opt})()
+	err = d.Decode(&f.Document)
 	return err
 }
 
@@ -165,3 +244,176 @@ func (f *Docx) parseNumbering(file *zip.File) error {
 	err = xml.NewDecoder(zf).Decode(&f.Numbering)
 	return err
 }
+
+// parseStyles processes word/styles.xml. Unknown elements are always
+// kept in it, for it used to be written back as is.
+func (f *Docx) parseStyles(file *zip.File, opt *ParseOptions) error {
+	zf, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer zf.Close()
+
+	s, err := decodeStyles(zf, opt)
+	if err != nil {
+		return err
+	}
+	s.file = f
+	s.name = file.Name
+	f.styles = s
+	return nil
+}
+
+// parseHeaderFooter processes word/headerN.xml or word/footerN.xml with its .rels
+func (f *Docx) parseHeaderFooter(file, relsfile *zip.File, r Relationship, opt *ParseOptions) error {
+	var ids map[string]string
+	var moved []Relationship
+	if relsfile != nil {
+		rels, err := parseRelationships(relsfile)
+		if err != nil {
+			return newPartError(relsfile.Name, err)
+		}
+		ids, moved = f.mergeRelations(rels)
+	}
+
+	zf, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer zf.Close()
+
+	d := xml.NewTokenDecoder(&relRemapper{d: xml.NewDecoder(zf), ids: ids})
+	defer bindContext(d, &decodeContext{opt: *opt})()
+	if r.Type == REL_HEADER {
+		h := &Header{HeaderFooter: HeaderFooter{file: f, name: file.Name, id: r.ID, rels: moved}}
+		h.setNamespaces()
+		err = d.Decode(h)
+		if err != nil {
+			return err
+		}
+		f.headers = append(f.headers, h)
+		return nil
+	}
+	ft := &Footer{HeaderFooter: HeaderFooter{file: f, name: file.Name, id: r.ID, rels: moved}}
+	ft.setNamespaces()
+	err = d.Decode(ft)
+	if err != nil {
+		return err
+	}
+	f.footers = append(f.footers, ft)
+	return nil
+}
+
+// parseNotes processes word/footnotes.xml or word/endnotes.xml with its .rels
+func (f *Docx) parseNotes(file, relsfile *zip.File, r Relationship, opt *ParseOptions) error {
+	var ids map[string]string
+	var moved []Relationship
+	if relsfile != nil {
+		rels, err := parseRelationships(relsfile)
+		if err != nil {
+			return newPartError(relsfile.Name, err)
+		}
+		ids, moved = f.mergeRelations(rels)
+	}
+
+	zf, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer zf.Close()
+
+	d := xml.NewTokenDecoder(&relRemapper{d: xml.NewDecoder(zf), ids: ids})
+	defer bindContext(d, &decodeContext{opt: *opt})()
+	local := "footnotes"
+	if r.Type == REL_ENDNOTES {
+		local = "endnotes"
+	}
+	n := newNotes(f, local)
+	n.name = file.Name
+	n.rels = moved
+	err = d.Decode(n)
+	if err != nil {
+		return err
+	}
+	if r.Type == REL_ENDNOTES {
+		f.endnotes = n
+	} else {
+		f.footnotes = n
+	}
+	return nil
+}
+
+// hasComments checks whether comments.xml is among the sub parts
+func hasComments(parts map[string]Relationship) bool {
+	for _, r := range parts {
+		if r.Type == REL_COMMENTS {
+			return true
+		}
+	}
+	return false
+}
+
+// parseComments processes word/comments.xml with its .rels
+// and word/commentsExtended.xml
+func (f *Docx) parseComments(file, relsfile, extfile *zip.File, opt *ParseOptions) error {
+	var ids map[string]string
+	var moved []Relationship
+	if relsfile != nil {
+		rels, err := parseRelationships(relsfile)
+		if err != nil {
+			return newPartError(relsfile.Name, err)
+		}
+		ids, moved = f.mergeRelations(rels)
+	}
+
+	zf, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer zf.Close()
+
+	d := xml.NewTokenDecoder(&relRemapper{d: xml.NewDecoder(zf), ids: ids})
+	defer bindContext(d, &decodeContext{opt: *opt})()
+	c := newComments(f)
+	c.name = file.Name
+	c.rels = moved
+	err = d.Decode(c)
+	if err != nil {
+		return err
+	}
+	f.comments = c
+	if extfile == nil {
+		return nil
+	}
+	c.extName = extfile.Name
+
+	ef, err := extfile.Open()
+	if err != nil {
+		return newPartError(extfile.Name, err)
+	}
+	defer ef.Close()
+
+	var ex commentsEx
+	err = xml.NewDecoder(ef).Decode(&ex)
+	if err != nil {
+		return newPartError(extfile.Name, err)
+	}
+	c.link(&ex)
+	return nil
+}
+
+// parseRelationships reads a .rels file of a sub part
+func parseRelationships(file *zip.File) (*Relationships, error) {
+	zf, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer zf.Close()
+
+	rels := &Relationships{Xmlns: XMLNS_REL}
+	err = xml.NewDecoder(zf).Decode(rels)
+	if err != nil {
+		return nil, err
+	}
+	return rels, nil
+}